@@ -0,0 +1,11 @@
+// Package api embeds the OpenAPI 3 description of the HTTP API. The spec is
+// maintained by hand; internal/server's contract test fails when it drifts
+// from the registered routes or DTOs.
+package api
+
+import _ "embed"
+
+// Spec is the OpenAPI document served at /openapi.json.
+//
+//go:embed openapi.json
+var Spec []byte
@@ -0,0 +1,147 @@
+// Command admin runs operational tasks against the application database, such
+// as bootstrapping the first admin or revoking a compromised user's sessions.
+//
+//	admin [-json] <command> [flags]
+//
+// Run `admin help` for the list of commands.
+package main
+
+import (
+	"context"
+	"english-learning/configs"
+	"english-learning/internal/database"
+	sessionDomain "english-learning/internal/modules/session/domain"
+	sessionPostgres "english-learning/internal/modules/session/repository/postgres"
+	userDomain "english-learning/internal/modules/user/domain"
+	userPostgres "english-learning/internal/modules/user/repository/postgres"
+	userService "english-learning/internal/modules/user/service"
+	"english-learning/internal/outbox"
+	"english-learning/pkg/encryption"
+	"english-learning/pkg/uow"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+
+	"github.com/joho/godotenv"
+	"gorm.io/gorm"
+)
+
+// env carries the dependencies shared by every command.
+type env struct {
+	db          *gorm.DB
+	userRepo    userDomain.UserRepository
+	userSvc     userDomain.UserService
+	userStore   userDomain.PersonalDataReencrypter
+	sessionRepo sessionDomain.SessionRepository
+	uow         uow.UnitOfWork
+	out         *output
+}
+
+type command struct {
+	summary string
+	run     func(ctx context.Context, e *env, args []string) error
+}
+
+var commands = map[string]command{
+	"create-user":            {"Create a user, optionally with the admin role", createUser},
+	"promote":                {"Change a user's role (admin by default)", promote},
+	"reset-password":         {"Set a new password and revoke the user's sessions", resetPassword},
+	"revoke-sessions":        {"Revoke every session of a user", revokeSessions},
+	"list-sessions":          {"List a user's sessions", listSessions},
+	"purge-expired-sessions": {"Delete sessions that have expired", purgeExpiredSessions},
+	"reencrypt-users":        {"Re-encrypt users' personal data with the primary key", reencryptUsers},
+	"seed-content":           {"Apply the SQL seed files in a directory", seedContent},
+}
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "admin:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("admin", flag.ContinueOnError)
+	jsonOutput := fs.Bool("json", false, "print results as JSON")
+	fs.Usage = func() { usage(fs) }
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 || fs.Arg(0) == "help" {
+		usage(fs)
+		return nil
+	}
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		usage(fs)
+		return fmt.Errorf("unknown command %q", fs.Arg(0))
+	}
+
+	// Load .env (ignore error if not present)
+	_ = godotenv.Load()
+
+	cfg, err := configs.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	enc, err := encryption.New(cfg.Encryption)
+	if err != nil {
+		return err
+	}
+
+	db, err := database.Open(cfg.Database, nil)
+	if err != nil {
+		return err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("getting sql.DB: %w", err)
+	}
+	defer sqlDB.Close()
+
+	userRepo := userPostgres.NewUserRepository(db, enc)
+	unitOfWork := database.NewUnitOfWork(db)
+	e := &env{
+		db:          db,
+		userRepo:    userRepo,
+		userSvc:     userService.NewService(userRepo, unitOfWork, outbox.NewPublisher(db)),
+		userStore:   userRepo,
+		sessionRepo: sessionPostgres.NewSessionRepository(db),
+		uow:         unitOfWork,
+		out:         &output{w: os.Stdout, json: *jsonOutput},
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err = cmd.run(ctx, e, fs.Args()[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	return err
+}
+
+func usage(fs *flag.FlagSet) {
+	w := fs.Output()
+	fmt.Fprintln(w, "Usage: admin [-json] <command> [flags]")
+	fmt.Fprintln(w, "\nCommands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-24s %s\n", name, commands[name].summary)
+	}
+
+	fmt.Fprintln(w, "\nGlobal flags:")
+	fs.PrintDefaults()
+	fmt.Fprintln(w, "\nRun `admin <command> -h` for command flags.")
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	sessionMemory "english-learning/internal/modules/session/repository/memory"
+	userMemory "english-learning/internal/modules/user/repository/memory"
+	userService "english-learning/internal/modules/user/service"
+	"english-learning/pkg/events"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// directUnitOfWork runs functions without a transaction, which is all the
+// memory repositories support.
+type directUnitOfWork struct{}
+
+func (directUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type nopPublisher struct{}
+
+func (nopPublisher) Publish(context.Context, ...events.Event) error { return nil }
+
+// testEnv is an env over the in-memory repositories, writing its output to
+// the returned buffer.
+func testEnv(t *testing.T, jsonOutput bool) (*env, *userMemory.UserRepository, *sessionMemory.SessionRepository, *bytes.Buffer) {
+	t.Helper()
+	users := userMemory.NewUserRepository()
+	sessions := sessionMemory.NewSessionRepository()
+	out := &bytes.Buffer{}
+	return &env{
+		userRepo:    users,
+		userSvc:     userService.NewService(users, directUnitOfWork{}, nopPublisher{}),
+		sessionRepo: sessions,
+		uow:         directUnitOfWork{},
+		out:         &output{w: out, json: jsonOutput},
+	}, users, sessions, out
+}
+
+func TestRun_Dispatch(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, run(nil), "no command prints the usage")
+	require.NoError(t, run([]string{"help"}))
+
+	err := run([]string{"no-such-command"})
+	assert.EqualError(t, err, `unknown command "no-such-command"`)
+
+	err = run([]string{"-no-such-flag", "help"})
+	assert.Error(t, err)
+}
+
+func TestCommands_HaveSummaries(t *testing.T) {
+	t.Parallel()
+	for name, cmd := range commands {
+		assert.NotEmptyf(t, cmd.summary, "command %s", name)
+		assert.NotNilf(t, cmd.run, "command %s", name)
+	}
+}
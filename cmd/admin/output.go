@@ -0,0 +1,36 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// output writes command results either as JSON, for scripting, or as an
+// aligned table for humans.
+type output struct {
+	w    io.Writer
+	json bool
+}
+
+// print writes v as indented JSON, or calls table with a tabwriter otherwise.
+func (o *output) print(v interface{}, table func(w io.Writer)) error {
+	if o.json {
+		enc := json.NewEncoder(o.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+
+	tw := tabwriter.NewWriter(o.w, 0, 0, 2, ' ', 0)
+	table(tw)
+	return tw.Flush()
+}
+
+// message prints a one-line confirmation, wrapped in {"message": ...} for JSON.
+func (o *output) message(format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	return o.print(map[string]string{"message": msg}, func(w io.Writer) {
+		fmt.Fprintln(w, msg)
+	})
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+// seedContent applies every *.sql file in a directory in lexical order, each
+// in its own transaction. Seed files should be idempotent (e.g. use
+// ON CONFLICT DO NOTHING) since nothing records which ones have run.
+func seedContent(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("seed-content", flag.ContinueOnError)
+	dir := fs.String("dir", "seeds", "directory containing *.sql seed files")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	files, err := filepath.Glob(filepath.Join(*dir, "*.sql"))
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no *.sql files in %s", *dir)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		sql, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		err = e.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return tx.Exec(string(sql)).Error
+		})
+		if err != nil {
+			return fmt.Errorf("applying %s: %w", file, err)
+		}
+	}
+
+	return e.out.print(map[string][]string{"applied": files}, func(w io.Writer) {
+		for _, file := range files {
+			fmt.Fprintf(w, "OK   %s\n", file)
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	sessionDomain "english-learning/internal/modules/session/domain"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+)
+
+// sessionView omits the refresh token: it is a bearer credential.
+type sessionView struct {
+	ID        uint      `json:"id"`
+	UserID    uint      `json:"userId"`
+	ClientIP  string    `json:"clientIp"`
+	UserAgent string    `json:"userAgent"`
+	IsRevoked bool      `json:"isRevoked"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func revokeSessions(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("revoke-sessions", flag.ContinueOnError)
+	ref := addUserRefFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	user, err := ref.resolve(ctx, e.userRepo)
+	if err != nil {
+		return err
+	}
+	if err := e.sessionRepo.RevokeAllForUser(ctx, user.ID); err != nil {
+		return fmt.Errorf("revoking sessions: %w", err)
+	}
+
+	return e.out.message("Revoked all sessions of user %d (%s)", user.ID, user.Email)
+}
+
+func listSessions(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("list-sessions", flag.ContinueOnError)
+	ref := addUserRefFlags(fs)
+	activeOnly := fs.Bool("active", false, "only show sessions that are neither revoked nor expired")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	user, err := ref.resolve(ctx, e.userRepo)
+	if err != nil {
+		return err
+	}
+	sessions, err := e.sessionRepo.ListByUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("listing sessions: %w", err)
+	}
+
+	now := time.Now()
+	views := make([]sessionView, 0, len(sessions))
+	for _, s := range sessions {
+		if *activeOnly && !isActive(s, now) {
+			continue
+		}
+		views = append(views, sessionView{
+			ID:        s.ID,
+			UserID:    s.UserID,
+			ClientIP:  s.ClientIP,
+			UserAgent: s.UserAgent,
+			IsRevoked: s.IsRevoked,
+			ExpiresAt: s.ExpiresAt,
+			CreatedAt: s.CreatedAt,
+		})
+	}
+
+	return e.out.print(views, func(w io.Writer) {
+		fmt.Fprintln(w, "ID\tCREATED AT\tEXPIRES AT\tREVOKED\tCLIENT IP\tUSER AGENT")
+		for _, v := range views {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%t\t%s\t%s\n", v.ID,
+				v.CreatedAt.Format(time.RFC3339), v.ExpiresAt.Format(time.RFC3339),
+				v.IsRevoked, v.ClientIP, v.UserAgent)
+		}
+	})
+}
+
+func isActive(s sessionDomain.Session, now time.Time) bool {
+	return !s.IsRevoked && s.ExpiresAt.After(now)
+}
+
+func purgeExpiredSessions(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("purge-expired-sessions", flag.ContinueOnError)
+	olderThan := fs.Duration("older-than", 0, "keep sessions that expired less than this long ago")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	deleted, err := e.sessionRepo.DeleteExpired(ctx, time.Now().Add(-*olderThan))
+	if err != nil {
+		return fmt.Errorf("purging sessions: %w", err)
+	}
+
+	return e.out.print(map[string]int64{"deleted": deleted}, func(w io.Writer) {
+		fmt.Fprintf(w, "Deleted %d expired session(s)\n", deleted)
+	})
+}
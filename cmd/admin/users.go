@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	userDomain "english-learning/internal/modules/user/domain"
+	userService "english-learning/internal/modules/user/service"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+)
+
+// minPasswordLength matches the API's registration rule.
+const minPasswordLength = 6
+
+type userView struct {
+	ID        uint      `json:"id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	FirstName string    `json:"firstName"`
+	LastName  string    `json:"lastName"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func toUserView(u *userDomain.User) userView {
+	return userView{
+		ID:        u.ID,
+		Email:     u.Email,
+		Role:      u.Role,
+		FirstName: u.FirstName,
+		LastName:  u.LastName,
+		CreatedAt: u.CreatedAt,
+	}
+}
+
+func (o *output) user(u *userDomain.User) error {
+	return o.print(toUserView(u), func(w io.Writer) {
+		fmt.Fprintln(w, "ID\tEMAIL\tROLE\tNAME\tCREATED AT")
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s %s\t%s\n", u.ID, u.Email, u.Role, u.FirstName, u.LastName, u.CreatedAt.Format(time.RFC3339))
+	})
+}
+
+// userRef identifies a user on the command line by -id or -email.
+type userRef struct {
+	id    uint
+	email string
+}
+
+func addUserRefFlags(fs *flag.FlagSet) *userRef {
+	ref := &userRef{}
+	fs.UintVar(&ref.id, "id", 0, "user ID")
+	fs.StringVar(&ref.email, "email", "", "user email")
+	return ref
+}
+
+func (r *userRef) resolve(ctx context.Context, repo userDomain.UserRepository) (*userDomain.User, error) {
+	switch {
+	case r.id != 0 && r.email != "":
+		return nil, errors.New("use either -id or -email, not both")
+	case r.id != 0:
+		return repo.FindByID(ctx, r.id)
+	case r.email != "":
+		return repo.FindByEmail(ctx, r.email)
+	default:
+		return nil, errors.New("-id or -email is required")
+	}
+}
+
+func validateRole(role string) error {
+	switch role {
+	case userDomain.RoleStudent, userDomain.RoleAdmin:
+		return nil
+	default:
+		return fmt.Errorf("unknown role %q (want %s or %s)", role, userDomain.RoleStudent, userDomain.RoleAdmin)
+	}
+}
+
+func createUser(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("create-user", flag.ContinueOnError)
+	email := fs.String("email", "", "email address (required)")
+	password := fs.String("password", "", "password; a random one is generated and printed if empty")
+	firstName := fs.String("first-name", "", "first name")
+	lastName := fs.String("last-name", "", "last name")
+	role := fs.String("role", userDomain.RoleStudent, "role: student or admin")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *email == "" {
+		return errors.New("-email is required")
+	}
+	if err := validateRole(*role); err != nil {
+		return err
+	}
+	plain, generated, err := passwordOrRandom(*password)
+	if err != nil {
+		return err
+	}
+
+	user := &userDomain.User{
+		Email:     *email,
+		Password:  plain,
+		FirstName: *firstName,
+		LastName:  *lastName,
+		Role:      *role,
+	}
+	if err := e.userSvc.Create(ctx, user); err != nil {
+		return err
+	}
+
+	if generated {
+		return e.out.print(struct {
+			userView
+			Password string `json:"password"`
+		}{toUserView(user), plain}, func(w io.Writer) {
+			fmt.Fprintf(w, "Created user %d (%s) with role %s\n", user.ID, user.Email, user.Role)
+			fmt.Fprintf(w, "Generated password: %s\n", plain)
+		})
+	}
+	return e.out.user(user)
+}
+
+func promote(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("promote", flag.ContinueOnError)
+	ref := addUserRefFlags(fs)
+	role := fs.String("role", userDomain.RoleAdmin, "new role: student or admin")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := validateRole(*role); err != nil {
+		return err
+	}
+
+	user, err := ref.resolve(ctx, e.userRepo)
+	if err != nil {
+		return err
+	}
+
+	// Existing access tokens keep the old role until they expire; revoking
+	// sessions stops them from being refreshed with it.
+	user.Role = *role
+	err = e.uow.Do(ctx, func(ctx context.Context) error {
+		if err := e.userSvc.Update(ctx, user, ""); err != nil {
+			return fmt.Errorf("updating role: %w", err)
+		}
+		if err := e.sessionRepo.RevokeAllForUser(ctx, user.ID); err != nil {
+			return fmt.Errorf("revoking sessions: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return e.out.user(user)
+}
+
+func resetPassword(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("reset-password", flag.ContinueOnError)
+	ref := addUserRefFlags(fs)
+	password := fs.String("password", "", "new password; a random one is generated and printed if empty")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	user, err := ref.resolve(ctx, e.userRepo)
+	if err != nil {
+		return err
+	}
+	plain, generated, err := passwordOrRandom(*password)
+	if err != nil {
+		return err
+	}
+
+	err = e.uow.Do(ctx, func(ctx context.Context) error {
+		if err := e.userSvc.Update(ctx, user, plain); err != nil {
+			return err
+		}
+		if err := e.sessionRepo.RevokeAllForUser(ctx, user.ID); err != nil {
+			return fmt.Errorf("revoking sessions: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	result := struct {
+		ID       uint   `json:"id"`
+		Email    string `json:"email"`
+		Password string `json:"password,omitempty"`
+	}{ID: user.ID, Email: user.Email}
+	if generated {
+		result.Password = plain
+	}
+	return e.out.print(result, func(w io.Writer) {
+		fmt.Fprintf(w, "Password reset for user %d (%s); all sessions revoked\n", user.ID, user.Email)
+		if generated {
+			fmt.Fprintf(w, "Generated password: %s\n", plain)
+		}
+	})
+}
+
+// passwordOrRandom returns password, or a random one when it is empty. The
+// boolean reports whether the password was generated.
+func passwordOrRandom(password string) (string, bool, error) {
+	if password != "" {
+		if len(password) < minPasswordLength {
+			return "", false, fmt.Errorf("password must be at least %d characters", minPasswordLength)
+		}
+		return password, false, nil
+	}
+
+	b := make([]byte, 18)
+	if _, err := rand.Read(b); err != nil {
+		return "", false, fmt.Errorf("generating password: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), true, nil
+}
+
+// reencryptUsers does what the scheduled user.reencrypt_personal_data job
+// does, e.g. to retire an old key right after a rotation.
+func reencryptUsers(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("reencrypt-users", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	rewritten, err := userService.ReencryptPersonalData(ctx, e.userStore)
+	if err != nil {
+		return err
+	}
+
+	return e.out.print(map[string]int{"reencrypted": rewritten}, func(w io.Writer) {
+		fmt.Fprintf(w, "Re-encrypted the personal data of %d user(s)\n", rewritten)
+	})
+}
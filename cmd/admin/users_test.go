@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	sessionDomain "english-learning/internal/modules/session/domain"
+	userDomain "english-learning/internal/modules/user/domain"
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestCreateUser_GeneratesPassword(t *testing.T) {
+	t.Parallel()
+	e, users, _, out := testEnv(t, true)
+	ctx := context.Background()
+
+	require.NoError(t, createUser(ctx, e, []string{"-email", "admin@example.com", "-role", "admin"}))
+
+	var printed struct {
+		ID       uint   `json:"id"`
+		Email    string `json:"email"`
+		Role     string `json:"role"`
+		Password string `json:"password"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &printed))
+	assert.Equal(t, "admin@example.com", printed.Email)
+	assert.Equal(t, userDomain.RoleAdmin, printed.Role)
+	require.NotEmpty(t, printed.Password)
+
+	stored, err := users.FindByID(ctx, printed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, userDomain.RoleAdmin, stored.Role)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(stored.Password), []byte(printed.Password)), "the printed password is the one stored")
+}
+
+func TestCreateUser_RejectsBadFlags(t *testing.T) {
+	t.Parallel()
+	e, _, _, _ := testEnv(t, false)
+	ctx := context.Background()
+
+	assert.EqualError(t, createUser(ctx, e, nil), "-email is required")
+	assert.ErrorContains(t, createUser(ctx, e, []string{"-email", "a@example.com", "-role", "teacher"}), `unknown role "teacher"`)
+	assert.ErrorContains(t, createUser(ctx, e, []string{"-email", "a@example.com", "-password", "short"}), "at least 6 characters")
+	assert.ErrorIs(t, createUser(ctx, e, []string{"-h"}), flag.ErrHelp)
+}
+
+func TestPromote_RevokesSessions(t *testing.T) {
+	t.Parallel()
+	e, users, sessions, out := testEnv(t, false)
+	ctx := context.Background()
+
+	user := &userDomain.User{Email: "teacher@example.com", Password: "hashed", Role: userDomain.RoleStudent}
+	require.NoError(t, users.Create(ctx, user))
+	session := &sessionDomain.Session{UserID: user.ID, RefreshToken: "token", ExpiresAt: time.Now().Add(time.Hour)}
+	require.NoError(t, sessions.Create(ctx, session))
+
+	require.NoError(t, promote(ctx, e, []string{"-email", "teacher@example.com"}))
+
+	stored, err := users.FindByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, userDomain.RoleAdmin, stored.Role)
+	assert.Equal(t, "hashed", stored.Password, "the password hash is not re-hashed")
+	revoked, err := sessions.FindByID(ctx, session.ID)
+	require.NoError(t, err)
+	assert.True(t, revoked.IsRevoked)
+	assert.Contains(t, out.String(), "teacher@example.com")
+}
+
+func TestResetPassword_HashesNewPassword(t *testing.T) {
+	t.Parallel()
+	e, users, sessions, _ := testEnv(t, false)
+	ctx := context.Background()
+
+	user := &userDomain.User{Email: "student@example.com", Password: "hashed", Role: userDomain.RoleStudent}
+	require.NoError(t, users.Create(ctx, user))
+	session := &sessionDomain.Session{UserID: user.ID, RefreshToken: "token", ExpiresAt: time.Now().Add(time.Hour)}
+	require.NoError(t, sessions.Create(ctx, session))
+
+	require.NoError(t, resetPassword(ctx, e, []string{"-email", "student@example.com", "-password", "new-secret"}))
+
+	stored, err := users.FindByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(stored.Password), []byte("new-secret")), "the new password is hashed once")
+	revoked, err := sessions.FindByID(ctx, session.ID)
+	require.NoError(t, err)
+	assert.True(t, revoked.IsRevoked)
+}
+
+func TestUserRef_Resolve(t *testing.T) {
+	t.Parallel()
+	e, _, _, _ := testEnv(t, false)
+	ctx := context.Background()
+
+	_, err := (&userRef{}).resolve(ctx, e.userRepo)
+	assert.EqualError(t, err, "-id or -email is required")
+	_, err = (&userRef{id: 1, email: "a@example.com"}).resolve(ctx, e.userRepo)
+	assert.EqualError(t, err, "use either -id or -email, not both")
+	_, err = (&userRef{id: 42}).resolve(ctx, e.userRepo)
+	assert.ErrorIs(t, err, userDomain.ErrUserNotFound)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"english-learning/configs"
+	"errors"
+	"flag"
+	"os"
+)
+
+const configUsage = "usage: server config print [--redacted=false]"
+
+// runConfig implements `server config print`, which writes the effective
+// configuration as YAML and then reports any validation problems. Secrets are
+// redacted unless --redacted=false is given.
+func runConfig(args []string) error {
+	if len(args) == 0 || args[0] != "print" {
+		return errors.New(configUsage)
+	}
+
+	fs := flag.NewFlagSet("config print", flag.ContinueOnError)
+	redacted := fs.Bool("redacted", true, "replace secrets with a placeholder")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	cfg, err := configs.Load("./configs")
+	if err != nil {
+		return err
+	}
+
+	out := *cfg
+	if *redacted {
+		out = cfg.Redacted()
+	}
+	if err := out.WriteYAML(os.Stdout); err != nil {
+		return err
+	}
+
+	return cfg.Validate()
+}
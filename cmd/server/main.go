@@ -4,6 +4,7 @@ import (
 	"english-learning/configs"
 	"english-learning/internal/app"
 	"log"
+	"os"
 
 	"github.com/joho/godotenv"
 )
@@ -12,21 +13,37 @@ func main() {
 	// Load .env (ignore error if not present)
 	_ = godotenv.Load()
 
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		if err := runConfig(os.Args[2:]); err != nil {
+			log.Fatalf("Config: %v", err)
+		}
+		return
+	}
+
 	// Load Config
 	cfg, err := configs.LoadConfig()
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(cfg, os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
 	// Init Application
 	application, err := app.New(cfg)
 	if err != nil {
 		log.Fatalf("Failed to initialize application: %v", err)
 	}
-	defer application.Close()
 
-	// Run Server
-	if err := application.Run(); err != nil {
+	// Run Server. Close is called explicitly rather than deferred because
+	// log.Fatalf exits without running deferred functions.
+	err = application.Run()
+	application.Close()
+	if err != nil {
 		log.Fatalf("Server error: %v", err)
 	}
 }
@@ -1,56 +1,443 @@
 package configs
 
 import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
+	Server      ServerConfig
+	API         APIConfig
+	Database    DatabaseConfig
+	JWT         JWTConfig
+	Health      HealthConfig
+	Metrics     MetricsConfig
+	Tracing     TracingConfig
+	Outbox      OutboxConfig
+	Jobs        JobsConfig
+	Cache       CacheConfig
+	Idempotency IdempotencyConfig
+	CORS        CORSConfig
+	Security    SecurityConfig
+	Encryption  EncryptionConfig
 }
 
 type ServerConfig struct {
 	Port string
-	Env  string
+	// Env selects the profile: "dev", "test" or "prod". configs/config.<env>.yaml
+	// is merged over config.yaml when it exists.
+	Env string
+	// RequestTimeout bounds how long a single request (and the DB queries it
+	// issues) may run. Zero disables the limit.
+	RequestTimeout time.Duration `mapstructure:"request_timeout"`
+
+	// http.Server limits. WriteTimeout should be longer than RequestTimeout so
+	// that timed-out requests can still send their 504.
+	ReadTimeout       time.Duration `mapstructure:"read_timeout"`
+	ReadHeaderTimeout time.Duration `mapstructure:"read_header_timeout"`
+	WriteTimeout      time.Duration `mapstructure:"write_timeout"`
+	IdleTimeout       time.Duration `mapstructure:"idle_timeout"`
+	MaxHeaderBytes    int           `mapstructure:"max_header_bytes"`
+	// DrainDelay is how long /readyz fails after SIGINT/SIGTERM before the
+	// server stops accepting connections, so load balancers notice first.
+	DrainDelay time.Duration `mapstructure:"drain_delay"`
+	// ShutdownTimeout is how long in-flight requests and background workers
+	// get to finish after DrainDelay.
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+	// TrustedProxies are the IPs or CIDRs of the load balancers in front of
+	// the server. Only requests from them may set the client IP with
+	// X-Forwarded-For or X-Real-IP; empty trusts no proxy.
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+}
+
+// APIConfig configures the deprecated unversioned alias of /v1. Both dates
+// are YYYY-MM-DD.
+type APIConfig struct {
+	// LegacySince is announced in the Deprecation header of the alias: the
+	// release that added /v1.
+	LegacySince string `mapstructure:"legacy_since"`
+	// LegacySunset, when set, is announced in its Sunset header: the day it
+	// is removed.
+	LegacySunset string `mapstructure:"legacy_sunset"`
+}
+
+// LegacyDates returns LegacySince and LegacySunset parsed; a date that is
+// empty or invalid, which Validate rejects, is zero.
+func (c APIConfig) LegacyDates() (since, sunset time.Time) {
+	since, _ = time.Parse(time.DateOnly, c.LegacySince)
+	sunset, _ = time.Parse(time.DateOnly, c.LegacySunset)
+	return since, sunset
 }
 
 type DatabaseConfig struct {
 	DSN string
+	// AutoMigrate applies pending migrations on start-up. Concurrent
+	// instances serialise on a Postgres advisory lock.
+	AutoMigrate bool `mapstructure:"auto_migrate"`
 }
 
 type JWTConfig struct {
-	Secret            string
-	AccessExpiryHour  int `mapstructure:"access_expiry_hour"`
-	RefreshExpiryHour int `mapstructure:"refresh_expiry_hour"`
+	Secret     string
+	AccessTTL  time.Duration `mapstructure:"access_ttl"`
+	RefreshTTL time.Duration `mapstructure:"refresh_ttl"`
 }
 
-func LoadConfig() (*Config, error) {
-	viper.AddConfigPath("./configs")
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
+type HealthConfig struct {
+	// CheckTimeout bounds each readiness check run by /readyz.
+	CheckTimeout time.Duration `mapstructure:"check_timeout"`
+}
+
+type MetricsConfig struct {
+	Enabled bool
+	Path    string
+	// Token, when set, must be sent by scrapers as a Bearer token. It is
+	// required in prod.
+	Token string
+}
+
+type TracingConfig struct {
+	// Exporter is one of "none", "stdout" or "otlp".
+	Exporter    string
+	ServiceName string `mapstructure:"service_name"`
+	// Endpoint is the OTLP/HTTP collector host:port. Empty uses the
+	// OTEL_EXPORTER_OTLP_ENDPOINT environment variable or localhost:4318.
+	Endpoint    string
+	Insecure    bool
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+}
+
+type OutboxConfig struct {
+	// PollInterval is how often the dispatcher looks for new events when the
+	// previous batch was not full.
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	BatchSize    int           `mapstructure:"batch_size"`
+	// MaxAttempts is how many failed deliveries mark an event as failed.
+	MaxAttempts int `mapstructure:"max_attempts"`
+	// RetryBase is the delay after the first failure; it doubles with every
+	// further failure up to RetryMax.
+	RetryBase time.Duration `mapstructure:"retry_base"`
+	RetryMax  time.Duration `mapstructure:"retry_max"`
+	// LockTimeout is how long a claimed batch may take. Its context expires
+	// then, and events left undelivered (e.g. their instance crashed) are
+	// claimed again.
+	LockTimeout time.Duration `mapstructure:"lock_timeout"`
+	// Retention is how long processed and failed events are kept. Their
+	// payloads hold personal data such as client IPs.
+	Retention time.Duration
+}
+
+type JobsConfig struct {
+	// Workers is how many jobs one instance runs concurrently.
+	Workers      int
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	// LockTimeout is how long a claimed job may run. Its context expires then,
+	// and a job still marked running afterwards (e.g. its worker crashed) is
+	// claimed again.
+	LockTimeout time.Duration `mapstructure:"lock_timeout"`
+	// MaxAttempts is the default number of failed runs after which a job is
+	// dead-lettered.
+	MaxAttempts int `mapstructure:"max_attempts"`
+	// RetryBase is the delay after the first failure; it doubles with every
+	// further failure up to RetryMax.
+	RetryBase time.Duration `mapstructure:"retry_base"`
+	RetryMax  time.Duration `mapstructure:"retry_max"`
+	// SchedulerInterval is how often recurring schedules are checked. Cron
+	// schedules have minute resolution, so it should stay under a minute.
+	SchedulerInterval time.Duration `mapstructure:"scheduler_interval"`
+	// Retention is how long succeeded jobs are kept. Dead jobs are kept until
+	// retried or deleted by hand.
+	Retention time.Duration
+}
+
+type CacheConfig struct {
+	// Users caches users by ID, the lookup behind every token refresh.
+	Users CacheSettings
+	// Features caches all feature flags, evaluated on every GET /features.
+	Features FeatureCacheSettings
+}
+
+type CacheSettings struct {
+	Enabled bool
+	// Size is the maximum number of entries held by each instance.
+	Size int
+	// TTL bounds how long an entry is served, and so how stale it can get if
+	// an invalidation is missed.
+	TTL time.Duration
+}
+
+// FeatureCacheSettings configures the feature flag cache, which always holds
+// every flag.
+type FeatureCacheSettings struct {
+	Enabled bool
+	// TTL bounds how stale the flags can get if an invalidation is missed.
+	TTL time.Duration
+}
+
+type IdempotencyConfig struct {
+	// TTL is how long a stored response is replayed; afterwards the key may
+	// be reused and the record is purged.
+	TTL time.Duration
+	// LockTimeout is how long a request holds its key while running. A retry
+	// after it takes over a key whose request never finished (e.g. its
+	// instance crashed), so it must exceed server.request_timeout.
+	LockTimeout time.Duration `mapstructure:"lock_timeout"`
+}
+
+type CORSConfig struct {
+	// AllowedOrigins are the browser origins allowed to call the API, e.g.
+	// "https://app.example.com". "https://*.example.com" allows any subdomain
+	// and "*" any origin. Empty disables CORS.
+	AllowedOrigins []string `mapstructure:"allowed_origins"`
+	// AllowCredentials lets browsers send cookies and HTTP auth. Not needed
+	// for Bearer tokens, and not allowed with "*".
+	AllowCredentials bool `mapstructure:"allow_credentials"`
+	// MaxAge is how long browsers may cache a preflight response.
+	MaxAge time.Duration `mapstructure:"max_age"`
+}
+
+type SecurityConfig struct {
+	// HSTSMaxAge is the max-age of Strict-Transport-Security; zero omits the
+	// header. Set it only where the API is reachable over HTTPS alone.
+	HSTSMaxAge time.Duration `mapstructure:"hsts_max_age"`
+}
+
+type EncryptionConfig struct {
+	// PrimaryKeyID names the master key new values are encrypted with.
+	PrimaryKeyID string `mapstructure:"primary_key_id"`
+	// Keys are master keys as "<id>:<base64 of 32 bytes>". Keep a retired
+	// key until re-encryption has moved every value off it.
+	Keys []string
+	// KeyDir, when set, holds more master keys, one file per key named
+	// <id>.key with the base64 key, e.g. a mounted Kubernetes secret.
+	KeyDir string `mapstructure:"key_dir"`
+	// BlindIndexKey is the base64 HMAC key, at least 32 bytes, of the hashes
+	// that encrypted fields are looked up by. Changing it orphans every
+	// existing hash.
+	BlindIndexKey string `mapstructure:"blind_index_key"`
+}
+
+// Profiles accepted in server.env.
+const (
+	EnvDev  = "dev"
+	EnvTest = "test"
+	EnvProd = "prod"
+)
+
+// setDefaults registers a typed default for every key, so that a key missing
+// from the yaml files still unmarshals correctly and can be set from the
+// environment.
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("server.port", "8080")
+	v.SetDefault("server.env", EnvDev)
+	v.SetDefault("server.request_timeout", 15*time.Second)
+	v.SetDefault("server.read_timeout", 15*time.Second)
+	v.SetDefault("server.read_header_timeout", 5*time.Second)
+	v.SetDefault("server.write_timeout", 20*time.Second)
+	v.SetDefault("server.idle_timeout", 60*time.Second)
+	v.SetDefault("server.max_header_bytes", 1<<20)
+	v.SetDefault("server.drain_delay", 5*time.Second)
+	v.SetDefault("server.shutdown_timeout", 20*time.Second)
+	v.SetDefault("server.trusted_proxies", []string{})
+
+	v.SetDefault("api.legacy_since", "2026-10-19")
+	v.SetDefault("api.legacy_sunset", "2027-04-19")
+
+	v.SetDefault("database.dsn", "")
+	v.SetDefault("database.auto_migrate", false)
+
+	v.SetDefault("jwt.secret", "")
+	v.SetDefault("jwt.access_ttl", 15*time.Minute)
+	v.SetDefault("jwt.refresh_ttl", 7*24*time.Hour)
+
+	v.SetDefault("health.check_timeout", 2*time.Second)
+
+	v.SetDefault("metrics.enabled", true)
+	v.SetDefault("metrics.path", "/metrics")
+	v.SetDefault("metrics.token", "")
 
-	// 1. Read config.yaml first (for defaults)
-	if err := viper.ReadInConfig(); err != nil {
+	v.SetDefault("tracing.exporter", "none")
+	v.SetDefault("tracing.service_name", "english-learning")
+	v.SetDefault("tracing.endpoint", "")
+	v.SetDefault("tracing.insecure", true)
+	v.SetDefault("tracing.sample_ratio", 1.0)
+
+	v.SetDefault("outbox.poll_interval", time.Second)
+	v.SetDefault("outbox.batch_size", 100)
+	v.SetDefault("outbox.max_attempts", 10)
+	v.SetDefault("outbox.retry_base", 5*time.Second)
+	v.SetDefault("outbox.retry_max", 30*time.Minute)
+	v.SetDefault("outbox.lock_timeout", 5*time.Minute)
+	v.SetDefault("outbox.retention", 7*24*time.Hour)
+
+	v.SetDefault("jobs.workers", 4)
+	v.SetDefault("jobs.poll_interval", time.Second)
+	v.SetDefault("jobs.lock_timeout", 5*time.Minute)
+	v.SetDefault("jobs.max_attempts", 5)
+	v.SetDefault("jobs.retry_base", 10*time.Second)
+	v.SetDefault("jobs.retry_max", time.Hour)
+	v.SetDefault("jobs.scheduler_interval", 15*time.Second)
+	v.SetDefault("jobs.retention", 7*24*time.Hour)
+
+	v.SetDefault("cache.users.enabled", true)
+	v.SetDefault("cache.users.size", 10000)
+	v.SetDefault("cache.users.ttl", 5*time.Minute)
+	v.SetDefault("cache.features.enabled", true)
+	v.SetDefault("cache.features.ttl", time.Minute)
+
+	v.SetDefault("idempotency.ttl", 24*time.Hour)
+	v.SetDefault("idempotency.lock_timeout", time.Minute)
+
+	v.SetDefault("cors.allowed_origins", []string{})
+	v.SetDefault("cors.allow_credentials", false)
+	v.SetDefault("cors.max_age", 10*time.Minute)
+
+	v.SetDefault("security.hsts_max_age", time.Duration(0))
+
+	v.SetDefault("encryption.primary_key_id", "")
+	v.SetDefault("encryption.keys", []string{})
+	v.SetDefault("encryption.key_dir", "")
+	v.SetDefault("encryption.blind_index_key", "")
+}
+
+// LoadConfig loads and validates the configuration from ./configs. Callers
+// load .env into the process environment beforehand.
+func LoadConfig() (*Config, error) {
+	cfg, err := Load("./configs")
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
 		return nil, err
 	}
+	return cfg, nil
+}
 
-	// 2. Read .env file (if it exists) to override defaults locally
-	viper.SetConfigName(".env")
-	viper.SetConfigType("env")
-	viper.AddConfigPath(".")
-	viper.MergeInConfig() // Ignore error if .env doesn't exist
+// Load reads the configuration from dir without validating it. Sources, from
+// lowest to highest priority:
+//
+//  1. typed defaults (setDefaults)
+//  2. dir/config.yaml
+//  3. dir/config.<server.env>.yaml, if present
+//  4. environment variables, e.g. DATABASE_DSN for database.dsn
+//  5. files named by <KEY>_FILE variables, e.g. JWT_SECRET_FILE, for
+//     Docker/Kubernetes secrets
+//
+// Keys that were renamed are still read; see renamedKeys.
+func Load(dir string) (*Config, error) {
+	v := viper.New()
+	setDefaults(v)
 
-	// 3. Environment Variables (Highest Priority)
-	viper.AutomaticEnv()
-	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AddConfigPath(dir)
+	v.SetConfigName("config")
+	v.SetConfigType("yaml")
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("reading config.yaml: %w", err)
+	}
 
-	var config Config
-	if err := viper.Unmarshal(&config); err != nil {
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	if env := v.GetString("server.env"); env != "" {
+		v.SetConfigName("config." + env)
+		if err := v.MergeInConfig(); err != nil {
+			var notFound viper.ConfigFileNotFoundError
+			if !errors.As(err, &notFound) {
+				return nil, fmt.Errorf("reading config.%s.yaml: %w", env, err)
+			}
+		}
+	}
+
+	if err := applyRenamedKeys(v); err != nil {
 		return nil, err
 	}
+	if err := applySecretFiles(v); err != nil {
+		return nil, err
+	}
+
+	var config Config
+	if err := v.Unmarshal(&config); err != nil {
+		return nil, fmt.Errorf("decoding config: %w", err)
+	}
 
 	return &config, nil
 }
+
+// applySecretFiles sets every key whose <KEY>_FILE variable is set to the
+// contents of that file, minus trailing newlines.
+func applySecretFiles(v *viper.Viper) error {
+	var errs []error
+	for _, key := range v.AllKeys() {
+		envName := envVar(key)
+		path := os.Getenv(envName + "_FILE")
+		if path == "" {
+			continue
+		}
+		if _, ok := os.LookupEnv(envName); ok {
+			errs = append(errs, fmt.Errorf("%s and %s_FILE are both set", envName, envName))
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("reading %s_FILE: %w", envName, err))
+			continue
+		}
+		v.Set(key, strings.TrimRight(string(data), "\r\n"))
+	}
+	return errors.Join(errs...)
+}
+
+// renamedKeys lists keys that were replaced, with a conversion of their value
+// to the new key. They are deprecated and will be removed in a later release.
+var renamedKeys = []struct {
+	old, new string
+	convert  func(value string) (any, error)
+}{
+	{"jwt.access_expiry_hour", "jwt.access_ttl", hoursToDuration},
+	{"jwt.refresh_expiry_hour", "jwt.refresh_ttl", hoursToDuration},
+}
+
+func hoursToDuration(value string) (any, error) {
+	hours, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("must be a whole number of hours, got %q", value)
+	}
+	return time.Duration(hours) * time.Hour, nil
+}
+
+// applyRenamedKeys copies every deprecated key that is set, in a config file
+// or the environment, to its replacement and warns about it. The shipped
+// config.yaml sets the new keys, so the old ones win over files; only the new
+// key's own environment variable or secret file takes precedence.
+func applyRenamedKeys(v *viper.Viper) error {
+	var errs []error
+	for _, k := range renamedKeys {
+		if !v.IsSet(k.old) {
+			continue
+		}
+		newEnv := envVar(k.new)
+		if os.Getenv(newEnv) != "" || os.Getenv(newEnv+"_FILE") != "" {
+			log.Printf("config: %s (%s) is deprecated and ignored because %s is set", k.old, envVar(k.old), newEnv)
+			continue
+		}
+		value, err := k.convert(v.GetString(k.old))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", k.old, err))
+			continue
+		}
+		log.Printf("config: %s (%s) is deprecated, use %s (%s) instead", k.old, envVar(k.old), k.new, newEnv)
+		v.Set(k.new, value)
+	}
+	return errors.Join(errs...)
+}
+
+// envVar returns the environment variable that overrides key.
+func envVar(key string) string {
+	return strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
@@ -0,0 +1,339 @@
+package configs
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func validConfig() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:           "8080",
+			Env:            EnvProd,
+			RequestTimeout: 15 * time.Second,
+			WriteTimeout:   20 * time.Second,
+		},
+		API:      APIConfig{LegacySince: "2026-10-19", LegacySunset: "2027-04-19"},
+		Database: DatabaseConfig{DSN: "host=localhost"},
+		JWT: JWTConfig{
+			Secret:     "0123456789abcdef0123456789abcdef",
+			AccessTTL:  15 * time.Minute,
+			RefreshTTL: 168 * time.Hour,
+		},
+		Health:  HealthConfig{CheckTimeout: 2 * time.Second},
+		Metrics: MetricsConfig{Enabled: true, Path: "/metrics", Token: "scraper-token"},
+		Tracing: TracingConfig{Exporter: "none", SampleRatio: 1},
+		Outbox: OutboxConfig{
+			PollInterval: time.Second,
+			BatchSize:    100,
+			MaxAttempts:  10,
+			RetryBase:    5 * time.Second,
+			RetryMax:     30 * time.Minute,
+			LockTimeout:  5 * time.Minute,
+			Retention:    7 * 24 * time.Hour,
+		},
+		Jobs: JobsConfig{
+			Workers:           4,
+			PollInterval:      time.Second,
+			LockTimeout:       5 * time.Minute,
+			MaxAttempts:       5,
+			RetryBase:         10 * time.Second,
+			RetryMax:          time.Hour,
+			SchedulerInterval: 15 * time.Second,
+			Retention:         7 * 24 * time.Hour,
+		},
+		Cache: CacheConfig{
+			Users:    CacheSettings{Enabled: true, Size: 10000, TTL: 5 * time.Minute},
+			Features: FeatureCacheSettings{Enabled: true, TTL: time.Minute},
+		},
+		Idempotency: IdempotencyConfig{TTL: 24 * time.Hour, LockTimeout: time.Minute},
+		Encryption: EncryptionConfig{
+			PrimaryKeyID:  "2024-05",
+			Keys:          []string{"2024-05:" + testKey},
+			BlindIndexKey: testKey,
+		},
+	}
+}
+
+// testKey is the base64 of 32 bytes.
+const testKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+
+func TestLoad_LayersDefaultsProfileAndEnv(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "config.yaml", "server:\n  env: prod\n  port: \"9000\"\ntracing:\n  sample_ratio: 1.0\n")
+	writeFile(t, dir, "config.prod.yaml", "tracing:\n  sample_ratio: 0.25\n")
+	t.Setenv("SERVER_PORT", "9100")
+
+	cfg, err := Load(dir)
+	require.NoError(t, err)
+
+	assert.Equal(t, "9100", cfg.Server.Port, "env overrides yaml")
+	assert.Equal(t, 0.25, cfg.Tracing.SampleRatio, "profile overrides base yaml")
+	assert.Equal(t, 15*time.Minute, cfg.JWT.AccessTTL, "typed default applies to missing keys")
+}
+
+func TestLoad_SecretFile(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "config.yaml", "server:\n  env: dev\n")
+	t.Setenv("JWT_SECRET_FILE", writeFile(t, dir, "jwt_secret", "from-file\n"))
+
+	cfg, err := Load(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "from-file", cfg.JWT.Secret)
+}
+
+func TestLoad_SecretFileConflictsWithVariable(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "config.yaml", "server:\n  env: dev\n")
+	t.Setenv("JWT_SECRET", "inline")
+	t.Setenv("JWT_SECRET_FILE", writeFile(t, dir, "jwt_secret", "from-file"))
+
+	_, err := Load(dir)
+	assert.ErrorContains(t, err, "JWT_SECRET and JWT_SECRET_FILE are both set")
+}
+
+func TestLoad_RenamedJWTKeys(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "config.yaml", "jwt:\n  access_ttl: 15m\n  refresh_expiry_hour: 48\n")
+	t.Setenv("JWT_ACCESS_EXPIRY_HOUR", "2")
+
+	cfg, err := Load(dir)
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Hour, cfg.JWT.AccessTTL, "the old variable still applies")
+	assert.Equal(t, 48*time.Hour, cfg.JWT.RefreshTTL, "the old key still applies")
+
+	t.Setenv("JWT_ACCESS_TTL", "30m")
+	cfg, err = Load(dir)
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Minute, cfg.JWT.AccessTTL, "the new variable wins")
+
+	t.Setenv("JWT_ACCESS_TTL", "")
+	t.Setenv("JWT_ACCESS_EXPIRY_HOUR", "a day")
+	_, err = Load(dir)
+	assert.ErrorContains(t, err, `jwt.access_expiry_hour: must be a whole number of hours, got "a day"`)
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		want   []string
+	}{
+		{
+			name:   "valid",
+			modify: func(c *Config) {},
+		},
+		{
+			name: "missing secrets are reported together",
+			modify: func(c *Config) {
+				c.Database.DSN = ""
+				c.JWT.Secret = ""
+			},
+			want: []string{
+				"database.dsn is required (set DATABASE_DSN or DATABASE_DSN_FILE)",
+				"jwt.secret is required (set JWT_SECRET or JWT_SECRET_FILE)",
+			},
+		},
+		{
+			name:   "short secret in prod",
+			modify: func(c *Config) { c.JWT.Secret = "short" },
+			want:   []string{"jwt.secret must be at least 32 characters and not a placeholder in prod"},
+		},
+		{
+			name: "short secret allowed in dev",
+			modify: func(c *Config) {
+				c.Server.Env = EnvDev
+				c.JWT.Secret = "short"
+			},
+		},
+		{
+			name:   "unknown env",
+			modify: func(c *Config) { c.Server.Env = "staging" },
+			want:   []string{`server.env must be one of dev, test, prod; got "staging"`},
+		},
+		{
+			name:   "write timeout shorter than request timeout",
+			modify: func(c *Config) { c.Server.WriteTimeout = 10 * time.Second },
+			want:   []string{"server.write_timeout (10s) must be longer than server.request_timeout (15s)"},
+		},
+		{
+			name:   "legacy since not a date",
+			modify: func(c *Config) { c.API.LegacySince = "19/10/2026" },
+			want:   []string{`api.legacy_since must be a YYYY-MM-DD date, got "19/10/2026"`},
+		},
+		{
+			name:   "legacy sunset before since",
+			modify: func(c *Config) { c.API.LegacySunset = "2026-10-01" },
+			want:   []string{`api.legacy_sunset must be a YYYY-MM-DD date after api.legacy_since, got "2026-10-01"`},
+		},
+		{
+			name:   "legacy alias without sunset",
+			modify: func(c *Config) { c.API.LegacySunset = "" },
+		},
+		{
+			name: "dev encryption keys outside dev",
+			modify: func(c *Config) {
+				c.Server.Env = EnvTest
+				c.Encryption.Keys = []string{"dev:ZGV2LW9ubHktZW5jcnlwdGlvbi1rZXktMzItYnl0ZXM="}
+				c.Encryption.BlindIndexKey = "ZGV2LW9ubHktYmxpbmQtaW5kZXgta2V5LTMyYnl0ZXM="
+			},
+			want: []string{
+				"encryption.keys: key \"dev\" is the published dev key, only accepted when server.env is dev; generate one with `openssl rand -base64 32`",
+				"encryption.blind_index_key is the published dev key, only accepted when server.env is dev",
+			},
+		},
+		{
+			name:   "public metrics in prod",
+			modify: func(c *Config) { c.Metrics.Token = "" },
+			want:   []string{"metrics.token is required in prod, or /metrics is public (set METRICS_TOKEN or METRICS_TOKEN_FILE)"},
+		},
+		{
+			name: "public metrics allowed in dev",
+			modify: func(c *Config) {
+				c.Server.Env = EnvDev
+				c.Metrics.Token = ""
+			},
+		},
+		{
+			name: "metrics disabled in prod",
+			modify: func(c *Config) {
+				c.Metrics.Enabled = false
+				c.Metrics.Token = ""
+			},
+		},
+		{
+			name:   "bad exporter",
+			modify: func(c *Config) { c.Tracing.Exporter = "jaeger" },
+			want:   []string{`tracing.exporter must be one of none, stdout, otlp; got "jaeger"`},
+		},
+		{
+			name:   "idempotency lock shorter than request timeout",
+			modify: func(c *Config) { c.Idempotency.LockTimeout = 10 * time.Second },
+			want:   []string{"idempotency.lock_timeout (10s) must be longer than server.request_timeout (15s)"},
+		},
+		{
+			name:   "feature cache without ttl",
+			modify: func(c *Config) { c.Cache.Features.TTL = 0 },
+			want:   []string{"cache.features.ttl must be positive"},
+		},
+		{
+			name: "trusted proxies and origins",
+			modify: func(c *Config) {
+				c.Server.TrustedProxies = []string{"10.0.0.0/8", "192.168.1.1", "::1", "proxy.local"}
+				c.CORS.AllowedOrigins = []string{"https://app.example.com", "https://*.example.com:8443", "https://app.example.com/", "app.example.com"}
+			},
+			want: []string{
+				`server.trusted_proxies: "proxy.local" is not an IP address or CIDR`,
+				`cors.allowed_origins: "https://app.example.com/" must be "*" or scheme://host[:port], optionally with a "*." subdomain wildcard`,
+				`cors.allowed_origins: "app.example.com" must be "*" or scheme://host[:port], optionally with a "*." subdomain wildcard`,
+			},
+		},
+		{
+			name: "encryption keys",
+			modify: func(c *Config) {
+				c.Encryption.PrimaryKeyID = ""
+				c.Encryption.Keys = []string{"2024-05:" + testKey, "short:c2hvcnQ=", testKey}
+				c.Encryption.BlindIndexKey = "c2hvcnQ="
+			},
+			want: []string{
+				"encryption.primary_key_id is required (set ENCRYPTION_PRIMARY_KEY_ID)",
+				"encryption.keys: entries must be <id>:<base64 of 32 bytes>",
+				"encryption.keys: entries must be <id>:<base64 of 32 bytes>",
+				"encryption.blind_index_key must be the base64 of at least 32 bytes (set ENCRYPTION_BLIND_INDEX_KEY or ENCRYPTION_BLIND_INDEX_KEY_FILE)",
+			},
+		},
+		{
+			name: "encryption keys from a directory",
+			modify: func(c *Config) {
+				c.Encryption.Keys = nil
+				c.Encryption.KeyDir = "/run/secrets/encryption"
+			},
+		},
+		{
+			name: "any origin with credentials",
+			modify: func(c *Config) {
+				c.CORS.AllowedOrigins = []string{"*"}
+				c.CORS.AllowCredentials = true
+			},
+			want: []string{`cors.allowed_origins must not contain "*" when cors.allow_credentials is set`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cfg := validConfig()
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if tt.want == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var verr *ValidationError
+			require.ErrorAs(t, err, &verr)
+			assert.ElementsMatch(t, tt.want, verr.Problems)
+		})
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	t.Parallel()
+	cfg := validConfig()
+	cfg.Metrics.Token = ""
+
+	var buf bytes.Buffer
+	require.NoError(t, cfg.Redacted().WriteYAML(&buf))
+
+	out := buf.String()
+	assert.NotContains(t, out, cfg.JWT.Secret)
+	assert.NotContains(t, out, cfg.Database.DSN)
+	assert.Contains(t, out, "secret: '[REDACTED]'")
+	assert.NotContains(t, out, testKey)
+	assert.Contains(t, out, "- 2024-05:[REDACTED]", "key IDs are kept")
+	assert.Contains(t, out, "access_ttl: 15m0s")
+	assert.Contains(t, out, `token: ""`, "empty secrets stay empty")
+	assert.Equal(t, "0123456789abcdef0123456789abcdef", cfg.JWT.Secret, "original is not modified")
+	assert.Equal(t, []string{"2024-05:" + testKey}, cfg.Encryption.Keys)
+}
+
+func TestLoad_ListsFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "config.yaml", "cors:\n  allowed_origins: [\"https://app.example.com\"]\n")
+	t.Setenv("SERVER_TRUSTED_PROXIES", "10.0.0.0/8,172.16.0.0/12")
+
+	cfg, err := Load(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.0/8", "172.16.0.0/12"}, cfg.Server.TrustedProxies, "comma-separated in the environment")
+	assert.Equal(t, []string{"https://app.example.com"}, cfg.CORS.AllowedOrigins)
+}
+
+func TestLoad_DevProfileHasEncryptionKeys(t *testing.T) {
+	t.Setenv("SERVER_ENV", EnvDev)
+	t.Setenv("DATABASE_DSN", "host=localhost")
+	t.Setenv("JWT_SECRET", "dev-secret")
+
+	cfg, err := Load(".")
+	require.NoError(t, err)
+	require.NoError(t, cfg.Validate(), "a dev server starts without generated keys")
+
+	cfg.Server.Env = EnvProd
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "published dev key")
+}
@@ -0,0 +1,76 @@
+package configs
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+const redactedValue = "[REDACTED]"
+
+// Redacted returns a copy of c with credentials replaced by a placeholder, so
+// it can be printed or logged.
+func (c Config) Redacted() Config {
+	redact := func(s *string) {
+		if *s != "" {
+			*s = redactedValue
+		}
+	}
+	redact(&c.Database.DSN)
+	redact(&c.JWT.Secret)
+	redact(&c.Metrics.Token)
+	redact(&c.Encryption.BlindIndexKey)
+	if len(c.Encryption.Keys) > 0 {
+		// Key IDs are kept: they tell which keys are loaded.
+		keys := make([]string, len(c.Encryption.Keys))
+		for i, key := range c.Encryption.Keys {
+			id, _, _ := strings.Cut(key, ":")
+			keys[i] = id + ":" + redactedValue
+		}
+		c.Encryption.Keys = keys
+	}
+	return c
+}
+
+// WriteYAML writes c in the layout of config.yaml, with durations in Go
+// syntax (e.g. 15s), so the output can be used as a config file.
+func (c Config) WriteYAML(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(toYAMLNode(reflect.ValueOf(c))); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// toYAMLNode converts a config struct into a mapping keyed like the
+// mapstructure tags viper decodes with, preserving field order.
+func toYAMLNode(v reflect.Value) *yaml.Node {
+	if v.Type() == durationType {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: time.Duration(v.Int()).String()}
+	}
+	if v.Kind() != reflect.Struct {
+		node := &yaml.Node{}
+		_ = node.Encode(v.Interface())
+		return node
+	}
+
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		key := f.Tag.Get("mapstructure")
+		if key == "" {
+			key = strings.ToLower(f.Name)
+		}
+		node.Content = append(node.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: key},
+			toYAMLNode(v.Field(i)),
+		)
+	}
+	return node
+}
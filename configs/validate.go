@@ -0,0 +1,186 @@
+package configs
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// minProdSecretLength is the minimum JWT secret length in prod: 32 bytes is
+// the HMAC-SHA256 key size.
+const minProdSecretLength = 32
+
+// weakSecrets are placeholder values that must never reach production.
+var weakSecrets = map[string]bool{
+	"secret":         true,
+	"changeme":       true,
+	"supersecretkey": true,
+	"your-secret":    true,
+}
+
+// devEncryptionKeys are the published keys of configs/config.dev.yaml, only
+// accepted when server.env is dev.
+var devEncryptionKeys = map[string]bool{
+	"ZGV2LW9ubHktZW5jcnlwdGlvbi1rZXktMzItYnl0ZXM=": true,
+	"ZGV2LW9ubHktYmxpbmQtaW5kZXgta2V5LTMyYnl0ZXM=": true,
+}
+
+// ValidationError lists every problem found by Config.Validate.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Validate checks the whole configuration and reports all problems at once.
+func (c *Config) Validate() error {
+	var problems []string
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	s := c.Server
+	port, err := strconv.Atoi(s.Port)
+	check(err == nil && port > 0 && port <= 65535, "server.port must be a TCP port, got %q", s.Port)
+	check(s.Env == EnvDev || s.Env == EnvTest || s.Env == EnvProd,
+		"server.env must be one of %s, %s, %s; got %q", EnvDev, EnvTest, EnvProd, s.Env)
+	for name, d := range map[string]time.Duration{
+		"server.request_timeout":     s.RequestTimeout,
+		"server.read_timeout":        s.ReadTimeout,
+		"server.read_header_timeout": s.ReadHeaderTimeout,
+		"server.write_timeout":       s.WriteTimeout,
+		"server.idle_timeout":        s.IdleTimeout,
+		"server.drain_delay":         s.DrainDelay,
+		"server.shutdown_timeout":    s.ShutdownTimeout,
+	} {
+		check(d >= 0, "%s must not be negative", name)
+	}
+	check(s.WriteTimeout == 0 || s.RequestTimeout == 0 || s.WriteTimeout > s.RequestTimeout,
+		"server.write_timeout (%s) must be longer than server.request_timeout (%s)", s.WriteTimeout, s.RequestTimeout)
+	check(s.MaxHeaderBytes >= 0, "server.max_header_bytes must not be negative")
+	for _, proxy := range s.TrustedProxies {
+		check(validProxy(proxy), "server.trusted_proxies: %q is not an IP address or CIDR", proxy)
+	}
+
+	since, err := time.Parse(time.DateOnly, c.API.LegacySince)
+	check(err == nil, "api.legacy_since must be a YYYY-MM-DD date, got %q", c.API.LegacySince)
+	if c.API.LegacySunset != "" {
+		sunset, err := time.Parse(time.DateOnly, c.API.LegacySunset)
+		check(err == nil && sunset.After(since), "api.legacy_sunset must be a YYYY-MM-DD date after api.legacy_since, got %q", c.API.LegacySunset)
+	}
+
+	check(c.Database.DSN != "", "database.dsn is required (set DATABASE_DSN or DATABASE_DSN_FILE)")
+
+	check(c.JWT.Secret != "", "jwt.secret is required (set JWT_SECRET or JWT_SECRET_FILE)")
+	if s.Env == EnvProd && c.JWT.Secret != "" {
+		check(len(c.JWT.Secret) >= minProdSecretLength && !weakSecrets[strings.ToLower(c.JWT.Secret)],
+			"jwt.secret must be at least %d characters and not a placeholder in prod", minProdSecretLength)
+	}
+	check(c.JWT.AccessTTL > 0, "jwt.access_ttl must be positive")
+	check(c.JWT.RefreshTTL > c.JWT.AccessTTL, "jwt.refresh_ttl must be longer than jwt.access_ttl")
+
+	check(c.Health.CheckTimeout > 0, "health.check_timeout must be positive")
+
+	if c.Metrics.Enabled {
+		check(strings.HasPrefix(c.Metrics.Path, "/"), "metrics.path must start with /, got %q", c.Metrics.Path)
+		check(s.Env != EnvProd || c.Metrics.Token != "",
+			"metrics.token is required in prod, or /metrics is public (set METRICS_TOKEN or METRICS_TOKEN_FILE)")
+	}
+
+	switch c.Tracing.Exporter {
+	case "", "none", "stdout", "otlp":
+	default:
+		check(false, "tracing.exporter must be one of none, stdout, otlp; got %q", c.Tracing.Exporter)
+	}
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1,
+		"tracing.sample_ratio must be between 0 and 1, got %v", c.Tracing.SampleRatio)
+
+	o := c.Outbox
+	check(o.PollInterval > 0, "outbox.poll_interval must be positive")
+	check(o.BatchSize > 0, "outbox.batch_size must be positive")
+	check(o.MaxAttempts > 0, "outbox.max_attempts must be positive")
+	check(o.RetryBase > 0 && o.RetryMax >= o.RetryBase, "outbox.retry_base must be positive and not above outbox.retry_max")
+	check(o.LockTimeout > 0, "outbox.lock_timeout must be positive")
+	check(o.Retention > 0, "outbox.retention must be positive")
+
+	j := c.Jobs
+	check(j.Workers > 0, "jobs.workers must be positive")
+	check(j.PollInterval > 0, "jobs.poll_interval must be positive")
+	check(j.LockTimeout > 0, "jobs.lock_timeout must be positive")
+	check(j.MaxAttempts > 0, "jobs.max_attempts must be positive")
+	check(j.RetryBase > 0 && j.RetryMax >= j.RetryBase, "jobs.retry_base must be positive and not above jobs.retry_max")
+	check(j.SchedulerInterval > 0 && j.SchedulerInterval < time.Minute, "jobs.scheduler_interval must be positive and under a minute")
+	check(j.Retention > 0, "jobs.retention must be positive")
+
+	if c.Cache.Users.Enabled {
+		check(c.Cache.Users.Size > 0, "cache.users.size must be positive")
+		check(c.Cache.Users.TTL > 0, "cache.users.ttl must be positive")
+	}
+	if c.Cache.Features.Enabled {
+		check(c.Cache.Features.TTL > 0, "cache.features.ttl must be positive")
+	}
+
+	check(c.Idempotency.TTL > 0, "idempotency.ttl must be positive")
+	check(c.Idempotency.LockTimeout > s.RequestTimeout,
+		"idempotency.lock_timeout (%s) must be longer than server.request_timeout (%s)", c.Idempotency.LockTimeout, s.RequestTimeout)
+
+	for _, origin := range c.CORS.AllowedOrigins {
+		check(validOrigin(origin), "cors.allowed_origins: %q must be \"*\" or scheme://host[:port], optionally with a \"*.\" subdomain wildcard", origin)
+		check(origin != "*" || !c.CORS.AllowCredentials, "cors.allowed_origins must not contain \"*\" when cors.allow_credentials is set")
+	}
+	check(c.CORS.MaxAge >= 0, "cors.max_age must not be negative")
+	check(c.Security.HSTSMaxAge >= 0, "security.hsts_max_age must not be negative")
+
+	// Key files are only read when the keys are loaded at start-up.
+	e := c.Encryption
+	check(e.PrimaryKeyID != "", "encryption.primary_key_id is required (set ENCRYPTION_PRIMARY_KEY_ID)")
+	check(len(e.Keys) > 0 || e.KeyDir != "", "encryption.keys or encryption.key_dir is required (set ENCRYPTION_KEYS or ENCRYPTION_KEY_DIR)")
+	for _, key := range e.Keys {
+		id, material, ok := strings.Cut(key, ":")
+		check(ok && id != "" && validKey(material, 32, 32), "encryption.keys: entries must be <id>:<base64 of 32 bytes>")
+		check(s.Env == EnvDev || !devEncryptionKeys[material],
+			"encryption.keys: key %q is the published dev key, only accepted when server.env is dev; generate one with `openssl rand -base64 32`", id)
+	}
+	check(validKey(e.BlindIndexKey, 32, -1),
+		"encryption.blind_index_key must be the base64 of at least 32 bytes (set ENCRYPTION_BLIND_INDEX_KEY or ENCRYPTION_BLIND_INDEX_KEY_FILE)")
+	check(s.Env == EnvDev || !devEncryptionKeys[e.BlindIndexKey],
+		"encryption.blind_index_key is the published dev key, only accepted when server.env is dev")
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+func validProxy(s string) bool {
+	if _, _, err := net.ParseCIDR(s); err == nil {
+		return true
+	}
+	return net.ParseIP(s) != nil
+}
+
+// validOrigin accepts "*" and origins as browsers send them, e.g.
+// "https://app.example.com:8443", with an optional "*." before the host.
+func validOrigin(s string) bool {
+	if s == "*" {
+		return true
+	}
+	u, err := url.Parse(strings.Replace(s, "://*.", "://", 1))
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" &&
+		u.Path == "" && u.RawQuery == "" && u.Fragment == "" && u.User == nil
+}
+
+// validKey reports whether s is the standard base64 of min to max bytes; a
+// negative max means no upper bound.
+func validKey(s string, min, max int) bool {
+	key, err := base64.StdEncoding.DecodeString(s)
+	return err == nil && len(key) >= min && (max < 0 || len(key) <= max)
+}
@@ -1,19 +1,39 @@
 package app
 
 import (
+	"context"
 	"english-learning/configs"
+	"english-learning/internal/database"
+	"english-learning/internal/jobqueue"
+	"english-learning/internal/outbox"
 	"english-learning/internal/server"
+	"english-learning/pkg/encryption"
+	"english-learning/pkg/health"
+	"english-learning/pkg/lifecycle"
 	"english-learning/pkg/logger"
+	"english-learning/pkg/metrics"
+	"english-learning/pkg/tracing"
+	"errors"
 	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
-	driverpostgres "gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
 // App holds the application-level dependencies and manages the lifecycle.
 type App struct {
-	cfg *configs.Config
-	db  *gorm.DB
+	cfg       *configs.Config
+	db        *gorm.DB
+	lifecycle *lifecycle.Lifecycle
+	health    *health.Registry
+	metrics   *metrics.Metrics
+	events    *outbox.Dispatcher
+	jobs      *jobqueue.Worker
+	enc       *encryption.Service
 }
 
 // New initializes the application: logger, database, and returns an App instance.
@@ -21,30 +41,180 @@ func New(cfg *configs.Config) (*App, error) {
 	// Init Logger
 	logger.InitLogger(cfg.Server.Env)
 
+	lc := lifecycle.New()
+
+	// Init Tracing. The provider is flushed by the lifecycle on shutdown.
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing, cfg.Server.Env)
+	if err != nil {
+		return nil, fmt.Errorf("initializing tracing: %w", err)
+	}
+	lc.Append(lifecycle.Hook{Name: "tracing", OnStop: shutdownTracing})
+
+	// Init Metrics
+	var m *metrics.Metrics
+	var queryObserver logger.QueryObserver
+	if cfg.Metrics.Enabled {
+		m = metrics.New()
+		queryObserver = m
+	}
+
+	// Init Encryption, before anything can read personal data.
+	enc, err := encryption.New(cfg.Encryption)
+	if err != nil {
+		return nil, err
+	}
+
 	// Init Database
-	db, err := gorm.Open(driverpostgres.Open(cfg.Database.DSN), &gorm.Config{
-		Logger: logger.NewGormLogger(logger.Log, 0),
-	})
+	db, err := database.Open(cfg.Database, queryObserver)
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("getting sql.DB: %w", err)
+	}
+	m.RegisterDBStats(sqlDB, "postgres")
+
+	migrator, err := database.NewMigrator(sqlDB)
 	if err != nil {
-		return nil, fmt.Errorf("connecting to database: %w", err)
+		return nil, err
+	}
+	if cfg.Database.AutoMigrate {
+		results, err := migrator.Up(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		logger.Infof("app", "Applied %d migration(s)", len(results))
 	}
 
+	// Domain events are delivered after commit by the outbox dispatcher.
+	dispatcher := outbox.NewDispatcher(db, cfg.Outbox)
+	lc.Append(lifecycle.Hook{Name: "outbox", OnStart: dispatcher.Start, OnStop: dispatcher.Stop})
+
+	// Background jobs registered by modules run on the job worker.
+	worker := jobqueue.NewWorker(db, cfg.Jobs)
+	outbox.RegisterJobs(worker, db, cfg.Outbox)
+	lc.Append(lifecycle.Hook{Name: "jobs", OnStart: worker.Start, OnStop: worker.Stop})
+
+	healthRegistry := health.NewRegistry(cfg.Health.CheckTimeout)
+	healthRegistry.Register("database", health.DBPing(db))
+	healthRegistry.Register("migrations", migrator.CheckPending)
+
 	return &App{
-		cfg: cfg,
-		db:  db,
+		cfg:       cfg,
+		db:        db,
+		lifecycle: lc,
+		health:    healthRegistry,
+		metrics:   m,
+		events:    dispatcher,
+		jobs:      worker,
+		enc:       enc,
 	}, nil
 }
 
-// Run starts the HTTP server.
+// Lifecycle returns the hook registry that modules use to start and stop
+// background work together with the application.
+func (a *App) Lifecycle() *lifecycle.Lifecycle {
+	return a.lifecycle
+}
+
+// Run starts the lifecycle hooks and the HTTP server, then blocks until the
+// server fails or SIGINT/SIGTERM is received. On shutdown readiness fails for
+// cfg.Server.DrainDelay while the server keeps serving, then it stops
+// accepting connections and drains in-flight requests, after which hooks are
+// stopped in reverse order. Draining and stopping must finish within
+// cfg.Server.ShutdownTimeout. The DB pool is closed afterwards by Close.
 func (a *App) Run() error {
-	srv := server.New(a.cfg, a.db)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	logger.Infof("app", "Starting server on port %s", a.cfg.Server.Port)
-	if err := srv.Run(":" + a.cfg.Server.Port); err != nil {
-		return fmt.Errorf("starting server: %w", err)
+	handler, err := server.New(a.cfg, server.Deps{
+		DB:         a.db,
+		Lifecycle:  a.lifecycle,
+		Health:     a.health,
+		Metrics:    a.metrics,
+		Events:     a.events,
+		Jobs:       a.jobs,
+		Encryption: a.enc,
+	})
+	if err != nil {
+		return fmt.Errorf("creating router: %w", err)
+	}
+	srv := a.newHTTPServer(handler)
+
+	// The signal context is cancelled by stop below; hooks are ended by
+	// a.lifecycle.Stop instead, so they start on a context that outlives it.
+	if err := a.lifecycle.Start(context.Background()); err != nil {
+		return fmt.Errorf("starting lifecycle hooks: %w", err)
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		logger.Infof("app", "Starting server on port %s", a.cfg.Server.Port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
+		}
+		close(serveErr)
+	}()
+
+	var runErr error
+	drain := false
+	select {
+	case err := <-serveErr:
+		if err != nil {
+			runErr = fmt.Errorf("starting server: %w", err)
+		}
+	case <-ctx.Done():
+		logger.Infof("app", "Shutdown signal received, draining connections")
+		drain = true
+	}
+	stop()
+
+	return errors.Join(runErr, a.shutdown(srv, drain))
+}
+
+func (a *App) newHTTPServer(handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              ":" + a.cfg.Server.Port,
+		Handler:           handler,
+		ReadTimeout:       a.cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: a.cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      a.cfg.Server.WriteTimeout,
+		IdleTimeout:       a.cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    a.cfg.Server.MaxHeaderBytes,
+	}
+}
+
+// shutdown stops the server and the lifecycle hooks. With drain set it first
+// keeps serving while readiness fails, so load balancers take the instance
+// out of rotation before it stops accepting connections; a server that
+// failed to start has no traffic to drain.
+func (a *App) shutdown(srv *http.Server, drain bool) error {
+	if drain {
+		a.health.SetShuttingDown()
+		if a.cfg.Server.DrainDelay > 0 {
+			logger.Infof("app", "Draining for %s", a.cfg.Server.DrainDelay)
+			time.Sleep(a.cfg.Server.DrainDelay)
+		}
+	}
+
+	ctx := context.Background()
+	if a.cfg.Server.ShutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.cfg.Server.ShutdownTimeout)
+		defer cancel()
+	}
+
+	var errs []error
+	if err := srv.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("shutting down server: %w", err))
+	}
+	if err := a.lifecycle.Stop(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("stopping lifecycle hooks: %w", err))
 	}
 
-	return nil
+	logger.Infof("app", "Server stopped")
+	return errors.Join(errs...)
 }
 
 // Close performs cleanup (e.g., closing DB connections).
@@ -0,0 +1,31 @@
+// Package database opens the application's Postgres connection and manages its
+// schema migrations.
+package database
+
+import (
+	"english-learning/configs"
+	"english-learning/pkg/logger"
+	"english-learning/pkg/tracing"
+	"fmt"
+
+	driverpostgres "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Open connects to Postgres with the zap GORM logger and tracing callbacks
+// installed and error translation enabled. observer may be nil.
+func Open(cfg configs.DatabaseConfig, observer logger.QueryObserver) (*gorm.DB, error) {
+	db, err := gorm.Open(driverpostgres.Open(cfg.DSN), &gorm.Config{
+		Logger: logger.NewGormLogger(logger.Log, 0, observer),
+		// Report constraint violations as gorm.ErrDuplicatedKey etc. so
+		// repositories can map them to domain errors.
+		TranslateError: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("connecting to database: %w", err)
+	}
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		return nil, fmt.Errorf("registering gorm tracing: %w", err)
+	}
+	return db, nil
+}
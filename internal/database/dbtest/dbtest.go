@@ -0,0 +1,52 @@
+// Package dbtest gives tests a migrated Postgres database. Tests using it are
+// skipped unless TEST_DATABASE_DSN names a disposable database.
+package dbtest
+
+import (
+	"context"
+	"english-learning/configs"
+	"english-learning/internal/database"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// DSNEnv names the database tests may migrate and write to.
+const DSNEnv = "TEST_DATABASE_DSN"
+
+// Open connects to the test database and applies all migrations, or skips t
+// when DSNEnv is not set. The connection is closed when t finishes.
+func Open(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv(DSNEnv)
+	if dsn == "" {
+		t.Skipf("%s not set", DSNEnv)
+	}
+
+	db, err := database.Open(configs.DatabaseConfig{DSN: dsn}, nil)
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	migrator, err := database.NewMigrator(sqlDB)
+	require.NoError(t, err)
+	_, err = migrator.Up(context.Background())
+	require.NoError(t, err)
+
+	return db
+}
+
+// Truncate empties tables, and every table referencing them, and resets
+// their sequences.
+func Truncate(t *testing.T, db *gorm.DB, tables ...string) {
+	t.Helper()
+	quoted := make([]string, len(tables))
+	for i, table := range tables {
+		quoted[i] = `"` + table + `"`
+	}
+	require.NoError(t, db.Exec("TRUNCATE "+strings.Join(quoted, ", ")+" RESTART IDENTITY CASCADE").Error)
+}
@@ -0,0 +1,108 @@
+package database
+
+import (
+	"context"
+	"english-learning/pkg/logger"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Listener receives Postgres NOTIFY messages on one channel over a dedicated
+// connection, reconnecting with backoff when it drops. Notifications sent
+// while disconnected are lost, so onConnect runs after every (re)connect to
+// let the caller resynchronise, e.g. by clearing a cache.
+type Listener struct {
+	dsn       string
+	channel   string
+	handle    func(payload string)
+	onConnect func()
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewListener creates a Listener for channel. handle is called with the
+// payload of each notification, one at a time.
+func NewListener(dsn, channel string, handle func(payload string), onConnect func()) *Listener {
+	return &Listener{dsn: dsn, channel: channel, handle: handle, onConnect: onConnect}
+}
+
+// Start launches the listening loop. It has the signature of a lifecycle
+// OnStart hook.
+func (l *Listener) Start(context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	l.cancel = cancel
+	l.done = make(chan struct{})
+	go l.run(ctx)
+	return nil
+}
+
+// Stop closes the connection and waits for the loop to exit.
+func (l *Listener) Stop(ctx context.Context) error {
+	if l.cancel == nil {
+		return nil
+	}
+	l.cancel()
+	select {
+	case <-l.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("waiting for %s listener: %w", l.channel, ctx.Err())
+	}
+}
+
+const (
+	listenRetryBase = time.Second
+	listenRetryMax  = time.Minute
+)
+
+func (l *Listener) run(ctx context.Context) {
+	defer close(l.done)
+
+	delay := listenRetryBase
+	for ctx.Err() == nil {
+		connected, err := l.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if connected {
+			delay = listenRetryBase
+		}
+		logger.Warnf("database", "Listening on %s: %v; reconnecting in %s", l.channel, err, delay)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, listenRetryMax)
+	}
+}
+
+// listen connects, subscribes and delivers notifications until the
+// connection fails or ctx is cancelled. It reports whether the subscription
+// was established.
+func (l *Listener) listen(ctx context.Context) (bool, error) {
+	conn, err := pgx.Connect(ctx, l.dsn)
+	if err != nil {
+		return false, fmt.Errorf("connecting: %w", err)
+	}
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{l.channel}.Sanitize()); err != nil {
+		return false, fmt.Errorf("subscribing: %w", err)
+	}
+	if l.onConnect != nil {
+		l.onConnect()
+	}
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return true, err
+		}
+		l.handle(n.Payload)
+	}
+}
@@ -0,0 +1,66 @@
+package database
+
+import (
+	"english-learning/pkg/query"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Columns maps the API names of a query.Spec's fields to columns.
+type Columns map[string]string
+
+// Filter narrows db to the rows matching q's filters.
+func Filter(db *gorm.DB, q query.Query, columns Columns) *gorm.DB {
+	for _, f := range q.Filters {
+		column := columns[f.Field.Name]
+		switch f.Op {
+		case query.Contains:
+			db = db.Where(column+" ILIKE ?", "%"+escapeLike(f.Value.(string))+"%")
+		default:
+			db = db.Where(fmt.Sprintf("%s %s ?", column, operators[f.Op]), f.Value)
+		}
+	}
+	return db
+}
+
+var operators = map[query.Op]string{
+	query.Eq:  "=",
+	query.Gt:  ">",
+	query.Gte: ">=",
+	query.Lt:  "<",
+	query.Lte: "<=",
+}
+
+// escapeLike makes s match literally inside a LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// Seek orders db by q's sort and idColumn, skips to q's cursor (or q's
+// offset) and limits it to q.Limit+1 rows, as query.NewPage expects. The row
+// comparison lets an index on (sort column, id) serve every page.
+func Seek(db *gorm.DB, q query.Query, columns Columns, idColumn string) *gorm.DB {
+	column := columns[q.Sort.Field.Name]
+	direction, comparison := "ASC", ">"
+	if q.Sort.Desc {
+		direction, comparison = "DESC", "<"
+	}
+
+	if q.After != nil {
+		if column == idColumn {
+			db = db.Where(fmt.Sprintf("%s %s ?", idColumn, comparison), q.After.ID)
+		} else {
+			db = db.Where(fmt.Sprintf("(%s, %s) %s (?, ?)", column, idColumn, comparison), q.After.Value, q.After.ID)
+		}
+	}
+	if column != idColumn {
+		db = db.Order(column + " " + direction)
+	}
+	db = db.Order(idColumn + " " + direction).Limit(q.Limit + 1)
+	if q.Offset > 0 {
+		db = db.Offset(q.Offset)
+	}
+	return db
+}
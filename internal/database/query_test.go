@@ -0,0 +1,98 @@
+package database
+
+import (
+	"english-learning/pkg/query"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	driverpostgres "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type widget struct {
+	ID        uint
+	Name      string
+	CreatedAt int64
+}
+
+var widgetSpec = query.Spec{
+	Fields: []query.Field{
+		{Name: "id", Type: query.Int, Sortable: true},
+		{Name: "name", Type: query.String, Ops: []query.Op{query.Eq, query.Contains}, Sortable: true},
+		{Name: "createdAt", Type: query.Int, Ops: []query.Op{query.Gte}, Sortable: true},
+	},
+	DefaultSort:  "id",
+	DefaultLimit: 10,
+	MaxLimit:     50,
+}
+
+var widgetColumns = Columns{"id": "id", "name": "name", "createdAt": "created_at"}
+
+// toSQL renders the statement Filter and Seek build for a query string,
+// without a database.
+func toSQL(t *testing.T, raw string) string {
+	t.Helper()
+	db, err := gorm.Open(driverpostgres.New(driverpostgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	require.NoError(t, err)
+
+	values, err := url.ParseQuery(raw)
+	require.NoError(t, err)
+	q, err := widgetSpec.Parse(values)
+	require.NoError(t, err)
+
+	return db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		var widgets []widget
+		return Seek(Filter(tx.Model(&widget{}), q, widgetColumns), q, widgetColumns, "id").Find(&widgets)
+	})
+}
+
+func TestFilterAndSeek(t *testing.T) {
+	t.Parallel()
+	after := query.EncodeCursor(query.Cursor{Value: "b", ID: 7}, query.Sort{Field: widgetSpec.Fields[1], Desc: true})
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{
+			"",
+			`SELECT * FROM "widgets" ORDER BY id ASC LIMIT 11`,
+		},
+		{
+			"name[contains]=50%25_off&createdAt[gte]=3&limit=5",
+			`SELECT * FROM "widgets" WHERE created_at >= 3 AND name ILIKE '%50\%\_off%' ORDER BY id ASC LIMIT 6`,
+		},
+		{
+			"sort=-name&cursor=" + after,
+			`SELECT * FROM "widgets" WHERE (name, id) < ('b', 7) ORDER BY name DESC,id DESC LIMIT 11`,
+		},
+		{
+			"sort=-id&cursor=" + query.EncodeCursor(query.Cursor{Value: int64(7), ID: 7}, query.Sort{Field: widgetSpec.Fields[0], Desc: true}),
+			`SELECT * FROM "widgets" WHERE id < 7 ORDER BY id DESC LIMIT 11`,
+		},
+	}
+	for _, tt := range tests {
+		assert.Equalf(t, tt.want, toSQL(t, tt.query), "query %s", tt.query)
+	}
+}
+
+func TestSeek_Offset(t *testing.T) {
+	t.Parallel()
+	db, err := gorm.Open(driverpostgres.New(driverpostgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	require.NoError(t, err)
+	q, _, _, err := widgetSpec.ParseOffset(url.Values{"page": {"3"}, "page_size": {"5"}}, 10)
+	require.NoError(t, err)
+
+	sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		var widgets []widget
+		return Seek(Filter(tx.Model(&widget{}), q, widgetColumns), q, widgetColumns, "id").Find(&widgets)
+	})
+	assert.Equal(t, `SELECT * FROM "widgets" ORDER BY id ASC LIMIT 6 OFFSET 10`, sql)
+}
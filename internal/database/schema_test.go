@@ -0,0 +1,194 @@
+package database_test
+
+import (
+	"english-learning/internal/database/dbtest"
+	"english-learning/internal/idempotency"
+	featurePostgres "english-learning/internal/modules/feature/repository/postgres"
+	jobPostgres "english-learning/internal/modules/job/repository/postgres"
+	sessionPostgres "english-learning/internal/modules/session/repository/postgres"
+	userPostgres "english-learning/internal/modules/user/repository/postgres"
+	"english-learning/internal/outbox"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// models lists every GORM model backed by a table created in migrations/.
+var models = []interface{}{
+	&userPostgres.User{},
+	&sessionPostgres.Session{},
+	&outbox.Event{},
+	&jobPostgres.Job{},
+	&jobPostgres.Schedule{},
+	&idempotency.Record{},
+	&featurePostgres.Flag{},
+}
+
+// TestModelsMatchMigratedSchema applies the embedded migrations and checks
+// that every GORM model agrees with the resulting tables: same columns, types,
+// nullability and indexes, and that relations are backed by foreign keys.
+func TestModelsMatchMigratedSchema(t *testing.T) {
+	db := dbtest.Open(t)
+
+	for _, model := range models {
+		sch, err := schema.Parse(model, &sync.Map{}, db.NamingStrategy)
+		require.NoError(t, err)
+
+		t.Run(sch.Table, func(t *testing.T) {
+			assertColumns(t, db, model, sch)
+			assertIndexes(t, db, model, sch)
+			assertForeignKeys(t, db, sch)
+		})
+	}
+}
+
+func assertColumns(t *testing.T, db *gorm.DB, model interface{}, sch *schema.Schema) {
+	columnTypes, err := db.Migrator().ColumnTypes(model)
+	require.NoError(t, err)
+
+	columns := make(map[string]gorm.ColumnType, len(columnTypes))
+	for _, ct := range columnTypes {
+		columns[ct.Name()] = ct
+	}
+
+	for _, field := range sch.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		ct, ok := columns[field.DBName]
+		if !assert.Truef(t, ok, "column %s.%s is missing from the schema", sch.Table, field.DBName) {
+			continue
+		}
+		delete(columns, field.DBName)
+
+		wantType, wantLength := normalizeType(db.Migrator().FullDataTypeOf(field).SQL)
+		gotType, _ := normalizeType(ct.DatabaseTypeName())
+		assert.Equalf(t, wantType, gotType, "type of %s.%s", sch.Table, field.DBName)
+		if wantLength > 0 {
+			gotLength, _ := ct.Length()
+			assert.EqualValuesf(t, wantLength, gotLength, "length of %s.%s", sch.Table, field.DBName)
+		}
+
+		if nullable, ok := ct.Nullable(); ok {
+			wantNotNull := field.NotNull || field.PrimaryKey
+			assert.Equalf(t, wantNotNull, !nullable, "NOT NULL on %s.%s", sch.Table, field.DBName)
+		}
+	}
+
+	for name := range columns {
+		t.Errorf("column %s.%s has no field on the model", sch.Table, name)
+	}
+}
+
+func assertIndexes(t *testing.T, db *gorm.DB, model interface{}, sch *schema.Schema) {
+	for _, idx := range sch.ParseIndexes() {
+		assert.Truef(t, db.Migrator().HasIndex(model, idx.Name), "index %s is missing from the schema", idx.Name)
+	}
+}
+
+func assertForeignKeys(t *testing.T, db *gorm.DB, sch *schema.Schema) {
+	for _, rel := range sch.Relationships.Relations {
+		constraint := rel.ParseConstraint()
+		if constraint == nil || constraint.Schema != sch {
+			continue
+		}
+		for i, fk := range constraint.ForeignKeys {
+			var deleteRule string
+			err := db.Raw(`
+				SELECT rc.delete_rule
+				FROM information_schema.referential_constraints rc
+				JOIN information_schema.key_column_usage kcu
+				  ON kcu.constraint_name = rc.constraint_name
+				 AND kcu.constraint_schema = rc.constraint_schema
+				JOIN information_schema.constraint_column_usage ccu
+				  ON ccu.constraint_name = rc.unique_constraint_name
+				 AND ccu.constraint_schema = rc.unique_constraint_schema
+				WHERE kcu.table_schema = CURRENT_SCHEMA()
+				  AND kcu.table_name = ? AND kcu.column_name = ?
+				  AND ccu.table_name = ? AND ccu.column_name = ?`,
+				sch.Table, fk.DBName,
+				constraint.ReferenceSchema.Table, constraint.References[i].DBName,
+			).Scan(&deleteRule).Error
+			require.NoError(t, err)
+
+			if !assert.NotEmptyf(t, deleteRule, "foreign key %s.%s -> %s.%s is missing",
+				sch.Table, fk.DBName, constraint.ReferenceSchema.Table, constraint.References[i].DBName) {
+				continue
+			}
+			if constraint.OnDelete != "" {
+				assert.Equalf(t, strings.ToUpper(constraint.OnDelete), deleteRule, "ON DELETE of %s.%s", sch.Table, fk.DBName)
+			}
+		}
+	}
+}
+
+var typeLength = regexp.MustCompile(`^([a-z ]+?)\s*\((\d+)\)`)
+
+// typeAliases maps the spellings used in model tags to the names Postgres
+// reports in information_schema.
+var typeAliases = map[string]string{
+	"bigserial":                "int8",
+	"bigint":                   "int8",
+	"serial":                   "int4",
+	"integer":                  "int4",
+	"smallint":                 "int2",
+	"boolean":                  "bool",
+	"character varying":        "varchar",
+	"timestamp with time zone": "timestamptz",
+	"timestamp":                "timestamp",
+	"decimal":                  "numeric",
+}
+
+// normalizeType reduces a column definition such as "varchar(255) NOT NULL"
+// or "timestamp with time zone" to its base type name and declared length.
+func normalizeType(def string) (string, int) {
+	def = strings.ToLower(strings.TrimSpace(def))
+
+	var length int
+	if m := typeLength.FindStringSubmatch(def); m != nil {
+		def = m[1]
+		length, _ = strconv.Atoi(m[2])
+	} else {
+		for _, kw := range []string{" not null", " default", " primary key", " unique"} {
+			if i := strings.Index(def, kw); i >= 0 {
+				def = def[:i]
+			}
+		}
+	}
+
+	def = strings.TrimSpace(def)
+	if alias, ok := typeAliases[def]; ok {
+		def = alias
+	}
+	return def, length
+}
+
+func TestNormalizeType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		def        string
+		wantType   string
+		wantLength int
+	}{
+		{"varchar(255) NOT NULL", "varchar", 255},
+		{"VARCHAR", "varchar", 0},
+		{"bigserial", "int8", 0},
+		{"timestamp with time zone", "timestamptz", 0},
+		{"boolean NOT NULL DEFAULT false", "bool", 0},
+		{"text", "text", 0},
+	}
+
+	for _, tt := range tests {
+		gotType, gotLength := normalizeType(tt.def)
+		assert.Equal(t, tt.wantType, gotType, tt.def)
+		assert.Equal(t, tt.wantLength, gotLength, tt.def)
+	}
+}
@@ -0,0 +1,33 @@
+package idempotency
+
+import (
+	"context"
+	"english-learning/pkg/cron"
+	"english-learning/pkg/idempotency"
+	"english-learning/pkg/jobs"
+	"english-learning/pkg/logger"
+	"fmt"
+	"time"
+)
+
+// PurgeExpiredKeys deletes idempotency keys past their TTL. It is scheduled
+// hourly by RegisterJobs.
+type PurgeExpiredKeys struct{}
+
+func (PurgeExpiredKeys) JobKind() string { return "idempotency.purge_expired" }
+
+// purgeExpiredSchedule runs the cleanup at the top of every hour.
+var purgeExpiredSchedule = cron.MustParse("0 * * * *")
+
+// RegisterJobs registers the idempotency key cleanup and its schedule.
+func RegisterJobs(r jobs.Registry, store idempotency.Store) {
+	jobs.Handle(r, func(ctx context.Context, _ PurgeExpiredKeys) error {
+		deleted, err := store.DeleteExpired(ctx, time.Now())
+		if err != nil {
+			return fmt.Errorf("deleting expired idempotency keys: %w", err)
+		}
+		logger.Infof("idempotency", "Purged %d expired idempotency key(s)", deleted)
+		return nil
+	})
+	r.Schedule("idempotency.purge_expired", purgeExpiredSchedule, PurgeExpiredKeys{})
+}
@@ -0,0 +1,47 @@
+package idempotency
+
+import (
+	"encoding/json"
+	"english-learning/pkg/idempotency"
+	"net/http"
+	"time"
+)
+
+// Record is a row of idempotency_keys.
+type Record struct {
+	ID             uint64 `gorm:"primaryKey"`
+	Scope          string `gorm:"type:varchar(64);not null;uniqueIndex:idx_idempotency_keys_scope_key_route,priority:1"`
+	IdempotencyKey string `gorm:"type:varchar(255);not null;uniqueIndex:idx_idempotency_keys_scope_key_route,priority:2"`
+	Route          string `gorm:"type:varchar(255);not null;uniqueIndex:idx_idempotency_keys_scope_key_route,priority:3"`
+	Fingerprint    string `gorm:"type:varchar(64);not null"`
+	// ResponseStatus is NULL while the request is in flight.
+	ResponseStatus  *int            `gorm:"type:integer"`
+	ResponseHeaders json.RawMessage `gorm:"type:jsonb"`
+	ResponseBody    []byte          `gorm:"type:bytea"`
+	LockedUntil     time.Time       `gorm:"type:timestamp with time zone;not null"`
+	ExpiresAt       time.Time       `gorm:"type:timestamp with time zone;not null;index:idx_idempotency_keys_expires_at"`
+	CreatedAt       time.Time       `gorm:"type:timestamp with time zone;not null"`
+}
+
+func (Record) TableName() string {
+	return "idempotency_keys"
+}
+
+func (m *Record) toDomain() (*idempotency.Record, error) {
+	rec := &idempotency.Record{
+		Key:         idempotency.Key{Scope: m.Scope, Key: m.IdempotencyKey, Route: m.Route},
+		Fingerprint: m.Fingerprint,
+		LockedUntil: m.LockedUntil,
+		ExpiresAt:   m.ExpiresAt,
+	}
+	if m.ResponseStatus != nil {
+		res := &idempotency.Response{Status: *m.ResponseStatus, Header: http.Header{}, Body: m.ResponseBody}
+		if len(m.ResponseHeaders) > 0 {
+			if err := json.Unmarshal(m.ResponseHeaders, &res.Header); err != nil {
+				return nil, err
+			}
+		}
+		rec.Response = res
+	}
+	return rec, nil
+}
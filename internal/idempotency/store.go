@@ -0,0 +1,121 @@
+// Package idempotency stores the responses of requests sent with an
+// Idempotency-Key header in the Postgres idempotency_keys table. Store
+// implements pkg/idempotency.Store; RegisterJobs purges expired keys.
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"english-learning/pkg/idempotency"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// acquireAttempts bounds the retries of Acquire when the record it
+// conflicted with is deleted before it can be read.
+const acquireAttempts = 3
+
+// Store implements idempotency.Store on idempotency_keys. Records are
+// written outside any unit of work: a key must stay claimed whether or not
+// the handler's transaction commits.
+type Store struct {
+	db *gorm.DB
+}
+
+// NewStore creates a Store for db.
+func NewStore(db *gorm.DB) *Store {
+	return &Store{db: db}
+}
+
+// Acquire implements idempotency.Store. The insert takes over, in the same
+// statement, a row that expired or whose request was abandoned, so of
+// concurrent requests exactly one gets the key.
+func (s *Store) Acquire(ctx context.Context, rec idempotency.Record) (*idempotency.Record, bool, error) {
+	now := time.Now()
+	db := s.db.WithContext(ctx)
+
+	for attempt := 0; attempt < acquireAttempts; attempt++ {
+		var ids []uint64
+		err := db.Raw(`
+			INSERT INTO idempotency_keys (scope, idempotency_key, route, fingerprint, locked_until, expires_at, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (scope, idempotency_key, route) DO UPDATE SET
+				fingerprint = EXCLUDED.fingerprint,
+				locked_until = EXCLUDED.locked_until,
+				expires_at = EXCLUDED.expires_at,
+				created_at = EXCLUDED.created_at,
+				response_status = NULL,
+				response_headers = NULL,
+				response_body = NULL
+			WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+				OR (idempotency_keys.response_status IS NULL
+					AND idempotency_keys.locked_until <= EXCLUDED.created_at
+					AND idempotency_keys.fingerprint = EXCLUDED.fingerprint)
+			RETURNING id`,
+			rec.Scope, rec.Key.Key, rec.Route, rec.Fingerprint, lease(rec), rec.ExpiresAt, now,
+		).Scan(&ids).Error
+		if err != nil {
+			return nil, false, err
+		}
+		if len(ids) == 1 {
+			return nil, true, nil
+		}
+
+		var row Record
+		err = db.Where("scope = ? AND idempotency_key = ? AND route = ?", rec.Scope, rec.Key.Key, rec.Route).
+			Take(&row).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue // purged meanwhile; try to insert again
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		existing, err := row.toDomain()
+		if err != nil {
+			return nil, false, fmt.Errorf("decoding stored response: %w", err)
+		}
+		return existing, false, nil
+	}
+	return nil, false, fmt.Errorf("idempotency key %q kept disappearing", rec.Key.Key)
+}
+
+// Complete implements idempotency.Store.
+func (s *Store) Complete(ctx context.Context, rec idempotency.Record, res idempotency.Response) error {
+	headers, err := json.Marshal(res.Header)
+	if err != nil {
+		return fmt.Errorf("encoding response headers: %w", err)
+	}
+	return s.owned(ctx, rec).Updates(map[string]interface{}{
+		"response_status":  res.Status,
+		"response_headers": headers,
+		"response_body":    res.Body,
+	}).Error
+}
+
+// Release implements idempotency.Store.
+func (s *Store) Release(ctx context.Context, rec idempotency.Record) error {
+	return s.owned(ctx, rec).Delete(&Record{}).Error
+}
+
+// DeleteExpired implements idempotency.Store.
+func (s *Store) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := s.db.WithContext(ctx).Where("expires_at < ?", now).Delete(&Record{})
+	return result.RowsAffected, result.Error
+}
+
+// owned selects rec's row while it is in flight under rec's lease. A retry
+// that took the key over has set another lease, which fences this one out.
+func (s *Store) owned(ctx context.Context, rec idempotency.Record) *gorm.DB {
+	return s.db.WithContext(ctx).Model(&Record{}).
+		Where("scope = ? AND idempotency_key = ? AND route = ?", rec.Scope, rec.Key.Key, rec.Route).
+		Where("response_status IS NULL AND locked_until = ?", lease(rec))
+}
+
+// lease is rec's lease at the microsecond precision of timestamptz, so that
+// it compares equal once stored.
+func lease(rec idempotency.Record) time.Time {
+	return rec.LockedUntil.Truncate(time.Microsecond)
+}
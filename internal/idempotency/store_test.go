@@ -0,0 +1,136 @@
+package idempotency
+
+import (
+	"context"
+	"english-learning/internal/database/dbtest"
+	"english-learning/pkg/idempotency"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	db := dbtest.Open(t)
+	dbtest.Truncate(t, db, "idempotency_keys")
+	return NewStore(db)
+}
+
+func record(key, fingerprint string, lockedUntil time.Time) idempotency.Record {
+	return idempotency.Record{
+		Key:         idempotency.Key{Scope: "1", Key: key, Route: "POST /users"},
+		Fingerprint: fingerprint,
+		LockedUntil: lockedUntil,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+}
+
+func TestStore_CompleteAndReplay(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	rec := record("k1", "fp", time.Now().Add(time.Minute))
+
+	_, acquired, err := store.Acquire(ctx, rec)
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	existing, acquired, err := store.Acquire(ctx, rec)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+	assert.Nil(t, existing.Response, "still in flight")
+
+	res := idempotency.Response{
+		Status: http.StatusCreated,
+		Header: http.Header{"Location": {"/users/7"}},
+		Body:   []byte(`{"id":7}`),
+	}
+	require.NoError(t, store.Complete(ctx, rec, res))
+
+	existing, acquired, err = store.Acquire(ctx, rec)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+	require.NotNil(t, existing.Response)
+	assert.Equal(t, res, *existing.Response)
+	assert.Equal(t, "fp", existing.Fingerprint)
+}
+
+func TestStore_ConcurrentAcquire(t *testing.T) {
+	store := newTestStore(t)
+	rec := record("k1", "fp", time.Now().Add(time.Minute))
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	winners := 0
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, acquired, err := store.Acquire(context.Background(), rec)
+			assert.NoError(t, err)
+			if acquired {
+				mu.Lock()
+				winners++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, winners)
+}
+
+func TestStore_TakeOverAndFencing(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	stale := record("k1", "fp", time.Now().Add(-time.Second))
+
+	_, acquired, err := store.Acquire(ctx, stale)
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	_, acquired, err = store.Acquire(ctx, record("k1", "other", time.Now().Add(time.Minute)))
+	require.NoError(t, err)
+	assert.False(t, acquired, "only a retry of the same request takes over")
+
+	retry := record("k1", "fp", time.Now().Add(time.Minute))
+	_, acquired, err = store.Acquire(ctx, retry)
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	// The abandoned request finishing late must not overwrite the retry.
+	require.NoError(t, store.Release(ctx, stale))
+	require.NoError(t, store.Complete(ctx, stale, idempotency.Response{Status: http.StatusTeapot}))
+	existing, _, err := store.Acquire(ctx, retry)
+	require.NoError(t, err)
+	assert.Nil(t, existing.Response)
+
+	require.NoError(t, store.Release(ctx, retry))
+	_, acquired, err = store.Acquire(ctx, retry)
+	require.NoError(t, err)
+	assert.True(t, acquired, "released keys can be used again")
+}
+
+func TestStore_DeleteExpired(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	expired := record("old", "fp", time.Now())
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	live := record("new", "fp", time.Now())
+
+	for _, rec := range []idempotency.Record{expired, live} {
+		_, acquired, err := store.Acquire(ctx, rec)
+		require.NoError(t, err)
+		require.True(t, acquired)
+	}
+
+	deleted, err := store.DeleteExpired(ctx, time.Now())
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, deleted)
+
+	_, acquired, err := store.Acquire(ctx, live)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+}
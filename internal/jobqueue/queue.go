@@ -0,0 +1,62 @@
+// Package jobqueue runs background jobs stored in the Postgres jobs table.
+// Queue implements jobs.Enqueuer; Worker implements jobs.Registry, claims due
+// jobs with FOR UPDATE SKIP LOCKED and runs recurring schedules.
+package jobqueue
+
+import (
+	"context"
+	"encoding/json"
+	"english-learning/configs"
+	"english-learning/internal/database"
+	"english-learning/internal/modules/job/domain"
+	jobPostgres "english-learning/internal/modules/job/repository/postgres"
+	"english-learning/pkg/jobs"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Queue implements jobs.Enqueuer by inserting into jobs through
+// database.Conn, so jobs join the caller's unit of work.
+type Queue struct {
+	db          *gorm.DB
+	maxAttempts int
+}
+
+// NewQueue creates a Queue for db that gives jobs cfg.MaxAttempts attempts
+// unless told otherwise.
+func NewQueue(db *gorm.DB, cfg configs.JobsConfig) *Queue {
+	return &Queue{db: db, maxAttempts: cfg.MaxAttempts}
+}
+
+// Enqueue implements jobs.Enqueuer.
+func (q *Queue) Enqueue(ctx context.Context, job jobs.Job, opts ...jobs.Option) error {
+	o := jobs.Options{MaxAttempts: q.maxAttempts}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.RunAt.IsZero() {
+		o.RunAt = time.Now()
+	}
+	if o.MaxAttempts < 1 {
+		o.MaxAttempts = 1
+	}
+
+	payload, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("encoding %s: %w", job.JobKind(), err)
+	}
+
+	row := &jobPostgres.Job{
+		Kind:        job.JobKind(),
+		Payload:     payload,
+		Status:      domain.StatusPending,
+		MaxAttempts: o.MaxAttempts,
+		RunAt:       o.RunAt,
+	}
+	if err := database.Conn(ctx, q.db).Create(row).Error; err != nil {
+		return fmt.Errorf("enqueuing %s: %w", job.JobKind(), err)
+	}
+	return nil
+}
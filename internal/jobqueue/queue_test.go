@@ -0,0 +1,80 @@
+package jobqueue
+
+import (
+	"context"
+	"english-learning/configs"
+	"english-learning/internal/database"
+	"english-learning/internal/database/dbtest"
+	"english-learning/internal/modules/job/domain"
+	jobPostgres "english-learning/internal/modules/job/repository/postgres"
+	"english-learning/pkg/jobs"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := dbtest.Open(t)
+	require.NoError(t, db.Exec("DELETE FROM jobs WHERE kind LIKE 'test.%'").Error)
+	return db
+}
+
+func TestQueue_ClaimRetryAndDeadLetter(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	w := NewWorker(db, configs.JobsConfig{
+		Workers:     1,
+		LockTimeout: time.Minute,
+		MaxAttempts: 2,
+		RetryBase:   time.Millisecond,
+		RetryMax:    time.Millisecond,
+	})
+	w.Register("test.greet", func(ctx context.Context, _ jobs.Envelope) error {
+		return errors.New("always fails")
+	})
+
+	require.NoError(t, w.queue.Enqueue(ctx, greet{Name: "Lan"}))
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		time.Sleep(5 * time.Millisecond) // let the backoff elapse
+		claimed, err := w.claim(ctx, 10)
+		require.NoError(t, err)
+		require.Len(t, claimed, 1, "attempt %d", attempt)
+		assert.Equal(t, attempt, claimed[0].Attempts)
+		assert.Equal(t, domain.StatusRunning, claimed[0].Status)
+
+		// A running job is not claimed twice.
+		again, err := w.claim(ctx, 10)
+		require.NoError(t, err)
+		assert.Empty(t, again)
+
+		w.execute(ctx, &claimed[0])
+	}
+
+	var row jobPostgres.Job
+	require.NoError(t, db.Where("kind = ?", "test.greet").First(&row).Error)
+	assert.Equal(t, domain.StatusDead, row.Status)
+	assert.Equal(t, "always fails", *row.LastError)
+	assert.NotNil(t, row.FinishedAt)
+}
+
+func TestQueue_EnqueueJoinsUnitOfWork(t *testing.T) {
+	db := openTestDB(t)
+	queue := NewQueue(db, configs.JobsConfig{MaxAttempts: 3})
+	rollback := errors.New("rollback")
+
+	err := database.NewUnitOfWork(db).Do(context.Background(), func(ctx context.Context) error {
+		require.NoError(t, queue.Enqueue(ctx, greet{Name: "Lan"}))
+		return rollback
+	})
+
+	require.ErrorIs(t, err, rollback)
+	var count int64
+	require.NoError(t, db.Model(&jobPostgres.Job{}).Where("kind = ?", "test.greet").Count(&count).Error)
+	assert.Zero(t, count)
+}
@@ -0,0 +1,125 @@
+package jobqueue
+
+import (
+	"context"
+	"english-learning/internal/database"
+	"english-learning/internal/modules/job/domain"
+	jobPostgres "english-learning/internal/modules/job/repository/postgres"
+	"english-learning/pkg/cron"
+	"english-learning/pkg/jobs"
+	"english-learning/pkg/logger"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type schedule struct {
+	name     string
+	schedule cron.Schedule
+	job      jobs.Job
+}
+
+// runSchedules checks every schedule each cfg.SchedulerInterval.
+func (w *Worker) runSchedules(ctx context.Context) {
+	w.mu.RLock()
+	schedules := append([]schedule(nil), w.schedules...)
+	w.mu.RUnlock()
+	if len(schedules) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(w.cfg.SchedulerInterval)
+	defer ticker.Stop()
+
+	for {
+		for _, s := range schedules {
+			if err := w.fire(ctx, s, time.Now()); err != nil && ctx.Err() == nil {
+				logger.Errorf("jobs", "Schedule %s: %v", s.name, err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// fire enqueues s.job if the schedule is due. The schedule's state lives in
+// job_schedules; a transaction-scoped advisory lock on the schedule name makes
+// sure only one instance evaluates it at a time, so each occurrence is
+// enqueued once. Occurrences missed while no instance was running are not
+// caught up: the job runs once and the next occurrence is computed from now.
+func (w *Worker) fire(ctx context.Context, s schedule, now time.Time) error {
+	unitOfWork := database.NewUnitOfWork(w.db)
+	return unitOfWork.Do(ctx, func(ctx context.Context) error {
+		tx := database.Conn(ctx, w.db)
+
+		var locked bool
+		if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", lockKey(s.name)).Scan(&locked).Error; err != nil {
+			return fmt.Errorf("acquiring lock: %w", err)
+		}
+		if !locked {
+			return nil // another instance is on it
+		}
+
+		spec := s.schedule.String()
+		var state jobPostgres.Schedule
+		err := tx.First(&state, "name = ?", s.name).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tx.Create(&jobPostgres.Schedule{Name: s.name, Spec: spec, NextRunAt: s.schedule.Next(now)}).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		if state.Spec != spec {
+			// The schedule changed in code: start over from the new spec.
+			return tx.Model(&state).Updates(map[string]interface{}{
+				"spec":        spec,
+				"next_run_at": s.schedule.Next(now),
+			}).Error
+		}
+		if state.NextRunAt.IsZero() || state.NextRunAt.After(now) {
+			return nil
+		}
+
+		if err := w.queue.Enqueue(ctx, s.job); err != nil {
+			return err
+		}
+		return tx.Model(&state).Updates(map[string]interface{}{
+			"next_run_at": s.schedule.Next(now),
+			"last_run_at": now,
+		}).Error
+	})
+}
+
+// lockKey maps a schedule name to an advisory lock key. The prefix keeps the
+// keys apart from other advisory locks, such as the migration lock.
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("jobs.schedule:" + name))
+	return int64(h.Sum64())
+}
+
+// PurgeFinished deletes succeeded jobs older than the configured retention.
+type PurgeFinished struct{}
+
+func (PurgeFinished) JobKind() string { return "jobs.purge_finished" }
+
+var purgeFinishedSchedule = cron.MustParse("30 3 * * *")
+
+func (w *Worker) purgeFinished(ctx context.Context, _ PurgeFinished) error {
+	result := w.db.WithContext(ctx).
+		Where("status = ? AND finished_at < ?", domain.StatusSucceeded, time.Now().Add(-w.cfg.Retention)).
+		Delete(&jobPostgres.Job{})
+	if result.Error != nil {
+		return result.Error
+	}
+	logger.Infof("jobs", "Purged %d finished job(s)", result.RowsAffected)
+	return nil
+}
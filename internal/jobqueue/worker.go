@@ -0,0 +1,273 @@
+package jobqueue
+
+import (
+	"context"
+	"english-learning/configs"
+	"english-learning/internal/modules/job/domain"
+	jobPostgres "english-learning/internal/modules/job/repository/postgres"
+	"english-learning/pkg/cron"
+	"english-learning/pkg/jobs"
+	"english-learning/pkg/logger"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// errLockExpired is recorded on jobs whose worker vanished on their last
+// attempt.
+var errLockExpired = errors.New("lock expired before the job finished")
+
+// Worker runs the jobs registered by modules. Each instance runs up to
+// cfg.Workers jobs at a time; jobs are claimed with FOR UPDATE SKIP LOCKED, so
+// any number of instances can share the queue. A claimed job holds a lease of
+// cfg.LockTimeout; if it is still running after that, its worker is presumed
+// dead and the job is claimed again.
+type Worker struct {
+	db    *gorm.DB
+	cfg   configs.JobsConfig
+	queue *Queue
+
+	mu        sync.RWMutex
+	handlers  map[string]jobs.Handler
+	schedules []schedule
+
+	cancel context.CancelFunc
+	// abort cancels the contexts of running jobs once Stop gives up waiting.
+	abort   context.CancelFunc
+	done    chan struct{}
+	running sync.WaitGroup
+}
+
+// NewWorker creates a Worker for db. Register handlers and schedules before
+// calling Start. The worker deletes its own succeeded jobs after
+// cfg.Retention.
+func NewWorker(db *gorm.DB, cfg configs.JobsConfig) *Worker {
+	w := &Worker{
+		db:       db,
+		cfg:      cfg,
+		queue:    NewQueue(db, cfg),
+		handlers: make(map[string]jobs.Handler),
+	}
+	jobs.Handle(w, w.purgeFinished)
+	w.Schedule("jobs.purge_finished", purgeFinishedSchedule, PurgeFinished{})
+	return w
+}
+
+// Register implements jobs.Registry. Registering a kind twice replaces the
+// earlier handler.
+func (w *Worker) Register(kind string, h jobs.Handler) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handlers[kind] = h
+}
+
+// Schedule implements jobs.Registry.
+func (w *Worker) Schedule(name string, s cron.Schedule, job jobs.Job) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.schedules = append(w.schedules, schedule{name: name, schedule: s, job: job})
+}
+
+// Start launches the polling and scheduling loops. It has the signature of a
+// lifecycle OnStart hook.
+func (w *Worker) Start(context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	jobCtx, abort := context.WithCancel(context.Background())
+	w.cancel, w.abort = cancel, abort
+	w.done = make(chan struct{})
+
+	var loops sync.WaitGroup
+	loops.Add(2)
+	go func() {
+		defer loops.Done()
+		w.poll(ctx, jobCtx)
+	}()
+	go func() {
+		defer loops.Done()
+		w.runSchedules(ctx)
+	}()
+	go func() {
+		loops.Wait()
+		w.running.Wait()
+		close(w.done)
+	}()
+	return nil
+}
+
+// Stop stops claiming jobs and waits for running ones to finish. If ctx
+// expires first, their contexts are cancelled; the jobs are claimed again
+// once their lease runs out.
+func (w *Worker) Stop(ctx context.Context) error {
+	if w.cancel == nil {
+		return nil
+	}
+	w.cancel()
+	select {
+	case <-w.done:
+		w.abort()
+		return nil
+	case <-ctx.Done():
+		w.abort()
+		return fmt.Errorf("waiting for running jobs: %w", ctx.Err())
+	}
+}
+
+func (w *Worker) poll(ctx, jobCtx context.Context) {
+	slots := make(chan struct{}, w.cfg.Workers)
+
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for ctx.Err() == nil {
+		free := cap(slots) - len(slots)
+		if free > 0 {
+			claimed, err := w.claim(ctx, free)
+			if err != nil {
+				logger.Errorf("jobs", "Claiming jobs: %v", err)
+			}
+			for _, row := range claimed {
+				slots <- struct{}{}
+				w.running.Add(1)
+				go func(row jobPostgres.Job) {
+					defer func() {
+						<-slots
+						w.running.Done()
+					}()
+					w.execute(jobCtx, &row)
+				}(row)
+			}
+			if err == nil && len(claimed) == free {
+				continue // more jobs are probably due
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+		}
+	}
+}
+
+// claim dead-letters jobs whose last attempt was abandoned, then marks up to
+// limit due jobs as running under a fresh lease and returns them.
+func (w *Worker) claim(ctx context.Context, limit int) ([]jobPostgres.Job, error) {
+	now := time.Now()
+	db := w.db.WithContext(ctx)
+
+	err := db.Model(&jobPostgres.Job{}).
+		Where("status = ? AND locked_until < ? AND attempts >= max_attempts", domain.StatusRunning, now).
+		Updates(map[string]interface{}{
+			"status":       domain.StatusDead,
+			"locked_until": nil,
+			"last_error":   errLockExpired.Error(),
+			"finished_at":  now,
+		}).Error
+	if err != nil {
+		return nil, fmt.Errorf("dead-lettering abandoned jobs: %w", err)
+	}
+
+	var rows []jobPostgres.Job
+	err = db.Raw(`
+		UPDATE jobs SET status = ?, attempts = attempts + 1, locked_until = ?, updated_at = ?
+		WHERE id IN (
+			SELECT id FROM jobs
+			WHERE (status = ? AND run_at <= ?) OR (status = ? AND locked_until < ?)
+			ORDER BY run_at, id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		domain.StatusRunning, now.Add(w.cfg.LockTimeout), now,
+		domain.StatusPending, now, domain.StatusRunning, now,
+		limit,
+	).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// execute runs a claimed job and records the outcome. The job's attempt
+// counter fences the update, so a worker whose lease expired cannot overwrite
+// the result of the worker that reclaimed the job.
+func (w *Worker) execute(ctx context.Context, row *jobPostgres.Job) {
+	ctx, cancel := context.WithTimeout(ctx, w.cfg.LockTimeout)
+	defer cancel()
+
+	runErr := w.run(ctx, row)
+	if runErr != nil {
+		logger.Warnf("jobs", "Job %d (%s) attempt %d/%d failed: %v", row.ID, row.Kind, row.Attempts, row.MaxAttempts, runErr)
+	}
+
+	// The outcome is recorded even if Stop aborted the job.
+	result := w.db.WithContext(context.WithoutCancel(ctx)).Model(&jobPostgres.Job{}).
+		Where("id = ? AND status = ? AND attempts = ?", row.ID, domain.StatusRunning, row.Attempts).
+		Updates(w.outcome(row, runErr, time.Now()))
+	if result.Error != nil {
+		logger.Errorf("jobs", "Recording outcome of job %d: %v", row.ID, result.Error)
+	}
+}
+
+// run calls the handler registered for the job's kind.
+func (w *Worker) run(ctx context.Context, row *jobPostgres.Job) (err error) {
+	w.mu.RLock()
+	h, ok := w.handlers[row.Kind]
+	w.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("no handler registered for %q", row.Kind)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+	return h(ctx, jobs.Envelope{
+		ID:      row.ID,
+		Kind:    row.Kind,
+		Payload: row.Payload,
+		Attempt: row.Attempts,
+	})
+}
+
+// outcome returns the column updates that record a finished attempt. Attempts
+// were already counted when the job was claimed.
+func (w *Worker) outcome(row *jobPostgres.Job, runErr error, now time.Time) map[string]interface{} {
+	if runErr == nil {
+		return map[string]interface{}{
+			"status":       domain.StatusSucceeded,
+			"locked_until": nil,
+			"last_error":   nil,
+			"finished_at":  now,
+		}
+	}
+
+	updates := map[string]interface{}{
+		"locked_until": nil,
+		"last_error":   runErr.Error(),
+	}
+	if row.Attempts >= row.MaxAttempts {
+		updates["status"] = domain.StatusDead
+		updates["finished_at"] = now
+	} else {
+		updates["status"] = domain.StatusPending
+		updates["run_at"] = now.Add(w.backoff(row.Attempts))
+	}
+	return updates
+}
+
+// backoff returns RetryBase doubled for every failed attempt after the first,
+// capped at RetryMax.
+func (w *Worker) backoff(attempts int) time.Duration {
+	delay := w.cfg.RetryBase
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= w.cfg.RetryMax {
+			return w.cfg.RetryMax
+		}
+	}
+	return min(delay, w.cfg.RetryMax)
+}
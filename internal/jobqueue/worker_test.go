@@ -0,0 +1,163 @@
+package jobqueue
+
+import (
+	"context"
+	"english-learning/configs"
+	"english-learning/internal/modules/job/domain"
+	jobPostgres "english-learning/internal/modules/job/repository/postgres"
+	"english-learning/pkg/jobs"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestWorker() *Worker {
+	return NewWorker(nil, configs.JobsConfig{
+		Workers:     2,
+		LockTimeout: time.Minute,
+		MaxAttempts: 3,
+		RetryBase:   time.Second,
+		RetryMax:    10 * time.Second,
+		Retention:   24 * time.Hour,
+	})
+}
+
+type greet struct {
+	Name string `json:"name"`
+}
+
+func (greet) JobKind() string { return "test.greet" }
+
+func TestBackoff(t *testing.T) {
+	t.Parallel()
+	w := newTestWorker()
+
+	assert.Equal(t, time.Second, w.backoff(1))
+	assert.Equal(t, 2*time.Second, w.backoff(2))
+	assert.Equal(t, 8*time.Second, w.backoff(4))
+	assert.Equal(t, 10*time.Second, w.backoff(5))
+	assert.Equal(t, 10*time.Second, w.backoff(100))
+}
+
+func TestOutcome_Success(t *testing.T) {
+	t.Parallel()
+	w := newTestWorker()
+	now := time.Now()
+
+	updates := w.outcome(&jobPostgres.Job{Attempts: 1, MaxAttempts: 3}, nil, now)
+
+	assert.Equal(t, map[string]interface{}{
+		"status":       domain.StatusSucceeded,
+		"locked_until": nil,
+		"last_error":   nil,
+		"finished_at":  now,
+	}, updates)
+}
+
+func TestOutcome_RetryWithBackoff(t *testing.T) {
+	t.Parallel()
+	w := newTestWorker()
+	now := time.Now()
+
+	updates := w.outcome(&jobPostgres.Job{Attempts: 2, MaxAttempts: 3}, errors.New("smtp down"), now)
+
+	assert.Equal(t, map[string]interface{}{
+		"status":       domain.StatusPending,
+		"locked_until": nil,
+		"last_error":   "smtp down",
+		"run_at":       now.Add(2 * time.Second),
+	}, updates)
+}
+
+func TestOutcome_DeadAfterMaxAttempts(t *testing.T) {
+	t.Parallel()
+	w := newTestWorker()
+	now := time.Now()
+
+	updates := w.outcome(&jobPostgres.Job{Attempts: 3, MaxAttempts: 3}, errors.New("smtp down"), now)
+
+	assert.Equal(t, map[string]interface{}{
+		"status":       domain.StatusDead,
+		"locked_until": nil,
+		"last_error":   "smtp down",
+		"finished_at":  now,
+	}, updates)
+}
+
+func TestRun_TypedHandler(t *testing.T) {
+	t.Parallel()
+	w := newTestWorker()
+
+	var got greet
+	jobs.Handle(w, func(ctx context.Context, job greet) error {
+		got = job
+		return nil
+	})
+
+	err := w.run(context.Background(), &jobPostgres.Job{ID: 1, Kind: "test.greet", Payload: []byte(`{"name":"Lan"}`), Attempts: 2})
+
+	require.NoError(t, err)
+	assert.Equal(t, greet{Name: "Lan"}, got)
+}
+
+func TestRun_PassesAttempt(t *testing.T) {
+	t.Parallel()
+	w := newTestWorker()
+
+	var attempt int
+	w.Register("test.greet", func(ctx context.Context, e jobs.Envelope) error {
+		attempt = e.Attempt
+		return nil
+	})
+
+	require.NoError(t, w.run(context.Background(), &jobPostgres.Job{Kind: "test.greet", Attempts: 2}))
+	assert.Equal(t, 2, attempt)
+}
+
+func TestRun_UnknownKind(t *testing.T) {
+	t.Parallel()
+	w := newTestWorker()
+
+	err := w.run(context.Background(), &jobPostgres.Job{Kind: "test.unknown"})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no handler registered")
+}
+
+func TestRun_RecoversPanics(t *testing.T) {
+	t.Parallel()
+	w := newTestWorker()
+	w.Register("test.greet", func(ctx context.Context, e jobs.Envelope) error {
+		panic("boom")
+	})
+
+	err := w.run(context.Background(), &jobPostgres.Job{Kind: "test.greet"})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "boom")
+}
+
+func TestNewWorker_SchedulesMaintenance(t *testing.T) {
+	t.Parallel()
+	w := newTestWorker()
+
+	require.Len(t, w.schedules, 1)
+	assert.Equal(t, "jobs.purge_finished", w.schedules[0].name)
+	assert.Contains(t, w.handlers, PurgeFinished{}.JobKind())
+}
+
+func TestLockKey(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, lockKey("session.purge_expired"), lockKey("session.purge_expired"))
+	assert.NotEqual(t, lockKey("session.purge_expired"), lockKey("jobs.purge_finished"))
+}
+
+func TestStop_BeforeStart(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, newTestWorker().Stop(context.Background()))
+}
@@ -0,0 +1,11 @@
+package domain
+
+import "errors"
+
+var (
+	ErrEmailAlreadyRegistered = errors.New("email already registered")
+	ErrInvalidCredentials     = errors.New("invalid credentials")
+	ErrInvalidRefreshToken    = errors.New("invalid refresh token")
+	ErrInvalidSession         = errors.New("invalid session")
+	ErrSessionRevoked         = errors.New("session revoked")
+)
@@ -0,0 +1,26 @@
+package domain
+
+// Event names published by the auth service. They are persisted in the
+// outbox, so never rename one.
+const (
+	EventUserRegistered = "auth.user_registered"
+	EventUserLoggedIn   = "auth.user_logged_in"
+)
+
+// UserRegistered is published when a new account signs up.
+type UserRegistered struct {
+	UserID uint   `json:"userId"`
+	Email  string `json:"email"`
+}
+
+func (UserRegistered) EventName() string { return EventUserRegistered }
+
+// UserLoggedIn is published when a login starts a new session.
+type UserLoggedIn struct {
+	UserID    uint   `json:"userId"`
+	SessionID uint   `json:"sessionId"`
+	ClientIP  string `json:"clientIp"`
+	UserAgent string `json:"userAgent"`
+}
+
+func (UserLoggedIn) EventName() string { return EventUserLoggedIn }
@@ -0,0 +1,9 @@
+package domain
+
+// Metrics records authentication outcomes for monitoring.
+type Metrics interface {
+	LoginSucceeded()
+	LoginFailed()
+	Registered()
+	TokenRefreshed()
+}
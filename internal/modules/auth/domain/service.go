@@ -1,10 +1,12 @@
 package domain
 
+import "context"
+
 // AuthService defines the business logic contract for authentication operations.
 type AuthService interface {
-	Register(req *RegisterRequest) error
-	Login(req *LoginRequest, ip, userAgent string) (*TokenPair, error)
-	RefreshToken(refreshToken string) (*TokenPair, error)
-	Logout(refreshToken string) error
-	LogoutAll(userID uint) error
+	Register(ctx context.Context, req *RegisterRequest) error
+	Login(ctx context.Context, req *LoginRequest, ip, userAgent string) (*TokenPair, error)
+	RefreshToken(ctx context.Context, refreshToken string) (*TokenPair, error)
+	Logout(ctx context.Context, refreshToken string) error
+	LogoutAll(ctx context.Context, userID uint) error
 }
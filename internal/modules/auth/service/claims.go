@@ -5,6 +5,14 @@ import "github.com/golang-jwt/jwt/v5"
 // authClaims is used for JWT token generation and parsing.
 // This is an implementation detail of the auth service, not a domain entity.
 type authClaims struct {
-	Email  string `json:"email"`
+	Email string `json:"email"`
+	// Locale carries the user's preferred language so responses can be
+	// localized without a profile lookup on every request.
+	Locale string `json:"locale,omitempty"`
+	// Role is checked by middleware.RequireRole on admin-only routes.
+	Role string `json:"role,omitempty"`
+	// Type is token.TypeAccess or token.TypeRefresh, so that neither kind of
+	// token is accepted in place of the other.
+	Type string `json:"typ"`
 	jwt.RegisteredClaims
 }
@@ -0,0 +1,9 @@
+package service
+
+// nopMetrics is used when no authDomain.Metrics is supplied.
+type nopMetrics struct{}
+
+func (nopMetrics) LoginSucceeded() {}
+func (nopMetrics) LoginFailed()    {}
+func (nopMetrics) Registered()     {}
+func (nopMetrics) TokenRefreshed() {}
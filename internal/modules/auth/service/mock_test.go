@@ -1,8 +1,13 @@
 package service
 
 import (
+	"context"
 	sessionDomain "english-learning/internal/modules/session/domain"
 	userDomain "english-learning/internal/modules/user/domain"
+	"english-learning/pkg/events"
+	"english-learning/pkg/query"
+	"sync"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -12,79 +17,130 @@ type MockUserRepository struct {
 	mock.Mock
 }
 
-func (m *MockUserRepository) Create(user *userDomain.User) error {
-	args := m.Called(user)
+func (m *MockUserRepository) Create(ctx context.Context, user *userDomain.User) error {
+	args := m.Called(ctx, user)
 	return args.Error(0)
 }
 
-func (m *MockUserRepository) FindByEmail(email string) (*userDomain.User, error) {
-	args := m.Called(email)
+func (m *MockUserRepository) FindByEmail(ctx context.Context, email string) (*userDomain.User, error) {
+	args := m.Called(ctx, email)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*userDomain.User), args.Error(1)
 }
 
-func (m *MockUserRepository) FindByID(id uint) (*userDomain.User, error) {
-	args := m.Called(id)
+func (m *MockUserRepository) FindByID(ctx context.Context, id uint) (*userDomain.User, error) {
+	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*userDomain.User), args.Error(1)
 }
 
-func (m *MockUserRepository) Update(user *userDomain.User) error {
-	args := m.Called(user)
+func (m *MockUserRepository) Update(ctx context.Context, user *userDomain.User) error {
+	args := m.Called(ctx, user)
 	return args.Error(0)
 }
 
-func (m *MockUserRepository) Delete(id uint) error {
-	args := m.Called(id)
+func (m *MockUserRepository) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
-func (m *MockUserRepository) List(offset, limit int) ([]userDomain.User, int64, error) {
-	args := m.Called(offset, limit)
+func (m *MockUserRepository) List(ctx context.Context, q query.Query) (query.Page[userDomain.User], error) {
+	args := m.Called(ctx, q)
+	return args.Get(0).(query.Page[userDomain.User]), args.Error(1)
+}
+
+func (m *MockUserRepository) ListDeleted(ctx context.Context, offset, limit int) ([]userDomain.User, int64, error) {
+	args := m.Called(ctx, offset, limit)
 	return args.Get(0).([]userDomain.User), args.Get(1).(int64), args.Error(2)
 }
 
+func (m *MockUserRepository) Restore(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) Purge(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
 // MockSessionRepository is a mock implementation of sessionDomain.SessionRepository.
 type MockSessionRepository struct {
 	mock.Mock
 }
 
-func (m *MockSessionRepository) Create(session *sessionDomain.Session) error {
-	args := m.Called(session)
+func (m *MockSessionRepository) Create(ctx context.Context, session *sessionDomain.Session) error {
+	args := m.Called(ctx, session)
 	return args.Error(0)
 }
 
-func (m *MockSessionRepository) FindByID(id uint) (*sessionDomain.Session, error) {
-	args := m.Called(id)
+func (m *MockSessionRepository) FindByID(ctx context.Context, id uint) (*sessionDomain.Session, error) {
+	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*sessionDomain.Session), args.Error(1)
 }
 
-func (m *MockSessionRepository) FindByRefreshToken(refreshToken string) (*sessionDomain.Session, error) {
-	args := m.Called(refreshToken)
+func (m *MockSessionRepository) FindByRefreshToken(ctx context.Context, refreshToken string) (*sessionDomain.Session, error) {
+	args := m.Called(ctx, refreshToken)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*sessionDomain.Session), args.Error(1)
 }
 
-func (m *MockSessionRepository) Revoke(id uint) error {
-	args := m.Called(id)
+func (m *MockSessionRepository) Revoke(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
-func (m *MockSessionRepository) RevokeAllForUser(userID uint) error {
-	args := m.Called(userID)
+func (m *MockSessionRepository) RevokeAllForUser(ctx context.Context, userID uint) error {
+	args := m.Called(ctx, userID)
 	return args.Error(0)
 }
 
-func (m *MockSessionRepository) Delete(id uint) error {
-	args := m.Called(id)
+func (m *MockSessionRepository) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
 	return args.Error(0)
 }
+
+func (m *MockSessionRepository) ListByUser(ctx context.Context, userID uint) ([]sessionDomain.Session, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]sessionDomain.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// fakeUnitOfWork runs the function directly, without a transaction.
+type fakeUnitOfWork struct{}
+
+func (fakeUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// fakePublisher records published events.
+type fakePublisher struct {
+	mu     sync.Mutex
+	events []events.Event
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, evts ...events.Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, evts...)
+	return nil
+}
+
+func (p *fakePublisher) Published() []events.Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]events.Event(nil), p.events...)
+}
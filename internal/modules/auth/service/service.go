@@ -1,45 +1,89 @@
 package service
 
 import (
+	"context"
 	authDomain "english-learning/internal/modules/auth/domain"
 	sessionDomain "english-learning/internal/modules/session/domain"
 	userDomain "english-learning/internal/modules/user/domain"
+	"english-learning/pkg/events"
+	"english-learning/pkg/token"
+	"english-learning/pkg/uow"
 	"errors"
 	"fmt"
 	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"go.opentelemetry.io/otel"
 	"golang.org/x/crypto/bcrypt"
 )
 
+var tracer = otel.Tracer("english-learning/internal/modules/auth/service")
+
+// Default token lifetimes, used when TokenConfig leaves them zero.
+const (
+	defaultAccessTTL  = 15 * time.Minute
+	defaultRefreshTTL = 7 * 24 * time.Hour
+)
+
+// TokenConfig controls how tokens are signed and how long they live. The
+// refresh TTL is also the lifetime of the session backing the token.
+type TokenConfig struct {
+	Secret     string
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+}
+
 // Service implements authDomain.AuthService.
 type Service struct {
 	userRepo    userDomain.UserRepository
 	sessionRepo sessionDomain.SessionRepository
-	jwtSecret   string
+	uow         uow.UnitOfWork
+	events      events.Publisher
+	tokens      TokenConfig
+	metrics     authDomain.Metrics
 }
 
-// NewService creates a new auth Service.
-func NewService(userRepo userDomain.UserRepository, sessionRepo sessionDomain.SessionRepository, jwtSecret string) *Service {
+// NewService creates a new auth Service. metrics may be nil.
+func NewService(userRepo userDomain.UserRepository, sessionRepo sessionDomain.SessionRepository, unitOfWork uow.UnitOfWork, publisher events.Publisher, tokens TokenConfig, metrics authDomain.Metrics) *Service {
+	if metrics == nil {
+		metrics = nopMetrics{}
+	}
+	if tokens.AccessTTL <= 0 {
+		tokens.AccessTTL = defaultAccessTTL
+	}
+	if tokens.RefreshTTL <= 0 {
+		tokens.RefreshTTL = defaultRefreshTTL
+	}
 	return &Service{
 		userRepo:    userRepo,
 		sessionRepo: sessionRepo,
-		jwtSecret:   jwtSecret,
+		uow:         unitOfWork,
+		events:      publisher,
+		tokens:      tokens,
+		metrics:     metrics,
 	}
 }
 
-func (s *Service) Register(req *authDomain.RegisterRequest) error {
-	existingUser, err := s.userRepo.FindByEmail(req.Email)
+// Register creates a new account. FindByEmail only sees active users, so an
+// email that belongs to a soft-deleted account is treated as available: the
+// deleted record is left untouched for admins to restore or purge, and a fresh
+// account is created alongside it. The email check is only a fast path that
+// avoids hashing; a concurrent registration that wins the race is caught by the
+// unique index and reported the same way.
+func (s *Service) Register(ctx context.Context, req *authDomain.RegisterRequest) error {
+	existingUser, err := s.userRepo.FindByEmail(ctx, req.Email)
 	if err == nil && existingUser != nil {
-		return errors.New("email already registered")
+		return authDomain.ErrEmailAlreadyRegistered
 	}
 
 	if err != nil && !errors.Is(err, userDomain.ErrUserNotFound) {
 		return fmt.Errorf("checking existing user: %w", err)
 	}
 
+	_, span := tracer.Start(ctx, "bcrypt.GenerateFromPassword")
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	span.End()
 	if err != nil {
 		return fmt.Errorf("hashing password: %w", err)
 	}
@@ -49,21 +93,39 @@ func (s *Service) Register(req *authDomain.RegisterRequest) error {
 		Password: string(hashedPassword),
 	}
 
-	if err := s.userRepo.Create(user); err != nil {
-		return fmt.Errorf("creating user: %w", err)
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Create(ctx, user); err != nil {
+			if errors.Is(err, userDomain.ErrEmailInUse) {
+				return authDomain.ErrEmailAlreadyRegistered
+			}
+			return fmt.Errorf("creating user: %w", err)
+		}
+		return s.publish(ctx, authDomain.UserRegistered{UserID: user.ID, Email: user.Email})
+	})
+	if err != nil {
+		return err
 	}
 
+	s.metrics.Registered()
 	return nil
 }
 
-func (s *Service) Login(req *authDomain.LoginRequest, ip, userAgent string) (*authDomain.TokenPair, error) {
-	user, err := s.userRepo.FindByEmail(req.Email)
+func (s *Service) Login(ctx context.Context, req *authDomain.LoginRequest, ip, userAgent string) (*authDomain.TokenPair, error) {
+	user, err := s.userRepo.FindByEmail(ctx, req.Email)
 	if err != nil {
-		return nil, errors.New("invalid credentials")
+		s.metrics.LoginFailed()
+		if !errors.Is(err, userDomain.ErrUserNotFound) {
+			return nil, fmt.Errorf("finding user: %w", err)
+		}
+		return nil, authDomain.ErrInvalidCredentials
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		return nil, errors.New("invalid credentials")
+	_, span := tracer.Start(ctx, "bcrypt.CompareHashAndPassword")
+	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
+	span.End()
+	if err != nil {
+		s.metrics.LoginFailed()
+		return nil, authDomain.ErrInvalidCredentials
 	}
 
 	accessToken, err := s.generateAccessToken(user)
@@ -81,123 +143,176 @@ func (s *Service) Login(req *authDomain.LoginRequest, ip, userAgent string) (*au
 		RefreshToken: refreshToken,
 		UserAgent:    userAgent,
 		ClientIP:     ip,
-		ExpiresAt:    time.Now().Add(7 * 24 * time.Hour),
+		ExpiresAt:    time.Now().Add(s.tokens.RefreshTTL),
 	}
 
-	if err := s.sessionRepo.Create(session); err != nil {
-		return nil, fmt.Errorf("creating session: %w", err)
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.sessionRepo.Create(ctx, session); err != nil {
+			return fmt.Errorf("creating session: %w", err)
+		}
+		return s.publish(ctx, authDomain.UserLoggedIn{
+			UserID:    user.ID,
+			SessionID: session.ID,
+			ClientIP:  ip,
+			UserAgent: userAgent,
+		})
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	s.metrics.LoginSucceeded()
 	return &authDomain.TokenPair{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
 	}, nil
 }
 
-func (s *Service) RefreshToken(refreshToken string) (*authDomain.TokenPair, error) {
+func (s *Service) RefreshToken(ctx context.Context, refreshToken string) (*authDomain.TokenPair, error) {
 	// Verify refresh token
-	token, err := jwt.ParseWithClaims(refreshToken, &authClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(s.jwtSecret), nil
-	})
-
-	if err != nil || !token.Valid {
-		return nil, errors.New("invalid refresh token")
+	claims := &authClaims{}
+	parsed, err := jwt.ParseWithClaims(refreshToken, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(s.tokens.Secret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+
+	// Refresh tokens issued before the "typ" claim existed carry no type.
+	// They are still accepted: the session lookup below only ever matches a
+	// refresh token, so adding the claim does not log everyone out.
+	if err != nil || !parsed.Valid || (claims.Type != token.TypeRefresh && claims.Type != "") {
+		return nil, authDomain.ErrInvalidRefreshToken
 	}
 
 	// Check if session exists and is valid
-	session, err := s.sessionRepo.FindByRefreshToken(refreshToken)
+	session, err := s.sessionRepo.FindByRefreshToken(ctx, refreshToken)
+	if err != nil && !errors.Is(err, sessionDomain.ErrSessionNotFound) {
+		return nil, fmt.Errorf("finding session: %w", err)
+	}
 	if err != nil || session == nil {
-		return nil, errors.New("invalid session")
+		return nil, authDomain.ErrInvalidSession
 	}
 
 	if session.IsRevoked {
-		return nil, errors.New("session revoked")
-	}
-
-	// Revoke current session (Token Rotation)
-	if err := s.sessionRepo.Revoke(session.ID); err != nil {
-		return nil, fmt.Errorf("revoking session: %w", err)
-	}
-
-	// Check if associated user exists
-	user, err := s.userRepo.FindByID(session.UserID)
-	if err != nil {
-		return nil, errors.New("user not found")
-	}
-
-	// Generate new access token and new refresh token
-	accessToken, err := s.generateAccessToken(user)
-	if err != nil {
-		return nil, fmt.Errorf("generating access token: %w", err)
+		return nil, authDomain.ErrSessionRevoked
 	}
 
-	newRefreshToken, err := s.generateRefreshToken(user)
+	// Revoking the old session and creating its replacement happen atomically,
+	// so a failure cannot leave the user logged out, and a refresh token that
+	// is presented twice concurrently only rotates once.
+	var pair *authDomain.TokenPair
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.sessionRepo.Revoke(ctx, session.ID); err != nil {
+			if errors.Is(err, sessionDomain.ErrSessionNotFound) {
+				return authDomain.ErrSessionRevoked
+			}
+			return fmt.Errorf("revoking session: %w", err)
+		}
+
+		// A refresh token of a user that has since been deleted is simply
+		// no longer valid.
+		user, err := s.userRepo.FindByID(ctx, session.UserID)
+		if err != nil {
+			if errors.Is(err, userDomain.ErrUserNotFound) {
+				return authDomain.ErrInvalidRefreshToken
+			}
+			return fmt.Errorf("finding user: %w", err)
+		}
+
+		// Generate new access token and new refresh token
+		accessToken, err := s.generateAccessToken(user)
+		if err != nil {
+			return fmt.Errorf("generating access token: %w", err)
+		}
+
+		newRefreshToken, err := s.generateRefreshToken(user)
+		if err != nil {
+			return fmt.Errorf("generating refresh token: %w", err)
+		}
+
+		// Create new session with the new refresh token
+		newSession := &sessionDomain.Session{
+			UserID:       user.ID,
+			RefreshToken: newRefreshToken,
+			UserAgent:    session.UserAgent,
+			ClientIP:     session.ClientIP,
+			ExpiresAt:    time.Now().Add(s.tokens.RefreshTTL),
+		}
+
+		if err := s.sessionRepo.Create(ctx, newSession); err != nil {
+			return fmt.Errorf("creating new session: %w", err)
+		}
+
+		pair = &authDomain.TokenPair{
+			AccessToken:  accessToken,
+			RefreshToken: newRefreshToken,
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("generating refresh token: %w", err)
+		return nil, err
 	}
 
-	// Create new session with the new refresh token
-	newSession := &sessionDomain.Session{
-		UserID:       user.ID,
-		RefreshToken: newRefreshToken,
-		UserAgent:    session.UserAgent,
-		ClientIP:     session.ClientIP,
-		ExpiresAt:    time.Now().Add(7 * 24 * time.Hour),
-	}
+	s.metrics.TokenRefreshed()
+	return pair, nil
+}
 
-	if err := s.sessionRepo.Create(newSession); err != nil {
-		return nil, fmt.Errorf("creating new session: %w", err)
+func (s *Service) publish(ctx context.Context, evts ...events.Event) error {
+	if err := s.events.Publish(ctx, evts...); err != nil {
+		return fmt.Errorf("publishing events: %w", err)
 	}
-
-	return &authDomain.TokenPair{
-		AccessToken:  accessToken,
-		RefreshToken: newRefreshToken,
-	}, nil
+	return nil
 }
 
 func (s *Service) generateAccessToken(user *userDomain.User) (string, error) {
 	claims := authClaims{
 		Email:  user.Email,
+		Locale: user.Locale,
+		Role:   user.Role,
+		Type:   token.TypeAccess,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Subject:   strconv.FormatUint(uint64(user.ID), 10),
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(15 * time.Minute)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.tokens.AccessTTL)),
 			Issuer:    "english-learning",
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.jwtSecret))
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(s.tokens.Secret))
 }
 
 func (s *Service) generateRefreshToken(user *userDomain.User) (string, error) {
 	claims := authClaims{
-		Email:  user.Email,
+		Email: user.Email,
+		Type:  token.TypeRefresh,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Subject:   strconv.FormatUint(uint64(user.ID), 10),
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(7 * 24 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.tokens.RefreshTTL)),
 			Issuer:    "english-learning",
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.jwtSecret))
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(s.tokens.Secret))
 }
 
-func (s *Service) Logout(refreshToken string) error {
-	session, err := s.sessionRepo.FindByRefreshToken(refreshToken)
-	if err != nil || session == nil {
+func (s *Service) Logout(ctx context.Context, refreshToken string) error {
+	session, err := s.sessionRepo.FindByRefreshToken(ctx, refreshToken)
+	if errors.Is(err, sessionDomain.ErrSessionNotFound) || (err == nil && session == nil) {
 		return nil // Already logged out or invalid
 	}
+	if err != nil {
+		return fmt.Errorf("finding session: %w", err)
+	}
 
-	if err := s.sessionRepo.Revoke(session.ID); err != nil {
+	if err := s.sessionRepo.Revoke(ctx, session.ID); err != nil {
+		if errors.Is(err, sessionDomain.ErrSessionNotFound) {
+			return nil // Revoked concurrently
+		}
 		return fmt.Errorf("revoking session: %w", err)
 	}
 
 	return nil
 }
 
-func (s *Service) LogoutAll(userID uint) error {
-	if err := s.sessionRepo.RevokeAllForUser(userID); err != nil {
+func (s *Service) LogoutAll(ctx context.Context, userID uint) error {
+	if err := s.sessionRepo.RevokeAllForUser(ctx, userID); err != nil {
 		return fmt.Errorf("revoking all sessions: %w", err)
 	}
 
@@ -1,15 +1,19 @@
 package service
 
 import (
+	"context"
 	authDomain "english-learning/internal/modules/auth/domain"
 	sessionDomain "english-learning/internal/modules/session/domain"
 	userDomain "english-learning/internal/modules/user/domain"
+	"english-learning/pkg/events"
 	"errors"
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -19,7 +23,7 @@ const testJWTSecret = "test-secret-key-for-unit-tests"
 func newTestService() (*Service, *MockUserRepository, *MockSessionRepository) {
 	userRepo := new(MockUserRepository)
 	sessionRepo := new(MockSessionRepository)
-	svc := NewService(userRepo, sessionRepo, testJWTSecret)
+	svc := NewService(userRepo, sessionRepo, fakeUnitOfWork{}, &fakePublisher{}, TokenConfig{Secret: testJWTSecret}, nil)
 	return svc, userRepo, sessionRepo
 }
 
@@ -44,10 +48,10 @@ func TestRegister_Success(t *testing.T) {
 		Password: "password123",
 	}
 
-	userRepo.On("FindByEmail", req.Email).Return(nil, userDomain.ErrUserNotFound)
-	userRepo.On("Create", mock.AnythingOfType("*domain.User")).Return(nil)
+	userRepo.On("FindByEmail", mock.Anything, req.Email).Return(nil, userDomain.ErrUserNotFound)
+	userRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.User")).Return(nil)
 
-	err := svc.Register(req)
+	err := svc.Register(context.Background(), req)
 
 	assert.NoError(t, err)
 	userRepo.AssertExpectations(t)
@@ -67,13 +71,13 @@ func TestRegister_EmailAlreadyExists(t *testing.T) {
 		Password: "password123",
 	}
 
-	userRepo.On("FindByEmail", req.Email).Return(existingUser, nil)
+	userRepo.On("FindByEmail", mock.Anything, req.Email).Return(existingUser, nil)
 
-	err := svc.Register(req)
+	err := svc.Register(context.Background(), req)
 
 	assert.Error(t, err)
 	assert.Equal(t, "email already registered", err.Error())
-	userRepo.AssertNotCalled(t, "Create", mock.Anything)
+	userRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
 }
 
 func TestRegister_RepositoryError(t *testing.T) {
@@ -86,13 +90,13 @@ func TestRegister_RepositoryError(t *testing.T) {
 	}
 
 	dbErr := errors.New("database connection failed")
-	userRepo.On("FindByEmail", req.Email).Return(nil, dbErr)
+	userRepo.On("FindByEmail", mock.Anything, req.Email).Return(nil, dbErr)
 
-	err := svc.Register(req)
+	err := svc.Register(context.Background(), req)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "checking existing user")
-	userRepo.AssertNotCalled(t, "Create", mock.Anything)
+	userRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
 }
 
 func TestRegister_CreateUserError(t *testing.T) {
@@ -104,15 +108,50 @@ func TestRegister_CreateUserError(t *testing.T) {
 		Password: "password123",
 	}
 
-	userRepo.On("FindByEmail", req.Email).Return(nil, userDomain.ErrUserNotFound)
-	userRepo.On("Create", mock.AnythingOfType("*domain.User")).Return(errors.New("insert failed"))
+	userRepo.On("FindByEmail", mock.Anything, req.Email).Return(nil, userDomain.ErrUserNotFound)
+	userRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.User")).Return(errors.New("insert failed"))
 
-	err := svc.Register(req)
+	err := svc.Register(context.Background(), req)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "creating user")
 }
 
+func TestRegister_ConcurrentRegistrationLosesRace(t *testing.T) {
+	t.Parallel()
+	svc, userRepo, _ := newTestService()
+
+	req := &authDomain.RegisterRequest{
+		Email:    "test@example.com",
+		Password: "password123",
+	}
+
+	// The email was free when checked but taken before the insert.
+	userRepo.On("FindByEmail", mock.Anything, req.Email).Return(nil, userDomain.ErrUserNotFound)
+	userRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.User")).Return(userDomain.ErrEmailInUse)
+
+	err := svc.Register(context.Background(), req)
+
+	assert.ErrorIs(t, err, authDomain.ErrEmailAlreadyRegistered)
+}
+
+func TestRegister_PublishesUserRegistered(t *testing.T) {
+	t.Parallel()
+	userRepo := new(MockUserRepository)
+	publisher := &fakePublisher{}
+	svc := NewService(userRepo, new(MockSessionRepository), fakeUnitOfWork{}, publisher, TokenConfig{Secret: testJWTSecret}, nil)
+
+	userRepo.On("FindByEmail", mock.Anything, "test@example.com").Return(nil, userDomain.ErrUserNotFound)
+	userRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.User")).Run(func(args mock.Arguments) {
+		args.Get(1).(*userDomain.User).ID = 7
+	}).Return(nil)
+
+	err := svc.Register(context.Background(), &authDomain.RegisterRequest{Email: "test@example.com", Password: "password123"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []events.Event{authDomain.UserRegistered{UserID: 7, Email: "test@example.com"}}, publisher.Published())
+}
+
 // --- Login Tests ---
 
 func TestLogin_Success(t *testing.T) {
@@ -131,10 +170,10 @@ func TestLogin_Success(t *testing.T) {
 		Password: password,
 	}
 
-	userRepo.On("FindByEmail", req.Email).Return(user, nil)
-	sessionRepo.On("Create", mock.AnythingOfType("*domain.Session")).Return(nil)
+	userRepo.On("FindByEmail", mock.Anything, req.Email).Return(user, nil)
+	sessionRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Session")).Return(nil)
 
-	tokenPair, err := svc.Login(req, "127.0.0.1", "TestAgent/1.0")
+	tokenPair, err := svc.Login(context.Background(), req, "127.0.0.1", "TestAgent/1.0")
 
 	assert.NoError(t, err)
 	assert.NotNil(t, tokenPair)
@@ -153,9 +192,9 @@ func TestLogin_UserNotFound(t *testing.T) {
 		Password: "password123",
 	}
 
-	userRepo.On("FindByEmail", req.Email).Return(nil, userDomain.ErrUserNotFound)
+	userRepo.On("FindByEmail", mock.Anything, req.Email).Return(nil, userDomain.ErrUserNotFound)
 
-	tokenPair, err := svc.Login(req, "127.0.0.1", "TestAgent/1.0")
+	tokenPair, err := svc.Login(context.Background(), req, "127.0.0.1", "TestAgent/1.0")
 
 	assert.Error(t, err)
 	assert.Nil(t, tokenPair)
@@ -177,16 +216,16 @@ func TestLogin_InvalidPassword(t *testing.T) {
 		Password: "wrong-password",
 	}
 
-	userRepo.On("FindByEmail", req.Email).Return(user, nil)
+	userRepo.On("FindByEmail", mock.Anything, req.Email).Return(user, nil)
 
-	tokenPair, err := svc.Login(req, "127.0.0.1", "TestAgent/1.0")
+	tokenPair, err := svc.Login(context.Background(), req, "127.0.0.1", "TestAgent/1.0")
 
 	assert.Error(t, err)
 	assert.Nil(t, tokenPair)
 	assert.Equal(t, "invalid credentials", err.Error())
 	// Session should NOT be created
 	sessionRepo := new(MockSessionRepository)
-	sessionRepo.AssertNotCalled(t, "Create", mock.Anything)
+	sessionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
 }
 
 func TestLogin_SessionCreateError(t *testing.T) {
@@ -205,16 +244,42 @@ func TestLogin_SessionCreateError(t *testing.T) {
 		Password: password,
 	}
 
-	userRepo.On("FindByEmail", req.Email).Return(user, nil)
-	sessionRepo.On("Create", mock.AnythingOfType("*domain.Session")).Return(errors.New("session insert failed"))
+	userRepo.On("FindByEmail", mock.Anything, req.Email).Return(user, nil)
+	sessionRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Session")).Return(errors.New("session insert failed"))
 
-	tokenPair, err := svc.Login(req, "127.0.0.1", "TestAgent/1.0")
+	tokenPair, err := svc.Login(context.Background(), req, "127.0.0.1", "TestAgent/1.0")
 
 	assert.Error(t, err)
 	assert.Nil(t, tokenPair)
 	assert.Contains(t, err.Error(), "creating session")
 }
 
+func TestLogin_PublishesUserLoggedIn(t *testing.T) {
+	t.Parallel()
+	userRepo := new(MockUserRepository)
+	sessionRepo := new(MockSessionRepository)
+	publisher := &fakePublisher{}
+	svc := NewService(userRepo, sessionRepo, fakeUnitOfWork{}, publisher, TokenConfig{Secret: testJWTSecret}, nil)
+
+	password := "password123"
+	user := &userDomain.User{ID: 1, Email: "test@example.com", Password: hashPassword(t, password)}
+
+	userRepo.On("FindByEmail", mock.Anything, user.Email).Return(user, nil)
+	sessionRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Session")).Run(func(args mock.Arguments) {
+		args.Get(1).(*sessionDomain.Session).ID = 3
+	}).Return(nil)
+
+	_, err := svc.Login(context.Background(), &authDomain.LoginRequest{Email: user.Email, Password: password}, "127.0.0.1", "TestAgent/1.0")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []events.Event{authDomain.UserLoggedIn{
+		UserID:    1,
+		SessionID: 3,
+		ClientIP:  "127.0.0.1",
+		UserAgent: "TestAgent/1.0",
+	}}, publisher.Published())
+}
+
 // --- RefreshToken Tests ---
 
 func TestRefreshToken_Success(t *testing.T) {
@@ -236,12 +301,12 @@ func TestRefreshToken_Success(t *testing.T) {
 		ExpiresAt:    time.Now().Add(7 * 24 * time.Hour),
 	}
 
-	sessionRepo.On("FindByRefreshToken", validRefreshToken).Return(session, nil)
-	sessionRepo.On("Revoke", uint(1)).Return(nil)
-	userRepo.On("FindByID", uint(1)).Return(user, nil)
-	sessionRepo.On("Create", mock.AnythingOfType("*domain.Session")).Return(nil)
+	sessionRepo.On("FindByRefreshToken", mock.Anything, validRefreshToken).Return(session, nil)
+	sessionRepo.On("Revoke", mock.Anything, uint(1)).Return(nil)
+	userRepo.On("FindByID", mock.Anything, uint(1)).Return(user, nil)
+	sessionRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Session")).Return(nil)
 
-	tokenPair, err := svc.RefreshToken(validRefreshToken)
+	tokenPair, err := svc.RefreshToken(context.Background(), validRefreshToken)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, tokenPair)
@@ -255,13 +320,52 @@ func TestRefreshToken_InvalidToken(t *testing.T) {
 	t.Parallel()
 	svc, _, _ := newTestService()
 
-	tokenPair, err := svc.RefreshToken("invalid-token-string")
+	tokenPair, err := svc.RefreshToken(context.Background(), "invalid-token-string")
 
 	assert.Error(t, err)
 	assert.Nil(t, tokenPair)
 	assert.Equal(t, "invalid refresh token", err.Error())
 }
 
+func TestRefreshToken_RejectsAccessToken(t *testing.T) {
+	t.Parallel()
+	svc, _, _ := newTestService()
+
+	accessToken, err := svc.generateAccessToken(&userDomain.User{ID: 1, Email: "test@example.com"})
+	assert.NoError(t, err)
+
+	tokenPair, err := svc.RefreshToken(context.Background(), accessToken)
+
+	assert.ErrorIs(t, err, authDomain.ErrInvalidRefreshToken)
+	assert.Nil(t, tokenPair)
+}
+
+func TestRefreshToken_AcceptsUntypedRefreshToken(t *testing.T) {
+	t.Parallel()
+	svc, userRepo, sessionRepo := newTestService()
+
+	// A refresh token issued before the "typ" claim existed.
+	user := &userDomain.User{ID: 1, Email: "test@example.com"}
+	legacyToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"email": user.Email,
+		"sub":   "1",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(testJWTSecret))
+	require.NoError(t, err)
+
+	session := &sessionDomain.Session{ID: 1, UserID: 1, RefreshToken: legacyToken, ExpiresAt: time.Now().Add(time.Hour)}
+	sessionRepo.On("FindByRefreshToken", mock.Anything, legacyToken).Return(session, nil)
+	sessionRepo.On("Revoke", mock.Anything, uint(1)).Return(nil)
+	userRepo.On("FindByID", mock.Anything, uint(1)).Return(user, nil)
+	sessionRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Session")).Return(nil)
+
+	tokenPair, err := svc.RefreshToken(context.Background(), legacyToken)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, tokenPair)
+	sessionRepo.AssertExpectations(t)
+}
+
 func TestRefreshToken_SessionNotFound(t *testing.T) {
 	t.Parallel()
 	svc, _, sessionRepo := newTestService()
@@ -269,9 +373,9 @@ func TestRefreshToken_SessionNotFound(t *testing.T) {
 	user := &userDomain.User{ID: 1, Email: "test@example.com"}
 	validRefreshToken, _ := svc.generateRefreshToken(user)
 
-	sessionRepo.On("FindByRefreshToken", validRefreshToken).Return(nil, errors.New("not found"))
+	sessionRepo.On("FindByRefreshToken", mock.Anything, validRefreshToken).Return(nil, sessionDomain.ErrSessionNotFound)
 
-	tokenPair, err := svc.RefreshToken(validRefreshToken)
+	tokenPair, err := svc.RefreshToken(context.Background(), validRefreshToken)
 
 	assert.Error(t, err)
 	assert.Nil(t, tokenPair)
@@ -292,18 +396,42 @@ func TestRefreshToken_SessionRevoked(t *testing.T) {
 		IsRevoked:    true,
 	}
 
-	sessionRepo.On("FindByRefreshToken", validRefreshToken).Return(session, nil)
+	sessionRepo.On("FindByRefreshToken", mock.Anything, validRefreshToken).Return(session, nil)
 
-	tokenPair, err := svc.RefreshToken(validRefreshToken)
+	tokenPair, err := svc.RefreshToken(context.Background(), validRefreshToken)
 
 	assert.Error(t, err)
 	assert.Nil(t, tokenPair)
 	assert.Equal(t, "session revoked", err.Error())
 	// Should NOT call Revoke again
-	sessionRepo.AssertNotCalled(t, "Revoke", mock.Anything)
+	sessionRepo.AssertNotCalled(t, "Revoke", mock.Anything, mock.Anything)
+}
+
+func TestRefreshToken_DeletedUser(t *testing.T) {
+	t.Parallel()
+	svc, userRepo, sessionRepo := newTestService()
+
+	user := &userDomain.User{ID: 1, Email: "test@example.com"}
+	validRefreshToken, _ := svc.generateRefreshToken(user)
+
+	session := &sessionDomain.Session{
+		ID:           1,
+		UserID:       1,
+		RefreshToken: validRefreshToken,
+		IsRevoked:    false,
+	}
+
+	sessionRepo.On("FindByRefreshToken", mock.Anything, validRefreshToken).Return(session, nil)
+	sessionRepo.On("Revoke", mock.Anything, uint(1)).Return(nil)
+	userRepo.On("FindByID", mock.Anything, uint(1)).Return(nil, userDomain.ErrUserNotFound)
+
+	tokenPair, err := svc.RefreshToken(context.Background(), validRefreshToken)
+
+	assert.ErrorIs(t, err, authDomain.ErrInvalidRefreshToken, "a deleted user's token is just invalid")
+	assert.Nil(t, tokenPair)
 }
 
-func TestRefreshToken_UserNotFound(t *testing.T) {
+func TestRefreshToken_ConcurrentRotation(t *testing.T) {
 	t.Parallel()
 	svc, userRepo, sessionRepo := newTestService()
 
@@ -317,15 +445,43 @@ func TestRefreshToken_UserNotFound(t *testing.T) {
 		IsRevoked:    false,
 	}
 
-	sessionRepo.On("FindByRefreshToken", validRefreshToken).Return(session, nil)
-	sessionRepo.On("Revoke", uint(1)).Return(nil)
-	userRepo.On("FindByID", uint(1)).Return(nil, errors.New("user not found"))
+	// Another request revoked the session between the read and the revoke.
+	sessionRepo.On("FindByRefreshToken", mock.Anything, validRefreshToken).Return(session, nil)
+	sessionRepo.On("Revoke", mock.Anything, uint(1)).Return(sessionDomain.ErrSessionNotFound)
+
+	tokenPair, err := svc.RefreshToken(context.Background(), validRefreshToken)
+
+	assert.ErrorIs(t, err, authDomain.ErrSessionRevoked)
+	assert.Nil(t, tokenPair)
+	userRepo.AssertNotCalled(t, "FindByID", mock.Anything, mock.Anything)
+	sessionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestRefreshToken_CreateSessionError(t *testing.T) {
+	t.Parallel()
+	svc, userRepo, sessionRepo := newTestService()
+
+	user := &userDomain.User{ID: 1, Email: "test@example.com"}
+	validRefreshToken, _ := svc.generateRefreshToken(user)
+
+	session := &sessionDomain.Session{
+		ID:           1,
+		UserID:       1,
+		RefreshToken: validRefreshToken,
+	}
+
+	sessionRepo.On("FindByRefreshToken", mock.Anything, validRefreshToken).Return(session, nil)
+	sessionRepo.On("Revoke", mock.Anything, uint(1)).Return(nil)
+	userRepo.On("FindByID", mock.Anything, uint(1)).Return(user, nil)
+	sessionRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Session")).Return(errors.New("insert failed"))
 
-	tokenPair, err := svc.RefreshToken(validRefreshToken)
+	tokenPair, err := svc.RefreshToken(context.Background(), validRefreshToken)
 
+	// The error is returned from inside the unit of work, which rolls the
+	// revocation back.
 	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "creating new session")
 	assert.Nil(t, tokenPair)
-	assert.Equal(t, "user not found", err.Error())
 }
 
 // --- Logout Tests ---
@@ -343,10 +499,10 @@ func TestLogout_Success(t *testing.T) {
 		RefreshToken: validRefreshToken,
 	}
 
-	sessionRepo.On("FindByRefreshToken", validRefreshToken).Return(session, nil)
-	sessionRepo.On("Revoke", uint(1)).Return(nil)
+	sessionRepo.On("FindByRefreshToken", mock.Anything, validRefreshToken).Return(session, nil)
+	sessionRepo.On("Revoke", mock.Anything, uint(1)).Return(nil)
 
-	err := svc.Logout(validRefreshToken)
+	err := svc.Logout(context.Background(), validRefreshToken)
 
 	assert.NoError(t, err)
 	sessionRepo.AssertExpectations(t)
@@ -356,14 +512,28 @@ func TestLogout_SessionNotFound_Idempotent(t *testing.T) {
 	t.Parallel()
 	svc, _, sessionRepo := newTestService()
 
-	sessionRepo.On("FindByRefreshToken", "some-token").Return(nil, errors.New("not found"))
+	sessionRepo.On("FindByRefreshToken", mock.Anything, "some-token").Return(nil, sessionDomain.ErrSessionNotFound)
 
-	err := svc.Logout("some-token")
+	err := svc.Logout(context.Background(), "some-token")
 
 	// Should not return error — idempotent behavior
 	assert.NoError(t, err)
 }
 
+func TestLogout_AlreadyRevoked_Idempotent(t *testing.T) {
+	t.Parallel()
+	svc, _, sessionRepo := newTestService()
+
+	session := &sessionDomain.Session{ID: 1, UserID: 1, RefreshToken: "some-token", IsRevoked: true}
+
+	sessionRepo.On("FindByRefreshToken", mock.Anything, "some-token").Return(session, nil)
+	sessionRepo.On("Revoke", mock.Anything, uint(1)).Return(sessionDomain.ErrSessionNotFound)
+
+	err := svc.Logout(context.Background(), "some-token")
+
+	assert.NoError(t, err)
+}
+
 func TestLogout_RevokeError(t *testing.T) {
 	t.Parallel()
 	svc, _, sessionRepo := newTestService()
@@ -377,10 +547,10 @@ func TestLogout_RevokeError(t *testing.T) {
 		RefreshToken: validRefreshToken,
 	}
 
-	sessionRepo.On("FindByRefreshToken", validRefreshToken).Return(session, nil)
-	sessionRepo.On("Revoke", uint(1)).Return(errors.New("revoke failed"))
+	sessionRepo.On("FindByRefreshToken", mock.Anything, validRefreshToken).Return(session, nil)
+	sessionRepo.On("Revoke", mock.Anything, uint(1)).Return(errors.New("revoke failed"))
 
-	err := svc.Logout(validRefreshToken)
+	err := svc.Logout(context.Background(), validRefreshToken)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "revoking session")
@@ -392,9 +562,9 @@ func TestLogoutAll_Success(t *testing.T) {
 	t.Parallel()
 	svc, _, sessionRepo := newTestService()
 
-	sessionRepo.On("RevokeAllForUser", uint(1)).Return(nil)
+	sessionRepo.On("RevokeAllForUser", mock.Anything, uint(1)).Return(nil)
 
-	err := svc.LogoutAll(1)
+	err := svc.LogoutAll(context.Background(), 1)
 
 	assert.NoError(t, err)
 	sessionRepo.AssertExpectations(t)
@@ -404,9 +574,9 @@ func TestLogoutAll_Error(t *testing.T) {
 	t.Parallel()
 	svc, _, sessionRepo := newTestService()
 
-	sessionRepo.On("RevokeAllForUser", uint(1)).Return(errors.New("db error"))
+	sessionRepo.On("RevokeAllForUser", mock.Anything, uint(1)).Return(errors.New("db error"))
 
-	err := svc.LogoutAll(1)
+	err := svc.LogoutAll(context.Background(), 1)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "revoking all sessions")
@@ -0,0 +1,15 @@
+package http
+
+import (
+	authDomain "english-learning/internal/modules/auth/domain"
+	"english-learning/pkg/response"
+	"net/http"
+)
+
+func init() {
+	response.RegisterError(authDomain.ErrEmailAlreadyRegistered, http.StatusConflict, response.CodeEmailAlreadyRegistered, response.MsgEmailRegistered)
+	response.RegisterError(authDomain.ErrInvalidCredentials, http.StatusUnauthorized, response.CodeInvalidCredentials, response.MsgInvalidCredentials)
+	response.RegisterError(authDomain.ErrInvalidRefreshToken, http.StatusUnauthorized, response.CodeInvalidRefreshToken, response.MsgInvalidRefreshToken)
+	response.RegisterError(authDomain.ErrInvalidSession, http.StatusUnauthorized, response.CodeInvalidRefreshToken, response.MsgInvalidRefreshToken)
+	response.RegisterError(authDomain.ErrSessionRevoked, http.StatusUnauthorized, response.CodeSessionRevoked, response.MsgSessionRevoked)
+}
@@ -3,8 +3,6 @@ package http
 import (
 	authDomain "english-learning/internal/modules/auth/domain"
 	"english-learning/pkg/response"
-	"english-learning/pkg/validation"
-	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
@@ -22,7 +20,7 @@ func NewAuthHandler(service authDomain.AuthService) *AuthHandler {
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req RegisterRequestDTO
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.Error(c, http.StatusBadRequest, response.CodeBadRequest, validation.FormatError(err))
+		response.BindError(c, err)
 		return
 	}
 
@@ -31,8 +29,8 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		Password: req.Password,
 	}
 
-	if err := h.service.Register(domainReq); err != nil {
-		response.Error(c, http.StatusBadRequest, response.CodeBadRequest, err.Error())
+	if err := h.service.Register(c.Request.Context(), domainReq); err != nil {
+		response.HandleError(c, err)
 		return
 	}
 
@@ -42,7 +40,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req LoginRequestDTO
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.Error(c, http.StatusBadRequest, response.CodeBadRequest, validation.FormatError(err))
+		response.BindError(c, err)
 		return
 	}
 
@@ -54,9 +52,9 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	userAgent := c.Request.UserAgent()
 	clientIP := c.ClientIP()
 
-	tokenPair, err := h.service.Login(domainReq, clientIP, userAgent)
+	tokenPair, err := h.service.Login(c.Request.Context(), domainReq, clientIP, userAgent)
 	if err != nil {
-		response.Error(c, http.StatusUnauthorized, response.CodeUnauthorized, "Invalid credentials")
+		response.HandleError(c, err)
 		return
 	}
 
@@ -71,13 +69,13 @@ func (h *AuthHandler) Login(c *gin.Context) {
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	var req RefreshTokenRequestDTO
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.Error(c, http.StatusBadRequest, response.CodeBadRequest, validation.FormatError(err))
+		response.BindError(c, err)
 		return
 	}
 
-	tokenPair, err := h.service.RefreshToken(req.RefreshToken)
+	tokenPair, err := h.service.RefreshToken(c.Request.Context(), req.RefreshToken)
 	if err != nil {
-		response.Error(c, http.StatusUnauthorized, response.CodeUnauthorized, err.Error())
+		response.HandleError(c, err)
 		return
 	}
 
@@ -92,12 +90,12 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 func (h *AuthHandler) Logout(c *gin.Context) {
 	var req RefreshTokenRequestDTO
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.Error(c, http.StatusBadRequest, response.CodeBadRequest, validation.FormatError(err))
+		response.BindError(c, err)
 		return
 	}
 
-	if err := h.service.Logout(req.RefreshToken); err != nil {
-		response.Error(c, http.StatusInternalServerError, response.CodeServerInternalError, err.Error())
+	if err := h.service.Logout(c.Request.Context(), req.RefreshToken); err != nil {
+		response.HandleError(c, err)
 		return
 	}
 
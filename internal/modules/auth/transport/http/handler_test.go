@@ -45,7 +45,7 @@ func TestRegisterHandler_Success(t *testing.T) {
 	handler := NewAuthHandler(mockService)
 	router := setupRouter(handler)
 
-	mockService.On("Register", mock.AnythingOfType("*domain.RegisterRequest")).Return(nil)
+	mockService.On("Register", mock.Anything, mock.AnythingOfType("*domain.RegisterRequest")).Return(nil)
 
 	body := RegisterRequestDTO{
 		Email:    "test@example.com",
@@ -71,7 +71,7 @@ func TestRegisterHandler_InvalidJSON_MissingEmail(t *testing.T) {
 	w := performRequest(router, "POST", "/auth/register", body)
 
 	assert.Equal(t, http.StatusBadRequest, w.Code)
-	mockService.AssertNotCalled(t, "Register", mock.Anything)
+	mockService.AssertNotCalled(t, "Register", mock.Anything, mock.Anything)
 }
 
 func TestRegisterHandler_InvalidJSON_ShortPassword(t *testing.T) {
@@ -88,7 +88,13 @@ func TestRegisterHandler_InvalidJSON_ShortPassword(t *testing.T) {
 	w := performRequest(router, "POST", "/auth/register", body)
 
 	assert.Equal(t, http.StatusBadRequest, w.Code)
-	mockService.AssertNotCalled(t, "Register", mock.Anything)
+	mockService.AssertNotCalled(t, "Register", mock.Anything, mock.Anything)
+
+	var resp map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "VALIDATION_FAILED", resp["code"])
+	fields := resp["errors"].([]interface{})
+	assert.Equal(t, "min", fields[0].(map[string]interface{})["tag"])
 }
 
 func TestRegisterHandler_InvalidEmail(t *testing.T) {
@@ -105,7 +111,7 @@ func TestRegisterHandler_InvalidEmail(t *testing.T) {
 	w := performRequest(router, "POST", "/auth/register", body)
 
 	assert.Equal(t, http.StatusBadRequest, w.Code)
-	mockService.AssertNotCalled(t, "Register", mock.Anything)
+	mockService.AssertNotCalled(t, "Register", mock.Anything, mock.Anything)
 }
 
 func TestRegisterHandler_ServiceError(t *testing.T) {
@@ -114,7 +120,7 @@ func TestRegisterHandler_ServiceError(t *testing.T) {
 	handler := NewAuthHandler(mockService)
 	router := setupRouter(handler)
 
-	mockService.On("Register", mock.AnythingOfType("*domain.RegisterRequest")).Return(errors.New("email already registered"))
+	mockService.On("Register", mock.Anything, mock.AnythingOfType("*domain.RegisterRequest")).Return(authDomain.ErrEmailAlreadyRegistered)
 
 	body := RegisterRequestDTO{
 		Email:    "test@example.com",
@@ -123,12 +129,32 @@ func TestRegisterHandler_ServiceError(t *testing.T) {
 
 	w := performRequest(router, "POST", "/auth/register", body)
 
-	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, http.StatusConflict, w.Code)
 
 	var resp map[string]interface{}
 	err := json.Unmarshal(w.Body.Bytes(), &resp)
 	assert.NoError(t, err)
-	assert.Equal(t, "email already registered", resp["message"])
+	assert.Equal(t, "EMAIL_ALREADY_REGISTERED", resp["code"])
+	assert.Equal(t, "Email already registered", resp["message"])
+}
+
+func TestRegisterHandler_InternalErrorNotExposed(t *testing.T) {
+	t.Parallel()
+	mockService := new(MockAuthService)
+	handler := NewAuthHandler(mockService)
+	router := setupRouter(handler)
+
+	mockService.On("Register", mock.Anything, mock.AnythingOfType("*domain.RegisterRequest")).Return(errors.New("creating user: pq: connection refused"))
+
+	body := RegisterRequestDTO{
+		Email:    "test@example.com",
+		Password: "password123",
+	}
+
+	w := performRequest(router, "POST", "/auth/register", body)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.NotContains(t, w.Body.String(), "connection refused")
 }
 
 // --- Login Handler Tests ---
@@ -144,7 +170,7 @@ func TestLoginHandler_Success(t *testing.T) {
 		RefreshToken: "refresh-token",
 	}
 
-	mockService.On("Login", mock.AnythingOfType("*domain.LoginRequest"), mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(tokenPair, nil)
+	mockService.On("Login", mock.Anything, mock.AnythingOfType("*domain.LoginRequest"), mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(tokenPair, nil)
 
 	body := LoginRequestDTO{
 		Email:    "test@example.com",
@@ -178,7 +204,7 @@ func TestLoginHandler_InvalidJSON(t *testing.T) {
 	w := performRequest(router, "POST", "/auth/login", body)
 
 	assert.Equal(t, http.StatusBadRequest, w.Code)
-	mockService.AssertNotCalled(t, "Login", mock.Anything, mock.Anything, mock.Anything)
+	mockService.AssertNotCalled(t, "Login", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 }
 
 func TestLoginHandler_Unauthorized(t *testing.T) {
@@ -187,7 +213,7 @@ func TestLoginHandler_Unauthorized(t *testing.T) {
 	handler := NewAuthHandler(mockService)
 	router := setupRouter(handler)
 
-	mockService.On("Login", mock.AnythingOfType("*domain.LoginRequest"), mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil, errors.New("invalid credentials"))
+	mockService.On("Login", mock.Anything, mock.AnythingOfType("*domain.LoginRequest"), mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil, authDomain.ErrInvalidCredentials)
 
 	body := LoginRequestDTO{
 		Email:    "test@example.com",
@@ -212,7 +238,7 @@ func TestRefreshTokenHandler_Success(t *testing.T) {
 		RefreshToken: "new-refresh-token",
 	}
 
-	mockService.On("RefreshToken", "valid-refresh-token").Return(tokenPair, nil)
+	mockService.On("RefreshToken", mock.Anything, "valid-refresh-token").Return(tokenPair, nil)
 
 	body := RefreshTokenRequestDTO{
 		RefreshToken: "valid-refresh-token",
@@ -242,7 +268,7 @@ func TestRefreshTokenHandler_InvalidJSON(t *testing.T) {
 	w := performRequest(router, "POST", "/auth/refresh-token", body)
 
 	assert.Equal(t, http.StatusBadRequest, w.Code)
-	mockService.AssertNotCalled(t, "RefreshToken", mock.Anything)
+	mockService.AssertNotCalled(t, "RefreshToken", mock.Anything, mock.Anything)
 }
 
 func TestRefreshTokenHandler_Unauthorized(t *testing.T) {
@@ -251,7 +277,7 @@ func TestRefreshTokenHandler_Unauthorized(t *testing.T) {
 	handler := NewAuthHandler(mockService)
 	router := setupRouter(handler)
 
-	mockService.On("RefreshToken", "invalid-token").Return(nil, errors.New("invalid refresh token"))
+	mockService.On("RefreshToken", mock.Anything, "invalid-token").Return(nil, authDomain.ErrInvalidRefreshToken)
 
 	body := RefreshTokenRequestDTO{
 		RefreshToken: "invalid-token",
@@ -270,7 +296,7 @@ func TestLogoutHandler_Success(t *testing.T) {
 	handler := NewAuthHandler(mockService)
 	router := setupRouter(handler)
 
-	mockService.On("Logout", "valid-refresh-token").Return(nil)
+	mockService.On("Logout", mock.Anything, "valid-refresh-token").Return(nil)
 
 	body := RefreshTokenRequestDTO{
 		RefreshToken: "valid-refresh-token",
@@ -293,7 +319,7 @@ func TestLogoutHandler_InvalidJSON(t *testing.T) {
 	w := performRequest(router, "POST", "/auth/logout", body)
 
 	assert.Equal(t, http.StatusBadRequest, w.Code)
-	mockService.AssertNotCalled(t, "Logout", mock.Anything)
+	mockService.AssertNotCalled(t, "Logout", mock.Anything, mock.Anything)
 }
 
 func TestLogoutHandler_ServiceError(t *testing.T) {
@@ -302,7 +328,7 @@ func TestLogoutHandler_ServiceError(t *testing.T) {
 	handler := NewAuthHandler(mockService)
 	router := setupRouter(handler)
 
-	mockService.On("Logout", "some-token").Return(errors.New("revoke failed"))
+	mockService.On("Logout", mock.Anything, "some-token").Return(errors.New("revoke failed"))
 
 	body := RefreshTokenRequestDTO{
 		RefreshToken: "some-token",
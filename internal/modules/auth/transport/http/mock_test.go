@@ -1,6 +1,7 @@
 package http
 
 import (
+	"context"
 	authDomain "english-learning/internal/modules/auth/domain"
 
 	"github.com/stretchr/testify/mock"
@@ -11,33 +12,33 @@ type MockAuthService struct {
 	mock.Mock
 }
 
-func (m *MockAuthService) Register(req *authDomain.RegisterRequest) error {
-	args := m.Called(req)
+func (m *MockAuthService) Register(ctx context.Context, req *authDomain.RegisterRequest) error {
+	args := m.Called(ctx, req)
 	return args.Error(0)
 }
 
-func (m *MockAuthService) Login(req *authDomain.LoginRequest, ip, userAgent string) (*authDomain.TokenPair, error) {
-	args := m.Called(req, ip, userAgent)
+func (m *MockAuthService) Login(ctx context.Context, req *authDomain.LoginRequest, ip, userAgent string) (*authDomain.TokenPair, error) {
+	args := m.Called(ctx, req, ip, userAgent)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*authDomain.TokenPair), args.Error(1)
 }
 
-func (m *MockAuthService) RefreshToken(refreshToken string) (*authDomain.TokenPair, error) {
-	args := m.Called(refreshToken)
+func (m *MockAuthService) RefreshToken(ctx context.Context, refreshToken string) (*authDomain.TokenPair, error) {
+	args := m.Called(ctx, refreshToken)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*authDomain.TokenPair), args.Error(1)
 }
 
-func (m *MockAuthService) Logout(refreshToken string) error {
-	args := m.Called(refreshToken)
+func (m *MockAuthService) Logout(ctx context.Context, refreshToken string) error {
+	args := m.Called(ctx, refreshToken)
 	return args.Error(0)
 }
 
-func (m *MockAuthService) LogoutAll(userID uint) error {
-	args := m.Called(userID)
+func (m *MockAuthService) LogoutAll(ctx context.Context, userID uint) error {
+	args := m.Called(ctx, userID)
 	return args.Error(0)
 }
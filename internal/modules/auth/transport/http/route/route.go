@@ -6,11 +6,12 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// Register registers all auth routes on the given router.
-func Register(r *gin.Engine, h *handler.AuthHandler) {
+// Register registers all auth routes on the given router. idempotent guards
+// the routes that accept an Idempotency-Key header.
+func Register(r gin.IRouter, h *handler.AuthHandler, idempotent gin.HandlerFunc) {
 	group := r.Group("/auth")
 	{
-		group.POST("/register", h.Register)
+		group.POST("/register", idempotent, h.Register)
 		group.POST("/login", h.Login)
 		group.POST("/refresh-token", h.RefreshToken)
 		group.POST("/logout", h.Logout)
@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"hash/fnv"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Flag switches a feature on for the subjects matched by one of its rules.
+// A disabled flag is off for everyone, whatever its rules: it is the kill
+// switch. A flag without rules is off too; a rule without conditions matches
+// everyone.
+type Flag struct {
+	// Key names the flag in code and in GET /features, e.g.
+	// "lesson.speaking". It never changes.
+	Key         string
+	Description string
+	Enabled     bool
+	Rules       []Rule
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Rule matches the subjects meeting all of its conditions. Empty conditions
+// are ignored.
+type Rule struct {
+	UserIDs []uint
+	Roles   []string
+	// MinAppVersion matches apps at this release or later, e.g. "2.3".
+	// Requests without a parseable X-App-Version never match.
+	MinAppVersion string
+	// Percentage matches this share of users, 0 to 100. Each user falls in a
+	// fixed bucket per flag, so raising it only adds users. Anonymous
+	// subjects never match.
+	Percentage *int
+}
+
+// Subject is who a flag is evaluated for.
+type Subject struct {
+	// UserID is 0 for anonymous callers.
+	UserID     uint
+	Role       string
+	AppVersion string
+}
+
+var keyPattern = regexp.MustCompile(`^[a-z0-9]+([._-][a-z0-9]+)*$`)
+
+// ValidKey reports whether key is lowercase words separated by '.', '_' or
+// '-', at most 100 characters.
+func ValidKey(key string) bool {
+	return len(key) <= 100 && keyPattern.MatchString(key)
+}
+
+// EnabledFor reports whether f is on for s.
+func (f *Flag) EnabledFor(s Subject) bool {
+	if !f.Enabled {
+		return false
+	}
+	for _, r := range f.Rules {
+		if r.matches(f.Key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Rule) matches(key string, s Subject) bool {
+	if len(r.UserIDs) > 0 && !slices.Contains(r.UserIDs, s.UserID) {
+		return false
+	}
+	if len(r.Roles) > 0 && !slices.Contains(r.Roles, s.Role) {
+		return false
+	}
+	if r.MinAppVersion != "" {
+		min, ok := ParseVersion(r.MinAppVersion)
+		app, appOK := ParseVersion(s.AppVersion)
+		if !ok || !appOK || slices.Compare(app, min) < 0 {
+			return false
+		}
+	}
+	if r.Percentage != nil {
+		if s.UserID == 0 || bucket(key, s.UserID) >= *r.Percentage {
+			return false
+		}
+	}
+	return true
+}
+
+// bucket places a user in 0-99 for a flag. Hashing the key with the ID gives
+// every flag an independent sample of users.
+func bucket(key string, userID uint) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key + ":" + strconv.FormatUint(uint64(userID), 10)))
+	return int(h.Sum32() % 100)
+}
+
+// ParseVersion parses the leading "major[.minor[.patch]]" of an app version
+// such as "2.3.1-beta" into three numbers.
+func ParseVersion(v string) ([]int, bool) {
+	if i := strings.IndexFunc(v, func(r rune) bool { return r != '.' && (r < '0' || r > '9') }); i >= 0 {
+		v = v[:i]
+	}
+	parts := strings.Split(v, ".")
+	if v == "" || len(parts) > 3 {
+		return nil, false
+	}
+	version := make([]int, 3)
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, false
+		}
+		version[i] = n
+	}
+	return version, true
+}
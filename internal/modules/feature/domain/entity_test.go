@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func percent(p int) *int { return &p }
+
+func TestFlag_EnabledFor(t *testing.T) {
+	t.Parallel()
+	student := Subject{UserID: 7, Role: "student", AppVersion: "2.3.1"}
+	tests := []struct {
+		name string
+		flag Flag
+		want bool
+	}{
+		{"disabled flag is off", Flag{Enabled: false, Rules: []Rule{{}}}, false},
+		{"flag without rules is off", Flag{Enabled: true}, false},
+		{"empty rule matches everyone", Flag{Enabled: true, Rules: []Rule{{}}}, true},
+		{"user ID", Flag{Enabled: true, Rules: []Rule{{UserIDs: []uint{3, 7}}}}, true},
+		{"other user ID", Flag{Enabled: true, Rules: []Rule{{UserIDs: []uint{3}}}}, false},
+		{"role", Flag{Enabled: true, Rules: []Rule{{Roles: []string{"student"}}}}, true},
+		{"other role", Flag{Enabled: true, Rules: []Rule{{Roles: []string{"admin"}}}}, false},
+		{"app version at minimum", Flag{Enabled: true, Rules: []Rule{{MinAppVersion: "2.3.1"}}}, true},
+		{"app version above minimum", Flag{Enabled: true, Rules: []Rule{{MinAppVersion: "2.2"}}}, true},
+		{"app version below minimum", Flag{Enabled: true, Rules: []Rule{{MinAppVersion: "2.10"}}}, false},
+		{"conditions are ANDed", Flag{Enabled: true, Rules: []Rule{{UserIDs: []uint{7}, Roles: []string{"admin"}}}}, false},
+		{"rules are ORed", Flag{Enabled: true, Rules: []Rule{{Roles: []string{"admin"}}, {UserIDs: []uint{7}}}}, true},
+		{"100 percent", Flag{Enabled: true, Rules: []Rule{{Percentage: percent(100)}}}, true},
+		{"0 percent", Flag{Enabled: true, Rules: []Rule{{Percentage: percent(0)}}}, false},
+	}
+	for _, tt := range tests {
+		assert.Equalf(t, tt.want, tt.flag.EnabledFor(student), tt.name)
+	}
+}
+
+func TestFlag_EnabledFor_Anonymous(t *testing.T) {
+	t.Parallel()
+	anonymous := Subject{}
+
+	assert.True(t, (&Flag{Enabled: true, Rules: []Rule{{}}}).EnabledFor(anonymous))
+	assert.False(t, (&Flag{Enabled: true, Rules: []Rule{{Percentage: percent(100)}}}).EnabledFor(anonymous))
+	assert.False(t, (&Flag{Enabled: true, Rules: []Rule{{MinAppVersion: "1.0"}}}).EnabledFor(anonymous))
+}
+
+func TestFlag_EnabledFor_PercentageIsStable(t *testing.T) {
+	t.Parallel()
+	flag := Flag{Key: "lesson.speaking", Enabled: true, Rules: []Rule{{Percentage: percent(30)}}}
+	wider := Flag{Key: "lesson.speaking", Enabled: true, Rules: []Rule{{Percentage: percent(60)}}}
+
+	on := 0
+	for id := uint(1); id <= 1000; id++ {
+		s := Subject{UserID: id}
+		if flag.EnabledFor(s) {
+			on++
+			assert.Truef(t, wider.EnabledFor(s), "user %d dropped when the rollout grew", id)
+		}
+		assert.Equal(t, flag.EnabledFor(s), flag.EnabledFor(s))
+	}
+	assert.InDelta(t, 300, on, 60)
+}
+
+func TestParseVersion(t *testing.T) {
+	t.Parallel()
+	for v, want := range map[string][]int{
+		"2":          {2, 0, 0},
+		"2.3":        {2, 3, 0},
+		"2.3.1":      {2, 3, 1},
+		"2.3.1-beta": {2, 3, 1},
+		"2.3 (451)":  {2, 3, 0},
+	} {
+		got, ok := ParseVersion(v)
+		assert.Truef(t, ok, "ParseVersion(%q)", v)
+		assert.Equalf(t, want, got, "ParseVersion(%q)", v)
+	}
+	for _, v := range []string{"", "beta", "1.2.3.4", "1..2", "v2.3"} {
+		_, ok := ParseVersion(v)
+		assert.Falsef(t, ok, "ParseVersion(%q)", v)
+	}
+}
+
+func TestValidKey(t *testing.T) {
+	t.Parallel()
+	for _, key := range []string{"lesson.speaking", "new_onboarding", "dark-mode", "v2"} {
+		assert.Truef(t, ValidKey(key), "ValidKey(%q)", key)
+	}
+	for _, key := range []string{"", "Lesson", "lesson..speaking", ".lesson", "lesson speaking", "lesson/speaking"} {
+		assert.Falsef(t, ValidKey(key), "ValidKey(%q)", key)
+	}
+}
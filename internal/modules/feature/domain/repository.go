@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrFlagNotFound   = errors.New("feature flag not found")
+	ErrFlagExists     = errors.New("feature flag already exists")
+	ErrInvalidFlagKey = errors.New("invalid feature flag key")
+)
+
+type FlagRepository interface {
+	// List returns every flag ordered by key. There are few enough flags to
+	// evaluate them all per request.
+	List(ctx context.Context) ([]Flag, error)
+	FindByKey(ctx context.Context, key string) (*Flag, error)
+	// Create fails with ErrFlagExists if the key is taken.
+	Create(ctx context.Context, flag *Flag) error
+	// Update saves the description, Enabled and Rules of an existing flag.
+	Update(ctx context.Context, flag *Flag) error
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,26 @@
+package domain
+
+import "context"
+
+// Evaluator answers whether a feature is on. It is what other services and
+// handlers depend on to gate behaviour.
+type Evaluator interface {
+	// Enabled reports whether the flag key is on for s. Unknown flags and
+	// lookup failures count as off.
+	Enabled(ctx context.Context, key string, s Subject) bool
+}
+
+// FlagService defines the business logic contract for feature flags.
+type FlagService interface {
+	Evaluator
+	// Evaluate returns every flag's state for s, keyed by flag key.
+	Evaluate(ctx context.Context, s Subject) (map[string]bool, error)
+
+	List(ctx context.Context) ([]Flag, error)
+	Get(ctx context.Context, key string) (*Flag, error)
+	Create(ctx context.Context, flag *Flag) error
+	// Update replaces the description, Enabled and Rules of the flag with
+	// flag.Key.
+	Update(ctx context.Context, flag *Flag) error
+	Delete(ctx context.Context, key string) error
+}
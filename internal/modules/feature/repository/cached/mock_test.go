@@ -0,0 +1,52 @@
+package cached
+
+import (
+	"context"
+	"english-learning/internal/modules/feature/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockFlagRepository is a mock implementation of domain.FlagRepository.
+type MockFlagRepository struct {
+	mock.Mock
+}
+
+func (m *MockFlagRepository) List(ctx context.Context) ([]domain.Flag, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Flag), args.Error(1)
+}
+
+func (m *MockFlagRepository) FindByKey(ctx context.Context, key string) (*domain.Flag, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Flag), args.Error(1)
+}
+
+func (m *MockFlagRepository) Create(ctx context.Context, flag *domain.Flag) error {
+	args := m.Called(ctx, flag)
+	return args.Error(0)
+}
+
+func (m *MockFlagRepository) Update(ctx context.Context, flag *domain.Flag) error {
+	args := m.Called(ctx, flag)
+	return args.Error(0)
+}
+
+func (m *MockFlagRepository) Delete(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+// fakeMetrics counts cache lookups.
+type fakeMetrics struct {
+	hits, misses int
+}
+
+func (m *fakeMetrics) CacheHit(string)  { m.hits++ }
+func (m *fakeMetrics) CacheMiss(string) { m.misses++ }
@@ -0,0 +1,160 @@
+// Package cached decorates a domain.FlagRepository with an in-memory copy of
+// every flag, since flags are evaluated on every GET /features and wherever a
+// service gates a feature.
+package cached
+
+import (
+	"context"
+	"english-learning/internal/database"
+	"english-learning/internal/modules/feature/domain"
+	"english-learning/pkg/cache"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Channel is the Postgres NOTIFY channel on which the feature_flags trigger
+// announces every change, whoever made it.
+const Channel = "feature_flag_changed"
+
+// metricsName labels this cache in the cache metrics.
+const metricsName = "features"
+
+// Metrics records cache lookups.
+type Metrics interface {
+	CacheHit(cache string)
+	CacheMiss(cache string)
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) CacheHit(string)  {}
+func (nopMetrics) CacheMiss(string) {}
+
+// allFlags is the only key of the cache: flags are few, so they are loaded
+// and dropped together.
+type allFlags struct{}
+
+// FlagRepository serves List and FindByKey from a cached snapshot of all
+// flags and drops it on every write. Changes made by other instances or
+// outside the application are picked up through HandleNotification; if a
+// notification is missed, the snapshot is stale for at most the backend's
+// TTL.
+type FlagRepository struct {
+	domain.FlagRepository
+	cache   cache.Cache[allFlags, []domain.Flag]
+	metrics Metrics
+
+	// mu and generation keep a load that raced with an invalidation from
+	// caching the flags it read before the change.
+	mu         sync.Mutex
+	generation uint64
+}
+
+// NewFlagRepository wraps next, caching its flags for ttl. metrics may be
+// nil.
+func NewFlagRepository(next domain.FlagRepository, ttl time.Duration, metrics Metrics) *FlagRepository {
+	if metrics == nil {
+		metrics = nopMetrics{}
+	}
+	return &FlagRepository{
+		FlagRepository: next,
+		cache:          cache.NewLRU[allFlags, []domain.Flag](1, ttl),
+		metrics:        metrics,
+	}
+}
+
+// List returns the cached flags if present. Lookups inside a unit of work
+// bypass the cache, since they may see uncommitted changes.
+func (r *FlagRepository) List(ctx context.Context) ([]domain.Flag, error) {
+	if database.InTransaction(ctx) {
+		return r.FlagRepository.List(ctx)
+	}
+
+	if flags, ok := r.cache.Get(ctx, allFlags{}); ok {
+		r.metrics.CacheHit(metricsName)
+		return cloneAll(flags), nil
+	}
+	r.metrics.CacheMiss(metricsName)
+
+	r.mu.Lock()
+	generation := r.generation
+	r.mu.Unlock()
+
+	flags, err := r.FlagRepository.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	if r.generation == generation {
+		r.cache.Set(ctx, allFlags{}, cloneAll(flags))
+	}
+	r.mu.Unlock()
+
+	return flags, nil
+}
+
+// FindByKey looks the flag up in the cached snapshot.
+func (r *FlagRepository) FindByKey(ctx context.Context, key string) (*domain.Flag, error) {
+	flags, err := r.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range flags {
+		if flags[i].Key == key {
+			return &flags[i], nil
+		}
+	}
+	return nil, domain.ErrFlagNotFound
+}
+
+func (r *FlagRepository) Create(ctx context.Context, flag *domain.Flag) error {
+	defer r.Flush()
+	return r.FlagRepository.Create(ctx, flag)
+}
+
+func (r *FlagRepository) Update(ctx context.Context, flag *domain.Flag) error {
+	defer r.Flush()
+	return r.FlagRepository.Update(ctx, flag)
+}
+
+func (r *FlagRepository) Delete(ctx context.Context, key string) error {
+	defer r.Flush()
+	return r.FlagRepository.Delete(ctx, key)
+}
+
+// Flush drops the cached flags. It runs after every write, on every
+// notification and whenever the notification listener (re)connects, as
+// changes may have been missed meanwhile.
+func (r *FlagRepository) Flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.generation++
+	r.cache.Clear(context.Background())
+}
+
+// HandleNotification drops the cached flags on any notification on Channel;
+// the payload, the changed key, is not needed.
+func (r *FlagRepository) HandleNotification(string) {
+	r.Flush()
+}
+
+// cloneAll deep-copies flags so that callers cannot modify the cached value.
+func cloneAll(flags []domain.Flag) []domain.Flag {
+	c := make([]domain.Flag, len(flags))
+	for i, f := range flags {
+		f.Rules = slices.Clone(f.Rules)
+		for j, rule := range f.Rules {
+			rule.UserIDs = slices.Clone(rule.UserIDs)
+			rule.Roles = slices.Clone(rule.Roles)
+			if rule.Percentage != nil {
+				percentage := *rule.Percentage
+				rule.Percentage = &percentage
+			}
+			f.Rules[j] = rule
+		}
+		c[i] = f
+	}
+	return c
+}
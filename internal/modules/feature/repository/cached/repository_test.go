@@ -0,0 +1,141 @@
+package cached
+
+import (
+	"context"
+	"english-learning/internal/modules/feature/domain"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRepository() (*FlagRepository, *MockFlagRepository, *fakeMetrics) {
+	next := new(MockFlagRepository)
+	metrics := &fakeMetrics{}
+	return NewFlagRepository(next, time.Minute, metrics), next, metrics
+}
+
+func flags() []domain.Flag {
+	return []domain.Flag{
+		{Key: "lesson.speaking", Enabled: true, Rules: []domain.Rule{{Roles: []string{"admin"}}}},
+		{Key: "lesson.writing"},
+	}
+}
+
+func TestList_CachesFlags(t *testing.T) {
+	t.Parallel()
+	repo, next, metrics := newTestRepository()
+	ctx := context.Background()
+
+	next.On("List", mock.Anything).Return(flags(), nil).Once()
+
+	first, err := repo.List(ctx)
+	require.NoError(t, err)
+	second, err := repo.List(ctx)
+	require.NoError(t, err)
+	flag, err := repo.FindByKey(ctx, "lesson.writing")
+	require.NoError(t, err)
+	_, err = repo.FindByKey(ctx, "missing")
+
+	assert.Equal(t, first, second)
+	assert.Equal(t, "lesson.writing", flag.Key)
+	assert.ErrorIs(t, err, domain.ErrFlagNotFound)
+	assert.Equal(t, 3, metrics.hits)
+	assert.Equal(t, 1, metrics.misses)
+	next.AssertExpectations(t)
+}
+
+func TestList_ReturnsCopies(t *testing.T) {
+	t.Parallel()
+	repo, next, _ := newTestRepository()
+	ctx := context.Background()
+
+	next.On("List", mock.Anything).Return(flags(), nil).Once()
+
+	listed, err := repo.List(ctx)
+	require.NoError(t, err)
+	listed[0].Enabled = false
+	listed[0].Rules[0].Roles[0] = "student"
+
+	cached, err := repo.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, flags(), cached)
+}
+
+func TestList_DoesNotCacheErrors(t *testing.T) {
+	t.Parallel()
+	repo, next, _ := newTestRepository()
+	ctx := context.Background()
+	failure := errors.New("connection refused")
+
+	next.On("List", mock.Anything).Return(nil, failure).Twice()
+
+	_, err := repo.List(ctx)
+	assert.ErrorIs(t, err, failure)
+	_, err = repo.List(ctx)
+	assert.ErrorIs(t, err, failure)
+	next.AssertExpectations(t)
+}
+
+func TestWrites_Flush(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	writes := map[string]func(repo *FlagRepository, next *MockFlagRepository) error{
+		"Create": func(repo *FlagRepository, next *MockFlagRepository) error {
+			next.On("Create", mock.Anything, mock.Anything).Return(nil)
+			return repo.Create(ctx, &domain.Flag{Key: "lesson.reading"})
+		},
+		"Update": func(repo *FlagRepository, next *MockFlagRepository) error {
+			next.On("Update", mock.Anything, mock.Anything).Return(nil)
+			return repo.Update(ctx, &domain.Flag{Key: "lesson.writing"})
+		},
+		"Delete": func(repo *FlagRepository, next *MockFlagRepository) error {
+			next.On("Delete", mock.Anything, "lesson.writing").Return(nil)
+			return repo.Delete(ctx, "lesson.writing")
+		},
+		"notification": func(repo *FlagRepository, next *MockFlagRepository) error {
+			repo.HandleNotification("lesson.writing")
+			return nil
+		},
+	}
+
+	for name, write := range writes {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			repo, next, metrics := newTestRepository()
+			next.On("List", mock.Anything).Return(flags(), nil).Twice()
+
+			_, err := repo.List(ctx)
+			require.NoError(t, err)
+			require.NoError(t, write(repo, next))
+			_, err = repo.List(ctx)
+			require.NoError(t, err)
+
+			assert.Equal(t, 2, metrics.misses)
+			next.AssertExpectations(t)
+		})
+	}
+}
+
+func TestList_RacingInvalidationIsNotCached(t *testing.T) {
+	t.Parallel()
+	repo, next, metrics := newTestRepository()
+	ctx := context.Background()
+
+	// A flag is switched off while the first load is reading the old rows.
+	next.On("List", mock.Anything).
+		Run(func(mock.Arguments) { repo.HandleNotification("lesson.speaking") }).
+		Return(flags(), nil).Once()
+	next.On("List", mock.Anything).Return([]domain.Flag{{Key: "lesson.speaking"}}, nil).Once()
+
+	_, err := repo.List(ctx)
+	require.NoError(t, err)
+	listed, err := repo.List(ctx)
+	require.NoError(t, err)
+
+	assert.False(t, listed[0].Enabled)
+	assert.Equal(t, 2, metrics.misses)
+}
@@ -0,0 +1,102 @@
+// Package memory implements domain.FlagRepository in memory, for fast tests.
+// It behaves like the Postgres repository, as checked by the repotest contract
+// suite.
+package memory
+
+import (
+	"context"
+	"english-learning/internal/modules/feature/domain"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+type FlagRepository struct {
+	now func() time.Time
+
+	mu    sync.Mutex
+	flags map[string]*domain.Flag
+}
+
+func NewFlagRepository() *FlagRepository {
+	return &FlagRepository{now: time.Now, flags: make(map[string]*domain.Flag)}
+}
+
+// clone copies f so callers never share memory with the repository.
+func clone(f *domain.Flag) *domain.Flag {
+	c := *f
+	c.Rules = make([]domain.Rule, len(f.Rules))
+	for i, r := range f.Rules {
+		r.UserIDs = slices.Clone(r.UserIDs)
+		r.Roles = slices.Clone(r.Roles)
+		if r.Percentage != nil {
+			percentage := *r.Percentage
+			r.Percentage = &percentage
+		}
+		c.Rules[i] = r
+	}
+	return &c
+}
+
+func (r *FlagRepository) List(_ context.Context) ([]domain.Flag, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	flags := make([]domain.Flag, 0, len(r.flags))
+	for _, f := range r.flags {
+		flags = append(flags, *clone(f))
+	}
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Key < flags[j].Key })
+	return flags, nil
+}
+
+func (r *FlagRepository) FindByKey(_ context.Context, key string) (*domain.Flag, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, ok := r.flags[key]
+	if !ok {
+		return nil, domain.ErrFlagNotFound
+	}
+	return clone(f), nil
+}
+
+func (r *FlagRepository) Create(_ context.Context, flag *domain.Flag) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.flags[flag.Key]; ok {
+		return domain.ErrFlagExists
+	}
+	now := r.now()
+	flag.CreatedAt = now
+	flag.UpdatedAt = now
+	r.flags[flag.Key] = clone(flag)
+	return nil
+}
+
+func (r *FlagRepository) Update(_ context.Context, flag *domain.Flag) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.flags[flag.Key]
+	if !ok {
+		return domain.ErrFlagNotFound
+	}
+	flag.CreatedAt = existing.CreatedAt
+	flag.UpdatedAt = r.now()
+	r.flags[flag.Key] = clone(flag)
+	return nil
+}
+
+func (r *FlagRepository) Delete(_ context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.flags[key]; !ok {
+		return domain.ErrFlagNotFound
+	}
+	delete(r.flags, key)
+	return nil
+}
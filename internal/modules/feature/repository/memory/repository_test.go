@@ -0,0 +1,14 @@
+package memory
+
+import (
+	"english-learning/internal/modules/feature/domain"
+	"english-learning/internal/modules/feature/repository/repotest"
+	"testing"
+)
+
+func TestFlagRepository_Contract(t *testing.T) {
+	t.Parallel()
+	repotest.Run(t, func(*testing.T) domain.FlagRepository {
+		return NewFlagRepository()
+	})
+}
@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"english-learning/internal/modules/feature/domain"
+	"time"
+)
+
+// Flag is a row of feature_flags.
+type Flag struct {
+	Key         string    `gorm:"type:varchar(100);primaryKey"`
+	Description string    `gorm:"type:text;not null;default:''"`
+	Enabled     bool      `gorm:"not null;default:false"`
+	Rules       []Rule    `gorm:"type:jsonb;serializer:json;not null"`
+	CreatedAt   time.Time `gorm:"type:timestamp with time zone;autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"type:timestamp with time zone;autoUpdateTime"`
+}
+
+func (Flag) TableName() string {
+	return "feature_flags"
+}
+
+// Rule is the JSON form of a domain.Rule in feature_flags.rules.
+type Rule struct {
+	UserIDs       []uint   `json:"userIds,omitempty"`
+	Roles         []string `json:"roles,omitempty"`
+	MinAppVersion string   `json:"minAppVersion,omitempty"`
+	Percentage    *int     `json:"percentage,omitempty"`
+}
+
+func (m *Flag) ToDomain() *domain.Flag {
+	if m == nil {
+		return nil
+	}
+	rules := make([]domain.Rule, len(m.Rules))
+	for i, r := range m.Rules {
+		rules[i] = domain.Rule(r)
+	}
+	return &domain.Flag{
+		Key:         m.Key,
+		Description: m.Description,
+		Enabled:     m.Enabled,
+		Rules:       rules,
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+	}
+}
+
+func FromDomainFlag(f *domain.Flag) *Flag {
+	if f == nil {
+		return nil
+	}
+	rules := make([]Rule, len(f.Rules))
+	for i, r := range f.Rules {
+		rules[i] = Rule(r)
+	}
+	return &Flag{
+		Key:         f.Key,
+		Description: f.Description,
+		Enabled:     f.Enabled,
+		Rules:       rules,
+		CreatedAt:   f.CreatedAt,
+		UpdatedAt:   f.UpdatedAt,
+	}
+}
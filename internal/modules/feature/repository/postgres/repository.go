@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"english-learning/internal/database"
+	"english-learning/internal/modules/feature/domain"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type FlagRepository struct {
+	db *gorm.DB
+}
+
+func NewFlagRepository(db *gorm.DB) domain.FlagRepository {
+	return &FlagRepository{db: db}
+}
+
+// conn joins the caller's unit of work, if any.
+func (r *FlagRepository) conn(ctx context.Context) *gorm.DB {
+	return database.Conn(ctx, r.db)
+}
+
+func (r *FlagRepository) List(ctx context.Context) ([]domain.Flag, error) {
+	var flagModels []Flag
+	if err := r.conn(ctx).Order("key").Find(&flagModels).Error; err != nil {
+		return nil, err
+	}
+
+	flags := make([]domain.Flag, len(flagModels))
+	for i, model := range flagModels {
+		flags[i] = *model.ToDomain()
+	}
+	return flags, nil
+}
+
+func (r *FlagRepository) FindByKey(ctx context.Context, key string) (*domain.Flag, error) {
+	var flagModel Flag
+	err := r.conn(ctx).Where("key = ?", key).First(&flagModel).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrFlagNotFound
+		}
+		return nil, err
+	}
+	return flagModel.ToDomain(), nil
+}
+
+func (r *FlagRepository) Create(ctx context.Context, flag *domain.Flag) error {
+	flagModel := FromDomainFlag(flag)
+	if err := r.conn(ctx).Create(flagModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return domain.ErrFlagExists
+		}
+		return err
+	}
+	flag.CreatedAt = flagModel.CreatedAt
+	flag.UpdatedAt = flagModel.UpdatedAt
+	return nil
+}
+
+// Update writes the mutable fields of an existing flag. Unlike Save, it never
+// inserts.
+func (r *FlagRepository) Update(ctx context.Context, flag *domain.Flag) error {
+	flagModel := FromDomainFlag(flag)
+	var stored Flag
+	err := r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Flag{Key: flag.Key}).
+			Select("description", "enabled", "rules", "updated_at").
+			Updates(flagModel)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrFlagNotFound
+		}
+		return tx.Where("key = ?", flag.Key).First(&stored).Error
+	})
+	if err != nil {
+		return err
+	}
+	flag.CreatedAt = stored.CreatedAt
+	flag.UpdatedAt = stored.UpdatedAt
+	return nil
+}
+
+func (r *FlagRepository) Delete(ctx context.Context, key string) error {
+	result := r.conn(ctx).Where("key = ?", key).Delete(&Flag{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrFlagNotFound
+	}
+	return nil
+}
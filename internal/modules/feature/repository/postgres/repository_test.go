@@ -0,0 +1,16 @@
+package postgres
+
+import (
+	"english-learning/internal/database/dbtest"
+	"english-learning/internal/modules/feature/domain"
+	"english-learning/internal/modules/feature/repository/repotest"
+	"testing"
+)
+
+func TestFlagRepository_Contract(t *testing.T) {
+	db := dbtest.Open(t)
+	repotest.Run(t, func(t *testing.T) domain.FlagRepository {
+		dbtest.Truncate(t, db, "feature_flags")
+		return NewFlagRepository(db)
+	})
+}
@@ -0,0 +1,123 @@
+// Package repotest is the contract every domain.FlagRepository must honour.
+// Each implementation runs it from its own tests; the Postgres run needs
+// TEST_DATABASE_DSN.
+package repotest
+
+import (
+	"context"
+	"english-learning/internal/modules/feature/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Run checks repositories returned by newRepo, which must be empty. Subtests
+// run sequentially so they may share a database.
+func Run(t *testing.T, newRepo func(t *testing.T) domain.FlagRepository) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, repo domain.FlagRepository)
+	}{
+		{"CreateAndFind", testCreateAndFind},
+		{"CreateDuplicateKey", testCreateDuplicateKey},
+		{"FindMissing", testFindMissing},
+		{"List", testList},
+		{"Update", testUpdate},
+		{"UpdateMissing", testUpdateMissing},
+		{"Delete", testDelete},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t))
+		})
+	}
+}
+
+func newFlag(key string) *domain.Flag {
+	percentage := 25
+	return &domain.Flag{
+		Key:         key,
+		Description: "Speaking exercises",
+		Enabled:     true,
+		Rules: []domain.Rule{
+			{UserIDs: []uint{1, 2}},
+			{Roles: []string{"admin"}, MinAppVersion: "2.3"},
+			{Percentage: &percentage},
+		},
+	}
+}
+
+func create(t *testing.T, repo domain.FlagRepository, key string) *domain.Flag {
+	t.Helper()
+	flag := newFlag(key)
+	require.NoError(t, repo.Create(context.Background(), flag))
+	return flag
+}
+
+func testCreateAndFind(t *testing.T, repo domain.FlagRepository) {
+	flag := create(t, repo, "lesson.speaking")
+	assert.False(t, flag.CreatedAt.IsZero())
+	assert.False(t, flag.UpdatedAt.IsZero())
+
+	found, err := repo.FindByKey(context.Background(), "lesson.speaking")
+	require.NoError(t, err)
+	assert.Equal(t, "Speaking exercises", found.Description)
+	assert.True(t, found.Enabled)
+	assert.Equal(t, newFlag("lesson.speaking").Rules, found.Rules)
+}
+
+func testCreateDuplicateKey(t *testing.T, repo domain.FlagRepository) {
+	create(t, repo, "lesson.speaking")
+	err := repo.Create(context.Background(), newFlag("lesson.speaking"))
+	assert.ErrorIs(t, err, domain.ErrFlagExists)
+}
+
+func testFindMissing(t *testing.T, repo domain.FlagRepository) {
+	_, err := repo.FindByKey(context.Background(), "missing")
+	assert.ErrorIs(t, err, domain.ErrFlagNotFound)
+}
+
+func testList(t *testing.T, repo domain.FlagRepository) {
+	create(t, repo, "lesson.writing")
+	create(t, repo, "lesson.speaking")
+
+	flags, err := repo.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, flags, 2)
+	assert.Equal(t, "lesson.speaking", flags[0].Key)
+	assert.Equal(t, "lesson.writing", flags[1].Key)
+}
+
+func testUpdate(t *testing.T, repo domain.FlagRepository) {
+	ctx := context.Background()
+	flag := create(t, repo, "lesson.speaking")
+	createdAt := flag.CreatedAt
+
+	flag.Description = "Changed"
+	flag.Enabled = false
+	flag.Rules = []domain.Rule{}
+	require.NoError(t, repo.Update(ctx, flag))
+	assert.True(t, flag.CreatedAt.Equal(createdAt))
+
+	found, err := repo.FindByKey(ctx, "lesson.speaking")
+	require.NoError(t, err)
+	assert.Equal(t, "Changed", found.Description)
+	assert.False(t, found.Enabled)
+	assert.Empty(t, found.Rules)
+}
+
+func testUpdateMissing(t *testing.T, repo domain.FlagRepository) {
+	err := repo.Update(context.Background(), newFlag("missing"))
+	assert.ErrorIs(t, err, domain.ErrFlagNotFound)
+}
+
+func testDelete(t *testing.T, repo domain.FlagRepository) {
+	ctx := context.Background()
+	create(t, repo, "lesson.speaking")
+
+	require.NoError(t, repo.Delete(ctx, "lesson.speaking"))
+	_, err := repo.FindByKey(ctx, "lesson.speaking")
+	assert.ErrorIs(t, err, domain.ErrFlagNotFound)
+	assert.ErrorIs(t, repo.Delete(ctx, "lesson.speaking"), domain.ErrFlagNotFound)
+}
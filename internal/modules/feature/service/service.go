@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"english-learning/internal/modules/feature/domain"
+	"english-learning/pkg/logger"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// Service implements domain.FlagService.
+type Service struct {
+	repo domain.FlagRepository
+}
+
+// NewService creates a new feature flag Service.
+func NewService(repo domain.FlagRepository) *Service {
+	return &Service{repo: repo}
+}
+
+// Enabled fails closed: a flag that cannot be read is off, so a database
+// outage never turns unfinished features on.
+func (s *Service) Enabled(ctx context.Context, key string, subject domain.Subject) bool {
+	flags, err := s.repo.List(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Named("feature").Warn("Evaluating feature flag failed", zap.String("flag", key), zap.Error(err))
+		return false
+	}
+	for i := range flags {
+		if flags[i].Key == key {
+			return flags[i].EnabledFor(subject)
+		}
+	}
+	return false
+}
+
+func (s *Service) Evaluate(ctx context.Context, subject domain.Subject) (map[string]bool, error) {
+	flags, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing feature flags: %w", err)
+	}
+
+	states := make(map[string]bool, len(flags))
+	for i := range flags {
+		states[flags[i].Key] = flags[i].EnabledFor(subject)
+	}
+	return states, nil
+}
+
+func (s *Service) List(ctx context.Context) ([]domain.Flag, error) {
+	flags, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing feature flags: %w", err)
+	}
+	return flags, nil
+}
+
+func (s *Service) Get(ctx context.Context, key string) (*domain.Flag, error) {
+	flag, err := s.repo.FindByKey(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("finding feature flag by key: %w", err)
+	}
+	return flag, nil
+}
+
+func (s *Service) Create(ctx context.Context, flag *domain.Flag) error {
+	if !domain.ValidKey(flag.Key) {
+		return domain.ErrInvalidFlagKey
+	}
+	if err := s.repo.Create(ctx, flag); err != nil {
+		return fmt.Errorf("creating feature flag: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) Update(ctx context.Context, flag *domain.Flag) error {
+	if err := s.repo.Update(ctx, flag); err != nil {
+		return fmt.Errorf("updating feature flag: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) Delete(ctx context.Context, key string) error {
+	if err := s.repo.Delete(ctx, key); err != nil {
+		return fmt.Errorf("deleting feature flag: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"english-learning/internal/modules/feature/domain"
+	"english-learning/internal/modules/feature/repository/memory"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingRepository fails every lookup, like a repository whose database is
+// down.
+type failingRepository struct {
+	domain.FlagRepository
+}
+
+func (failingRepository) List(context.Context) ([]domain.Flag, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestService_Evaluate(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	svc := NewService(memory.NewFlagRepository())
+	require.NoError(t, svc.Create(ctx, &domain.Flag{Key: "lesson.speaking", Enabled: true, Rules: []domain.Rule{{Roles: []string{"admin"}}}}))
+	require.NoError(t, svc.Create(ctx, &domain.Flag{Key: "lesson.writing", Enabled: true, Rules: []domain.Rule{{}}}))
+
+	states, err := svc.Evaluate(ctx, domain.Subject{UserID: 7, Role: "student"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"lesson.speaking": false, "lesson.writing": true}, states)
+
+	assert.True(t, svc.Enabled(ctx, "lesson.speaking", domain.Subject{UserID: 1, Role: "admin"}))
+	assert.False(t, svc.Enabled(ctx, "missing", domain.Subject{UserID: 1, Role: "admin"}))
+}
+
+func TestService_EnabledFailsClosed(t *testing.T) {
+	t.Parallel()
+	svc := NewService(failingRepository{})
+
+	assert.False(t, svc.Enabled(context.Background(), "lesson.writing", domain.Subject{UserID: 1}))
+}
+
+func TestService_CreateRejectsInvalidKey(t *testing.T) {
+	t.Parallel()
+	svc := NewService(memory.NewFlagRepository())
+
+	err := svc.Create(context.Background(), &domain.Flag{Key: "Lesson Speaking"})
+	assert.ErrorIs(t, err, domain.ErrInvalidFlagKey)
+}
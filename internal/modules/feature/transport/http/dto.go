@@ -0,0 +1,89 @@
+package http
+
+import (
+	"english-learning/internal/modules/feature/domain"
+	"time"
+)
+
+// RuleDTO is a targeting rule. Conditions left empty are ignored; a rule
+// without conditions matches everyone.
+type RuleDTO struct {
+	UserIDs       []uint   `json:"userIds,omitempty"`
+	Roles         []string `json:"roles,omitempty" binding:"omitempty,dive,oneof=student admin"`
+	MinAppVersion string   `json:"minAppVersion,omitempty" binding:"omitempty,version"`
+	Percentage    *int     `json:"percentage,omitempty" binding:"omitempty,gte=0,lte=100"`
+}
+
+type CreateFlagRequestDTO struct {
+	Key         string    `json:"key" binding:"required"`
+	Description string    `json:"description"`
+	Enabled     bool      `json:"enabled"`
+	Rules       []RuleDTO `json:"rules" binding:"dive"`
+}
+
+type UpdateFlagRequestDTO struct {
+	Description string    `json:"description"`
+	Enabled     bool      `json:"enabled"`
+	Rules       []RuleDTO `json:"rules" binding:"dive"`
+}
+
+type FlagResponseDTO struct {
+	Key         string    `json:"key"`
+	Description string    `json:"description"`
+	Enabled     bool      `json:"enabled"`
+	Rules       []RuleDTO `json:"rules"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+func toDomainRules(rules []RuleDTO) []domain.Rule {
+	result := make([]domain.Rule, len(rules))
+	for i, r := range rules {
+		result[i] = domain.Rule(r)
+	}
+	return result
+}
+
+func (r CreateFlagRequestDTO) toDomain() *domain.Flag {
+	return &domain.Flag{
+		Key:         r.Key,
+		Description: r.Description,
+		Enabled:     r.Enabled,
+		Rules:       toDomainRules(r.Rules),
+	}
+}
+
+func (r UpdateFlagRequestDTO) toDomain(key string) *domain.Flag {
+	return &domain.Flag{
+		Key:         key,
+		Description: r.Description,
+		Enabled:     r.Enabled,
+		Rules:       toDomainRules(r.Rules),
+	}
+}
+
+func ToFlagResponse(flag *domain.Flag) FlagResponseDTO {
+	if flag == nil {
+		return FlagResponseDTO{}
+	}
+	rules := make([]RuleDTO, len(flag.Rules))
+	for i, r := range flag.Rules {
+		rules[i] = RuleDTO(r)
+	}
+	return FlagResponseDTO{
+		Key:         flag.Key,
+		Description: flag.Description,
+		Enabled:     flag.Enabled,
+		Rules:       rules,
+		CreatedAt:   flag.CreatedAt,
+		UpdatedAt:   flag.UpdatedAt,
+	}
+}
+
+func ToFlagListResponse(flags []domain.Flag) []FlagResponseDTO {
+	result := make([]FlagResponseDTO, len(flags))
+	for i := range flags {
+		result[i] = ToFlagResponse(&flags[i])
+	}
+	return result
+}
@@ -0,0 +1,13 @@
+package http
+
+import (
+	"english-learning/internal/modules/feature/domain"
+	"english-learning/pkg/response"
+	"net/http"
+)
+
+func init() {
+	response.RegisterError(domain.ErrFlagNotFound, http.StatusNotFound, response.CodeFeatureFlagNotFound, response.MsgFeatureFlagNotFound)
+	response.RegisterError(domain.ErrFlagExists, http.StatusConflict, response.CodeFeatureFlagExists, response.MsgFeatureFlagExists)
+	response.RegisterError(domain.ErrInvalidFlagKey, http.StatusBadRequest, response.CodeInvalidFeatureFlagKey, response.MsgInvalidFeatureFlagKey)
+}
@@ -0,0 +1,105 @@
+package http
+
+import (
+	"english-learning/internal/modules/feature/domain"
+	"english-learning/pkg/apiversion"
+	"english-learning/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FlagHandler serves the current user's feature flags and the admin API for
+// managing them.
+type FlagHandler struct {
+	service domain.FlagService
+}
+
+// NewFlagHandler creates a new FlagHandler with the given service interface.
+func NewFlagHandler(service domain.FlagService) *FlagHandler {
+	return &FlagHandler{service: service}
+}
+
+// Subject returns who the request is evaluated for: the authenticated user
+// and the app version it reports.
+func Subject(c *gin.Context) domain.Subject {
+	return domain.Subject{
+		UserID:     c.GetUint("user_id"),
+		Role:       c.GetString("role"),
+		AppVersion: c.GetHeader(apiversion.AppVersionHeader),
+	}
+}
+
+// Evaluate serves GET /features with the state of every flag for the
+// caller, keyed by flag key.
+func (h *FlagHandler) Evaluate(c *gin.Context) {
+	states, err := h.service.Evaluate(c.Request.Context(), Subject(c))
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.Success(c, states, response.MsgSuccess)
+}
+
+func (h *FlagHandler) List(c *gin.Context) {
+	flags, err := h.service.List(c.Request.Context())
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.Success(c, ToFlagListResponse(flags), response.MsgSuccess)
+}
+
+func (h *FlagHandler) Get(c *gin.Context) {
+	flag, err := h.service.Get(c.Request.Context(), c.Param("key"))
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.Success(c, ToFlagResponse(flag), response.MsgSuccess)
+}
+
+func (h *FlagHandler) Create(c *gin.Context) {
+	var req CreateFlagRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	flag := req.toDomain()
+	if err := h.service.Create(c.Request.Context(), flag); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.Created(c, ToFlagResponse(flag), response.MsgFeatureFlagCreated)
+}
+
+// Update serves PUT /admin/features/:key, replacing the flag's description,
+// state and rules.
+func (h *FlagHandler) Update(c *gin.Context) {
+	var req UpdateFlagRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	flag := req.toDomain(c.Param("key"))
+	if err := h.service.Update(c.Request.Context(), flag); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.Success(c, ToFlagResponse(flag), response.MsgFeatureFlagUpdated)
+}
+
+func (h *FlagHandler) Delete(c *gin.Context) {
+	if err := h.service.Delete(c.Request.Context(), c.Param("key")); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.Success(c, nil, response.MsgFeatureFlagDeleted)
+}
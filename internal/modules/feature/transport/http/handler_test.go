@@ -0,0 +1,193 @@
+package http
+
+import (
+	"encoding/json"
+	"english-learning/internal/modules/feature/domain"
+	"english-learning/pkg/response"
+	"english-learning/pkg/validation"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		_ = v.RegisterValidation("version", validation.ValidateVersion)
+	}
+}
+
+// setupRouter creates a gin engine with the handler registered for testing,
+// authenticating every request as student 7.
+func setupRouter(h *FlagHandler) *gin.Engine {
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("user_id", uint(7))
+		c.Set("role", "student")
+	})
+	r.GET("/features", h.Evaluate)
+	r.POST("/admin/features", h.Create)
+	r.GET("/admin/features/:key", h.Get)
+	r.PUT("/admin/features/:key", h.Update)
+	r.DELETE("/admin/features/:key", h.Delete)
+	return r
+}
+
+func performRequest(r *gin.Engine, method, path, body string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func decodeCode(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body response.APIResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return body.Code
+}
+
+func TestEvaluateHandler_UsesCallerAndAppVersion(t *testing.T) {
+	t.Parallel()
+	mockService := new(MockFlagService)
+	router := setupRouter(NewFlagHandler(mockService))
+
+	subject := domain.Subject{UserID: 7, Role: "student", AppVersion: "2.3.1"}
+	mockService.On("Evaluate", mock.Anything, subject).
+		Return(map[string]bool{"lesson.speaking": true, "lesson.writing": false}, nil)
+
+	w := performRequest(router, http.MethodGet, "/features", "", map[string]string{"X-App-Version": "2.3.1"})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"data":{"lesson.speaking":true,"lesson.writing":false}`)
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateHandler_CreatesFlag(t *testing.T) {
+	t.Parallel()
+	mockService := new(MockFlagService)
+	router := setupRouter(NewFlagHandler(mockService))
+
+	percentage := 20
+	want := &domain.Flag{
+		Key:     "lesson.speaking",
+		Enabled: true,
+		Rules:   []domain.Rule{{Roles: []string{"admin"}}, {MinAppVersion: "2.3", Percentage: &percentage}},
+	}
+	mockService.On("Create", mock.Anything, want).Return(nil)
+
+	w := performRequest(router, http.MethodPost, "/admin/features",
+		`{"key":"lesson.speaking","enabled":true,"rules":[{"roles":["admin"]},{"minAppVersion":"2.3","percentage":20}]}`, nil)
+
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"key":"lesson.speaking"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateHandler_ValidatesRules(t *testing.T) {
+	t.Parallel()
+	for _, rule := range []string{
+		`{"roles":["teacher"]}`,
+		`{"minAppVersion":"latest"}`,
+		`{"percentage":101}`,
+		`{"percentage":-1}`,
+	} {
+		mockService := new(MockFlagService)
+		router := setupRouter(NewFlagHandler(mockService))
+
+		w := performRequest(router, http.MethodPost, "/admin/features",
+			fmt.Sprintf(`{"key":"lesson.speaking","rules":[%s]}`, rule), nil)
+
+		assert.Equalf(t, http.StatusBadRequest, w.Code, "rule %s", rule)
+		assert.Equal(t, response.CodeValidationFailed, decodeCode(t, w))
+		mockService.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	}
+}
+
+func TestHandlers_MapErrors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		setup      func(m *MockFlagService)
+		method     string
+		path, body string
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name: "invalid key",
+			setup: func(m *MockFlagService) {
+				m.On("Create", mock.Anything, mock.Anything).Return(domain.ErrInvalidFlagKey)
+			},
+			method:     http.MethodPost,
+			path:       "/admin/features",
+			body:       `{"key":"Lesson Speaking"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   response.CodeInvalidFeatureFlagKey,
+		},
+		{
+			name:       "duplicate key",
+			setup:      func(m *MockFlagService) { m.On("Create", mock.Anything, mock.Anything).Return(domain.ErrFlagExists) },
+			method:     http.MethodPost,
+			path:       "/admin/features",
+			body:       `{"key":"lesson.speaking"}`,
+			wantStatus: http.StatusConflict,
+			wantCode:   response.CodeFeatureFlagExists,
+		},
+		{
+			name:       "get missing",
+			setup:      func(m *MockFlagService) { m.On("Get", mock.Anything, "missing").Return(nil, domain.ErrFlagNotFound) },
+			method:     http.MethodGet,
+			path:       "/admin/features/missing",
+			wantStatus: http.StatusNotFound,
+			wantCode:   response.CodeFeatureFlagNotFound,
+		},
+		{
+			name:       "update missing",
+			setup:      func(m *MockFlagService) { m.On("Update", mock.Anything, mock.Anything).Return(domain.ErrFlagNotFound) },
+			method:     http.MethodPut,
+			path:       "/admin/features/missing",
+			body:       `{"enabled":true}`,
+			wantStatus: http.StatusNotFound,
+			wantCode:   response.CodeFeatureFlagNotFound,
+		},
+		{
+			name:       "delete missing",
+			setup:      func(m *MockFlagService) { m.On("Delete", mock.Anything, "missing").Return(domain.ErrFlagNotFound) },
+			method:     http.MethodDelete,
+			path:       "/admin/features/missing",
+			wantStatus: http.StatusNotFound,
+			wantCode:   response.CodeFeatureFlagNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockService := new(MockFlagService)
+			tt.setup(mockService)
+			router := setupRouter(NewFlagHandler(mockService))
+
+			w := performRequest(router, tt.method, tt.path, tt.body, nil)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantCode, decodeCode(t, w))
+			mockService.AssertExpectations(t)
+		})
+	}
+}
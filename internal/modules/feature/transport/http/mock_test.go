@@ -0,0 +1,54 @@
+package http
+
+import (
+	"context"
+	"english-learning/internal/modules/feature/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockFlagService is a mock implementation of domain.FlagService.
+type MockFlagService struct {
+	mock.Mock
+}
+
+func (m *MockFlagService) Enabled(ctx context.Context, key string, s domain.Subject) bool {
+	args := m.Called(ctx, key, s)
+	return args.Bool(0)
+}
+
+func (m *MockFlagService) Evaluate(ctx context.Context, s domain.Subject) (map[string]bool, error) {
+	args := m.Called(ctx, s)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]bool), args.Error(1)
+}
+
+func (m *MockFlagService) List(ctx context.Context) ([]domain.Flag, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.Flag), args.Error(1)
+}
+
+func (m *MockFlagService) Get(ctx context.Context, key string) (*domain.Flag, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Flag), args.Error(1)
+}
+
+func (m *MockFlagService) Create(ctx context.Context, flag *domain.Flag) error {
+	args := m.Called(ctx, flag)
+	return args.Error(0)
+}
+
+func (m *MockFlagService) Update(ctx context.Context, flag *domain.Flag) error {
+	args := m.Called(ctx, flag)
+	return args.Error(0)
+}
+
+func (m *MockFlagService) Delete(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
@@ -0,0 +1,26 @@
+package route
+
+import (
+	"english-learning/configs"
+	handler "english-learning/internal/modules/feature/transport/http"
+	userDomain "english-learning/internal/modules/user/domain"
+	"english-learning/pkg/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Register registers the feature flag routes on the given router: the
+// caller's flags and the admin API.
+func Register(r gin.IRouter, cfg *configs.Config, h *handler.FlagHandler) {
+	r.GET("/features", middleware.AuthMiddleware(cfg.JWT), h.Evaluate)
+
+	admin := r.Group("/admin/features")
+	admin.Use(middleware.AuthMiddleware(cfg.JWT), middleware.RequireRole(userDomain.RoleAdmin))
+	{
+		admin.GET("", h.List)
+		admin.POST("", h.Create)
+		admin.GET("/:key", h.Get)
+		admin.PUT("/:key", h.Update)
+		admin.DELETE("/:key", h.Delete)
+	}
+}
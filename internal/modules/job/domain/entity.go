@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Job states. A job is pending until a worker claims it, running while its
+// handler executes, and ends either succeeded or, after exhausting its
+// attempts, dead.
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusDead      = "dead"
+)
+
+type Job struct {
+	ID          uint64
+	Kind        string
+	Payload     json.RawMessage
+	Status      string
+	Attempts    int
+	MaxAttempts int
+	// RunAt is when the job becomes due; for a failed job, when it is retried.
+	RunAt     time.Time
+	LastError string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// FinishedAt is set once the job has succeeded or been dead-lettered.
+	FinishedAt *time.Time
+}
+
+// Filter narrows a job listing. Empty fields match everything.
+type Filter struct {
+	Status string
+	Kind   string
+}
@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrJobNotFound     = errors.New("job not found")
+	ErrJobNotRetryable = errors.New("only dead jobs can be retried")
+)
+
+// JobRepository is the admin view of the job queue. Enqueuing and running
+// jobs is done by internal/jobqueue.
+type JobRepository interface {
+	List(ctx context.Context, filter Filter, offset, limit int) ([]Job, int64, error)
+	FindByID(ctx context.Context, id uint64) (*Job, error)
+	// Retry makes a dead job pending again with a fresh set of attempts. It
+	// fails with ErrJobNotRetryable for jobs in any other state.
+	Retry(ctx context.Context, id uint64) error
+}
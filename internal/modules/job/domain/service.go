@@ -0,0 +1,10 @@
+package domain
+
+import "context"
+
+// JobService defines the admin operations on background jobs.
+type JobService interface {
+	List(ctx context.Context, filter Filter, page, pageSize int) ([]Job, int64, error)
+	Get(ctx context.Context, id uint64) (*Job, error)
+	Retry(ctx context.Context, id uint64) error
+}
@@ -0,0 +1,102 @@
+// Package memory implements domain.JobRepository in memory, for fast tests.
+// Nothing runs the jobs it holds; Add stands in for the queue.
+package memory
+
+import (
+	"context"
+	"english-learning/internal/modules/job/domain"
+	"sort"
+	"sync"
+	"time"
+)
+
+type JobRepository struct {
+	now func() time.Time
+
+	mu     sync.Mutex
+	nextID uint64
+	jobs   map[uint64]*domain.Job
+}
+
+func NewJobRepository() *JobRepository {
+	return &JobRepository{now: time.Now, jobs: make(map[uint64]*domain.Job)}
+}
+
+func clone(j *domain.Job) *domain.Job {
+	c := *j
+	c.Payload = append([]byte(nil), j.Payload...)
+	if j.FinishedAt != nil {
+		finishedAt := *j.FinishedAt
+		c.FinishedAt = &finishedAt
+	}
+	return &c
+}
+
+// Add stores job as is, apart from a new ID and timestamps, and returns the
+// ID.
+func (r *JobRepository) Add(job domain.Job) uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	now := r.now()
+	job.ID = r.nextID
+	job.CreatedAt = now
+	job.UpdatedAt = now
+	r.jobs[job.ID] = clone(&job)
+	return job.ID
+}
+
+func (r *JobRepository) List(_ context.Context, filter domain.Filter, offset, limit int) ([]domain.Job, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var jobs []domain.Job
+	for _, j := range r.jobs {
+		if (filter.Status == "" || j.Status == filter.Status) && (filter.Kind == "" || j.Kind == filter.Kind) {
+			jobs = append(jobs, *clone(j))
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].ID > jobs[j].ID })
+
+	count := int64(len(jobs))
+	if offset > len(jobs) {
+		offset = len(jobs)
+	}
+	jobs = jobs[offset:]
+	if limit >= 0 && limit < len(jobs) {
+		jobs = jobs[:limit]
+	}
+	return jobs, count, nil
+}
+
+func (r *JobRepository) FindByID(_ context.Context, id uint64) (*domain.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	j, ok := r.jobs[id]
+	if !ok {
+		return nil, domain.ErrJobNotFound
+	}
+	return clone(j), nil
+}
+
+func (r *JobRepository) Retry(_ context.Context, id uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	j, ok := r.jobs[id]
+	if !ok {
+		return domain.ErrJobNotFound
+	}
+	if j.Status != domain.StatusDead {
+		return domain.ErrJobNotRetryable
+	}
+	now := r.now()
+	j.Status = domain.StatusPending
+	j.Attempts = 0
+	j.RunAt = now
+	j.UpdatedAt = now
+	j.FinishedAt = nil
+	return nil
+}
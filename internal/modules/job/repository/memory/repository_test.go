@@ -0,0 +1,18 @@
+package memory
+
+import (
+	"english-learning/internal/modules/job/domain"
+	"english-learning/internal/modules/job/repository/repotest"
+	"testing"
+)
+
+func TestJobRepository_Contract(t *testing.T) {
+	t.Parallel()
+	repotest.Run(t, func(*testing.T) repotest.Fixture {
+		repo := NewJobRepository()
+		return repotest.Fixture{
+			Repo: repo,
+			Seed: func(_ *testing.T, job domain.Job) uint64 { return repo.Add(job) },
+		}
+	})
+}
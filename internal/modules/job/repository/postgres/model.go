@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"encoding/json"
+	"english-learning/internal/modules/job/domain"
+	"time"
+)
+
+// Job is a row of jobs. It is shared with internal/jobqueue, which enqueues
+// and runs the jobs.
+type Job struct {
+	ID          uint64          `gorm:"primaryKey"`
+	Kind        string          `gorm:"type:varchar(100);not null"`
+	Payload     json.RawMessage `gorm:"type:jsonb;not null"`
+	Status      string          `gorm:"type:varchar(20);not null;default:pending;index:idx_jobs_status_run_at,priority:1"`
+	Attempts    int             `gorm:"not null;default:0"`
+	MaxAttempts int             `gorm:"not null"`
+	RunAt       time.Time       `gorm:"type:timestamp with time zone;not null;index:idx_jobs_status_run_at,priority:2"`
+	// LockedUntil is the lease of the worker running the job.
+	LockedUntil *time.Time `gorm:"type:timestamp with time zone"`
+	LastError   *string    `gorm:"type:text"`
+	CreatedAt   time.Time  `gorm:"type:timestamp with time zone;autoCreateTime"`
+	UpdatedAt   time.Time  `gorm:"type:timestamp with time zone;autoUpdateTime"`
+	FinishedAt  *time.Time `gorm:"type:timestamp with time zone"`
+}
+
+func (Job) TableName() string {
+	return "jobs"
+}
+
+// Schedule is a row of job_schedules, the state of one recurring schedule.
+type Schedule struct {
+	Name      string     `gorm:"type:varchar(100);primaryKey"`
+	Spec      string     `gorm:"type:varchar(100);not null"`
+	NextRunAt time.Time  `gorm:"type:timestamp with time zone;not null"`
+	LastRunAt *time.Time `gorm:"type:timestamp with time zone"`
+}
+
+func (Schedule) TableName() string {
+	return "job_schedules"
+}
+
+func (m *Job) ToDomain() *domain.Job {
+	if m == nil {
+		return nil
+	}
+	job := &domain.Job{
+		ID:          m.ID,
+		Kind:        m.Kind,
+		Payload:     m.Payload,
+		Status:      m.Status,
+		Attempts:    m.Attempts,
+		MaxAttempts: m.MaxAttempts,
+		RunAt:       m.RunAt,
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+		FinishedAt:  m.FinishedAt,
+	}
+	if m.LastError != nil {
+		job.LastError = *m.LastError
+	}
+	return job
+}
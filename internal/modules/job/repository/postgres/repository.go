@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+	"english-learning/internal/database"
+	"english-learning/internal/modules/job/domain"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type JobRepository struct {
+	db *gorm.DB
+}
+
+func NewJobRepository(db *gorm.DB) domain.JobRepository {
+	return &JobRepository{db: db}
+}
+
+// conn joins the caller's unit of work, if any.
+func (r *JobRepository) conn(ctx context.Context) *gorm.DB {
+	return database.Conn(ctx, r.db)
+}
+
+func (r *JobRepository) List(ctx context.Context, filter domain.Filter, offset, limit int) ([]domain.Job, int64, error) {
+	var jobModels []Job
+	var count int64
+
+	query := r.conn(ctx).Model(&Job{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Kind != "" {
+		query = query.Where("kind = ?", filter.Kind)
+	}
+
+	if err := query.Session(&gorm.Session{}).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&jobModels).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	jobs := make([]domain.Job, len(jobModels))
+	for i, model := range jobModels {
+		jobs[i] = *model.ToDomain()
+	}
+
+	return jobs, count, nil
+}
+
+func (r *JobRepository) FindByID(ctx context.Context, id uint64) (*domain.Job, error) {
+	var jobModel Job
+	err := r.conn(ctx).First(&jobModel, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrJobNotFound
+		}
+		return nil, err
+	}
+	return jobModel.ToDomain(), nil
+}
+
+func (r *JobRepository) Retry(ctx context.Context, id uint64) error {
+	result := r.conn(ctx).Model(&Job{}).
+		Where("id = ? AND status = ?", id, domain.StatusDead).
+		Updates(map[string]interface{}{
+			"status":       domain.StatusPending,
+			"attempts":     0,
+			"run_at":       time.Now(),
+			"locked_until": nil,
+			"finished_at":  nil,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		// Distinguish a missing job from one in the wrong state.
+		if _, err := r.FindByID(ctx, id); err != nil {
+			return err
+		}
+		return domain.ErrJobNotRetryable
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package postgres
+
+import (
+	"english-learning/internal/database/dbtest"
+	"english-learning/internal/modules/job/domain"
+	"english-learning/internal/modules/job/repository/repotest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobRepository_Contract(t *testing.T) {
+	db := dbtest.Open(t)
+	repotest.Run(t, func(t *testing.T) repotest.Fixture {
+		dbtest.Truncate(t, db, "jobs")
+		return repotest.Fixture{
+			Repo: NewJobRepository(db),
+			Seed: func(t *testing.T, job domain.Job) uint64 {
+				model := &Job{
+					Kind:        job.Kind,
+					Payload:     job.Payload,
+					Status:      job.Status,
+					Attempts:    job.Attempts,
+					MaxAttempts: job.MaxAttempts,
+					RunAt:       job.RunAt,
+					FinishedAt:  job.FinishedAt,
+				}
+				if job.LastError != "" {
+					model.LastError = &job.LastError
+				}
+				require.NoError(t, db.Create(model).Error)
+				return model.ID
+			},
+		}
+	})
+}
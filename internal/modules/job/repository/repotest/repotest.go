@@ -0,0 +1,140 @@
+// Package repotest is the contract every domain.JobRepository must honour.
+// Each implementation runs it from its own tests; the Postgres run needs
+// TEST_DATABASE_DSN.
+package repotest
+
+import (
+	"context"
+	"encoding/json"
+	"english-learning/internal/modules/job/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Fixture is one empty repository under test. Seed stores a job with the
+// given fields and returns its ID.
+type Fixture struct {
+	Repo domain.JobRepository
+	Seed func(t *testing.T, job domain.Job) uint64
+}
+
+// Run checks fixtures returned by newFixture. Subtests run sequentially so
+// they may share a database.
+func Run(t *testing.T, newFixture func(t *testing.T) Fixture) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, f Fixture)
+	}{
+		{"FindByID", testFindByID},
+		{"List", testList},
+		{"Retry", testRetry},
+		{"RetryRejectsLiveJobs", testRetryRejectsLiveJobs},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newFixture(t))
+		})
+	}
+}
+
+func job(kind, status string) domain.Job {
+	j := domain.Job{
+		Kind:        kind,
+		Payload:     json.RawMessage(`{"name":"Lan"}`),
+		Status:      status,
+		MaxAttempts: 3,
+		RunAt:       time.Now().Truncate(time.Second),
+	}
+	if status == domain.StatusDead || status == domain.StatusSucceeded {
+		j.Attempts = 3
+		finishedAt := time.Now().Truncate(time.Second)
+		j.FinishedAt = &finishedAt
+	}
+	if status == domain.StatusDead {
+		j.LastError = "boom"
+	}
+	return j
+}
+
+func testFindByID(t *testing.T, f Fixture) {
+	ctx := context.Background()
+	want := job("test.greet", domain.StatusDead)
+	id := f.Seed(t, want)
+
+	found, err := f.Repo.FindByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, id, found.ID)
+	assert.Equal(t, "test.greet", found.Kind)
+	assert.JSONEq(t, `{"name":"Lan"}`, string(found.Payload))
+	assert.Equal(t, domain.StatusDead, found.Status)
+	assert.Equal(t, 3, found.Attempts)
+	assert.Equal(t, 3, found.MaxAttempts)
+	assert.True(t, want.RunAt.Equal(found.RunAt))
+	assert.Equal(t, "boom", found.LastError)
+	require.NotNil(t, found.FinishedAt)
+
+	_, err = f.Repo.FindByID(ctx, 999)
+	assert.ErrorIs(t, err, domain.ErrJobNotFound)
+}
+
+func testList(t *testing.T, f Fixture) {
+	ctx := context.Background()
+	first := f.Seed(t, job("test.greet", domain.StatusPending))
+	second := f.Seed(t, job("test.greet", domain.StatusDead))
+	third := f.Seed(t, job("test.other", domain.StatusDead))
+
+	ids := func(jobs []domain.Job) []uint64 {
+		out := make([]uint64, len(jobs))
+		for i, j := range jobs {
+			out[i] = j.ID
+		}
+		return out
+	}
+
+	jobs, count, err := f.Repo.List(ctx, domain.Filter{}, 0, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, count)
+	assert.Equal(t, []uint64{third, second, first}, ids(jobs), "newest first")
+
+	jobs, count, err = f.Repo.List(ctx, domain.Filter{Status: domain.StatusDead}, 0, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+	assert.Equal(t, []uint64{third, second}, ids(jobs))
+
+	jobs, count, err = f.Repo.List(ctx, domain.Filter{Status: domain.StatusDead, Kind: "test.greet"}, 0, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	assert.Equal(t, []uint64{second}, ids(jobs))
+
+	jobs, count, err = f.Repo.List(ctx, domain.Filter{}, 1, 1)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, count, "count ignores the page")
+	assert.Equal(t, []uint64{second}, ids(jobs))
+}
+
+func testRetry(t *testing.T, f Fixture) {
+	ctx := context.Background()
+	id := f.Seed(t, job("test.greet", domain.StatusDead))
+
+	require.NoError(t, f.Repo.Retry(ctx, id))
+
+	found, err := f.Repo.FindByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusPending, found.Status)
+	assert.Zero(t, found.Attempts)
+	assert.Nil(t, found.FinishedAt)
+	assert.WithinDuration(t, time.Now(), found.RunAt, time.Minute)
+
+	assert.ErrorIs(t, f.Repo.Retry(ctx, 999), domain.ErrJobNotFound)
+}
+
+func testRetryRejectsLiveJobs(t *testing.T, f Fixture) {
+	ctx := context.Background()
+	for _, status := range []string{domain.StatusPending, domain.StatusRunning, domain.StatusSucceeded} {
+		id := f.Seed(t, job("test.greet", status))
+		assert.ErrorIsf(t, f.Repo.Retry(ctx, id), domain.ErrJobNotRetryable, "status %s", status)
+	}
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"english-learning/internal/modules/job/domain"
+	"fmt"
+)
+
+// Service implements domain.JobService.
+type Service struct {
+	repo domain.JobRepository
+}
+
+// NewService creates a new job Service.
+func NewService(repo domain.JobRepository) *Service {
+	return &Service{repo: repo}
+}
+
+func (s *Service) List(ctx context.Context, filter domain.Filter, page, pageSize int) ([]domain.Job, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	offset := (page - 1) * pageSize
+
+	jobs, count, err := s.repo.List(ctx, filter, offset, pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("listing jobs: %w", err)
+	}
+
+	return jobs, count, nil
+}
+
+func (s *Service) Get(ctx context.Context, id uint64) (*domain.Job, error) {
+	job, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("finding job by id: %w", err)
+	}
+
+	return job, nil
+}
+
+func (s *Service) Retry(ctx context.Context, id uint64) error {
+	if err := s.repo.Retry(ctx, id); err != nil {
+		return fmt.Errorf("retrying job: %w", err)
+	}
+	return nil
+}
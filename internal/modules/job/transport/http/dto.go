@@ -0,0 +1,55 @@
+package http
+
+import (
+	"encoding/json"
+	"english-learning/internal/modules/job/domain"
+	"time"
+)
+
+type ListJobsQueryDTO struct {
+	Status   string `form:"status" binding:"omitempty,oneof=pending running succeeded dead"`
+	Kind     string `form:"kind"`
+	Page     int    `form:"page"`
+	PageSize int    `form:"page_size" binding:"omitempty,lte=100"`
+}
+
+type JobResponseDTO struct {
+	ID          uint64          `json:"id"`
+	Kind        string          `json:"kind"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"maxAttempts"`
+	RunAt       time.Time       `json:"runAt"`
+	LastError   string          `json:"lastError,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+	FinishedAt  *time.Time      `json:"finishedAt,omitempty"`
+}
+
+func ToJobResponse(job *domain.Job) JobResponseDTO {
+	if job == nil {
+		return JobResponseDTO{}
+	}
+	return JobResponseDTO{
+		ID:          job.ID,
+		Kind:        job.Kind,
+		Payload:     job.Payload,
+		Status:      job.Status,
+		Attempts:    job.Attempts,
+		MaxAttempts: job.MaxAttempts,
+		RunAt:       job.RunAt,
+		LastError:   job.LastError,
+		CreatedAt:   job.CreatedAt,
+		UpdatedAt:   job.UpdatedAt,
+		FinishedAt:  job.FinishedAt,
+	}
+}
+
+func ToJobListResponse(jobs []domain.Job) []JobResponseDTO {
+	dtos := make([]JobResponseDTO, len(jobs))
+	for i, job := range jobs {
+		dtos[i] = ToJobResponse(&job)
+	}
+	return dtos
+}
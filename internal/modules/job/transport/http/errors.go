@@ -0,0 +1,12 @@
+package http
+
+import (
+	"english-learning/internal/modules/job/domain"
+	"english-learning/pkg/response"
+	"net/http"
+)
+
+func init() {
+	response.RegisterError(domain.ErrJobNotFound, http.StatusNotFound, response.CodeJobNotFound, response.MsgJobNotFound)
+	response.RegisterError(domain.ErrJobNotRetryable, http.StatusConflict, response.CodeJobNotRetryable, response.MsgJobNotRetryable)
+}
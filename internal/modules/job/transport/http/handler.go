@@ -0,0 +1,74 @@
+package http
+
+import (
+	"english-learning/internal/modules/job/domain"
+	"english-learning/pkg/response"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JobHandler serves the admin API for inspecting and retrying background jobs.
+type JobHandler struct {
+	service domain.JobService
+}
+
+// NewJobHandler creates a new JobHandler with the given service interface.
+func NewJobHandler(service domain.JobService) *JobHandler {
+	return &JobHandler{service: service}
+}
+
+func (h *JobHandler) List(c *gin.Context) {
+	var query ListJobsQueryDTO
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.BindError(c, err)
+		return
+	}
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.PageSize < 1 {
+		query.PageSize = 10
+	}
+
+	filter := domain.Filter{Status: query.Status, Kind: query.Kind}
+	jobs, count, err := h.service.List(c.Request.Context(), filter, query.Page, query.PageSize)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.SuccessList(c, ToJobListResponse(jobs), count, query.Page, query.PageSize, response.MsgSuccess)
+}
+
+func (h *JobHandler) Get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, response.CodeBadRequest, response.MsgInvalidID)
+		return
+	}
+
+	job, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.Success(c, ToJobResponse(job), response.MsgSuccess)
+}
+
+func (h *JobHandler) Retry(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, response.CodeBadRequest, response.MsgInvalidID)
+		return
+	}
+
+	if err := h.service.Retry(c.Request.Context(), id); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.Success(c, nil, response.MsgJobRetried)
+}
@@ -0,0 +1,132 @@
+package http
+
+import (
+	"encoding/json"
+	"english-learning/internal/modules/job/domain"
+	"english-learning/pkg/response"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// setupRouter creates a gin engine with the handler registered for testing.
+func setupRouter(h *JobHandler) *gin.Engine {
+	r := gin.New()
+	r.GET("/admin/jobs", h.List)
+	r.GET("/admin/jobs/:id", h.Get)
+	r.POST("/admin/jobs/:id/retry", h.Retry)
+	return r
+}
+
+func performRequest(r *gin.Engine, method, path string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func decodeCode(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body response.APIResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return body.Code
+}
+
+func TestListHandler_FiltersAndPaginates(t *testing.T) {
+	t.Parallel()
+	mockService := new(MockJobService)
+	router := setupRouter(NewJobHandler(mockService))
+
+	jobs := []domain.Job{{ID: 3, Kind: "session.purge_expired", Status: domain.StatusDead}}
+	mockService.On("List", mock.Anything, domain.Filter{Status: domain.StatusDead, Kind: "session.purge_expired"}, 2, 5).
+		Return(jobs, int64(6), nil)
+
+	w := performRequest(router, "GET", "/admin/jobs?status=dead&kind=session.purge_expired&page=2&page_size=5")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"kind":"session.purge_expired"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestListHandler_InvalidStatus(t *testing.T) {
+	t.Parallel()
+	mockService := new(MockJobService)
+	router := setupRouter(NewJobHandler(mockService))
+
+	w := performRequest(router, "GET", "/admin/jobs?status=bogus")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "List", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestListHandler_PageSizeTooLarge(t *testing.T) {
+	t.Parallel()
+	mockService := new(MockJobService)
+	router := setupRouter(NewJobHandler(mockService))
+
+	w := performRequest(router, "GET", "/admin/jobs?page_size=101")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, response.CodeValidationFailed, decodeCode(t, w))
+	mockService.AssertNotCalled(t, "List", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetHandler_NotFound(t *testing.T) {
+	t.Parallel()
+	mockService := new(MockJobService)
+	router := setupRouter(NewJobHandler(mockService))
+
+	mockService.On("Get", mock.Anything, uint64(42)).Return(nil, fmt.Errorf("finding job by id: %w", domain.ErrJobNotFound))
+
+	w := performRequest(router, "GET", "/admin/jobs/42")
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, response.CodeJobNotFound, decodeCode(t, w))
+}
+
+func TestGetHandler_InvalidID(t *testing.T) {
+	t.Parallel()
+	mockService := new(MockJobService)
+	router := setupRouter(NewJobHandler(mockService))
+
+	w := performRequest(router, "GET", "/admin/jobs/abc")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+}
+
+func TestRetryHandler_Success(t *testing.T) {
+	t.Parallel()
+	mockService := new(MockJobService)
+	router := setupRouter(NewJobHandler(mockService))
+
+	mockService.On("Retry", mock.Anything, uint64(7)).Return(nil)
+
+	w := performRequest(router, "POST", "/admin/jobs/7/retry")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestRetryHandler_NotDead(t *testing.T) {
+	t.Parallel()
+	mockService := new(MockJobService)
+	router := setupRouter(NewJobHandler(mockService))
+
+	mockService.On("Retry", mock.Anything, uint64(7)).Return(fmt.Errorf("retrying job: %w", domain.ErrJobNotRetryable))
+
+	w := performRequest(router, "POST", "/admin/jobs/7/retry")
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, response.CodeJobNotRetryable, decodeCode(t, w))
+}
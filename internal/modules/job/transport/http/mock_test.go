@@ -0,0 +1,31 @@
+package http
+
+import (
+	"context"
+	"english-learning/internal/modules/job/domain"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockJobService is a mock implementation of domain.JobService.
+type MockJobService struct {
+	mock.Mock
+}
+
+func (m *MockJobService) List(ctx context.Context, filter domain.Filter, page, pageSize int) ([]domain.Job, int64, error) {
+	args := m.Called(ctx, filter, page, pageSize)
+	return args.Get(0).([]domain.Job), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockJobService) Get(ctx context.Context, id uint64) (*domain.Job, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Job), args.Error(1)
+}
+
+func (m *MockJobService) Retry(ctx context.Context, id uint64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
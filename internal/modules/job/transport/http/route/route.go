@@ -0,0 +1,21 @@
+package route
+
+import (
+	"english-learning/configs"
+	handler "english-learning/internal/modules/job/transport/http"
+	userDomain "english-learning/internal/modules/user/domain"
+	"english-learning/pkg/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Register registers the admin job routes on the given router.
+func Register(r gin.IRouter, cfg *configs.Config, h *handler.JobHandler) {
+	group := r.Group("/admin/jobs")
+	group.Use(middleware.AuthMiddleware(cfg.JWT), middleware.RequireRole(userDomain.RoleAdmin))
+	{
+		group.GET("", h.List)
+		group.GET("/:id", h.Get)
+		group.POST("/:id/retry", h.Retry)
+	}
+}
@@ -0,0 +1,7 @@
+package domain
+
+// PurgeExpiredSessions deletes sessions whose refresh token has expired. It
+// runs on a schedule; the kind is persisted, so never rename it.
+type PurgeExpiredSessions struct{}
+
+func (PurgeExpiredSessions) JobKind() string { return "session.purge_expired" }
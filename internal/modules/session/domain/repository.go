@@ -1,10 +1,27 @@
 package domain
 
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
 type SessionRepository interface {
-	Create(session *Session) error
-	FindByID(id uint) (*Session, error)
-	FindByRefreshToken(refreshToken string) (*Session, error)
-	Revoke(id uint) error
-	RevokeAllForUser(userID uint) error
-	Delete(id uint) error
+	Create(ctx context.Context, session *Session) error
+	FindByID(ctx context.Context, id uint) (*Session, error)
+	FindByRefreshToken(ctx context.Context, refreshToken string) (*Session, error)
+	// Revoke marks an active session as revoked. It returns ErrSessionNotFound
+	// when no unrevoked session has that ID, so of two concurrent rotations of
+	// the same refresh token only one succeeds.
+	Revoke(ctx context.Context, id uint) error
+	RevokeAllForUser(ctx context.Context, userID uint) error
+	Delete(ctx context.Context, id uint) error
+	// ListByUser returns the user's sessions, newest first, including revoked
+	// and expired ones.
+	ListByUser(ctx context.Context, userID uint) ([]Session, error)
+	// DeleteExpired removes sessions that expired before the given time and
+	// returns how many were deleted.
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
 }
@@ -0,0 +1,132 @@
+// Package memory implements domain.SessionRepository in memory, for fast
+// tests. It behaves like the Postgres repository, as checked by the repotest
+// contract suite, except that it does not check that users exist and does not
+// take part in units of work.
+package memory
+
+import (
+	"context"
+	"english-learning/internal/modules/session/domain"
+	"sort"
+	"sync"
+	"time"
+)
+
+type SessionRepository struct {
+	now func() time.Time
+
+	mu       sync.Mutex
+	nextID   uint
+	sessions map[uint]*domain.Session
+}
+
+func NewSessionRepository() *SessionRepository {
+	return &SessionRepository{now: time.Now, sessions: make(map[uint]*domain.Session)}
+}
+
+func (r *SessionRepository) Create(_ context.Context, session *domain.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	now := r.now()
+	session.ID = r.nextID
+	session.CreatedAt = now
+	session.UpdatedAt = now
+	stored := *session
+	r.sessions[session.ID] = &stored
+	return nil
+}
+
+func (r *SessionRepository) FindByID(_ context.Context, id uint) (*domain.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.sessions[id]
+	if !ok {
+		return nil, domain.ErrSessionNotFound
+	}
+	found := *s
+	return &found, nil
+}
+
+func (r *SessionRepository) FindByRefreshToken(_ context.Context, refreshToken string) (*domain.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, s := range r.sessions {
+		if s.RefreshToken == refreshToken {
+			found := *s
+			return &found, nil
+		}
+	}
+	return nil, domain.ErrSessionNotFound
+}
+
+func (r *SessionRepository) Revoke(_ context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.sessions[id]
+	if !ok || s.IsRevoked {
+		return domain.ErrSessionNotFound
+	}
+	s.IsRevoked = true
+	s.UpdatedAt = r.now()
+	return nil
+}
+
+func (r *SessionRepository) RevokeAllForUser(_ context.Context, userID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	for _, s := range r.sessions {
+		if s.UserID == userID {
+			s.IsRevoked = true
+			s.UpdatedAt = now
+		}
+	}
+	return nil
+}
+
+func (r *SessionRepository) Delete(_ context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.sessions, id)
+	return nil
+}
+
+func (r *SessionRepository) ListByUser(_ context.Context, userID uint) ([]domain.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sessions := []domain.Session{}
+	for _, s := range r.sessions {
+		if s.UserID == userID {
+			sessions = append(sessions, *s)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		if !sessions[i].CreatedAt.Equal(sessions[j].CreatedAt) {
+			return sessions[i].CreatedAt.After(sessions[j].CreatedAt)
+		}
+		return sessions[i].ID > sessions[j].ID
+	})
+	return sessions, nil
+}
+
+func (r *SessionRepository) DeleteExpired(_ context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for id, s := range r.sessions {
+		if s.ExpiresAt.Before(before) {
+			delete(r.sessions, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
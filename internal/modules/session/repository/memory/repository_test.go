@@ -0,0 +1,20 @@
+package memory
+
+import (
+	"english-learning/internal/modules/session/repository/repotest"
+	"testing"
+)
+
+func TestSessionRepository_Contract(t *testing.T) {
+	t.Parallel()
+	repotest.Run(t, func(*testing.T) repotest.Fixture {
+		var lastUserID uint
+		return repotest.Fixture{
+			Repo: NewSessionRepository(),
+			NewUser: func(*testing.T) uint {
+				lastUserID++
+				return lastUserID
+			},
+		}
+	})
+}
@@ -9,7 +9,7 @@ import (
 type Session struct {
 	ID           uint      `gorm:"primaryKey"`
 	UserID       uint      `gorm:"not null;index"`
-	RefreshToken string    `gorm:"type:text;not null"`
+	RefreshToken string    `gorm:"type:text;not null;index"`
 	UserAgent    string    `gorm:"type:text"`
 	ClientIP     string    `gorm:"type:varchar(45)"`
 	IsRevoked    bool      `gorm:"not null;default:false"`
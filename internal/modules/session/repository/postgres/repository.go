@@ -1,7 +1,11 @@
 package postgres
 
 import (
+	"context"
+	"english-learning/internal/database"
 	"english-learning/internal/modules/session/domain"
+	"errors"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -14,9 +18,14 @@ func NewSessionRepository(db *gorm.DB) domain.SessionRepository {
 	return &SessionRepository{db: db}
 }
 
-func (r *SessionRepository) Create(session *domain.Session) error {
+// conn joins the caller's unit of work, if any.
+func (r *SessionRepository) conn(ctx context.Context) *gorm.DB {
+	return database.Conn(ctx, r.db)
+}
+
+func (r *SessionRepository) Create(ctx context.Context, session *domain.Session) error {
 	sessionModel := FromDomainSession(session)
-	if err := r.db.Create(sessionModel).Error; err != nil {
+	if err := r.conn(ctx).Create(sessionModel).Error; err != nil {
 		return err
 	}
 	session.ID = sessionModel.ID
@@ -25,32 +34,63 @@ func (r *SessionRepository) Create(session *domain.Session) error {
 	return nil
 }
 
-func (r *SessionRepository) FindByID(id uint) (*domain.Session, error) {
+func (r *SessionRepository) FindByID(ctx context.Context, id uint) (*domain.Session, error) {
 	var sessionModel Session
-	err := r.db.First(&sessionModel, id).Error
+	err := r.conn(ctx).First(&sessionModel, id).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrSessionNotFound
+		}
 		return nil, err
 	}
 	return sessionModel.ToDomain(), nil
 }
 
-func (r *SessionRepository) FindByRefreshToken(refreshToken string) (*domain.Session, error) {
+func (r *SessionRepository) FindByRefreshToken(ctx context.Context, refreshToken string) (*domain.Session, error) {
 	var sessionModel Session
-	err := r.db.Where("refresh_token = ?", refreshToken).First(&sessionModel).Error
+	err := r.conn(ctx).Where("refresh_token = ?", refreshToken).First(&sessionModel).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrSessionNotFound
+		}
 		return nil, err
 	}
 	return sessionModel.ToDomain(), nil
 }
 
-func (r *SessionRepository) Revoke(id uint) error {
-	return r.db.Model(&Session{}).Where("id = ?", id).Update("is_revoked", true).Error
+func (r *SessionRepository) Revoke(ctx context.Context, id uint) error {
+	result := r.conn(ctx).Model(&Session{}).Where("id = ? AND is_revoked = ?", id, false).Update("is_revoked", true)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrSessionNotFound
+	}
+	return nil
+}
+
+func (r *SessionRepository) RevokeAllForUser(ctx context.Context, userID uint) error {
+	return r.conn(ctx).Model(&Session{}).Where("user_id = ?", userID).Update("is_revoked", true).Error
 }
 
-func (r *SessionRepository) RevokeAllForUser(userID uint) error {
-	return r.db.Model(&Session{}).Where("user_id = ?", userID).Update("is_revoked", true).Error
+func (r *SessionRepository) Delete(ctx context.Context, id uint) error {
+	return r.conn(ctx).Delete(&Session{}, id).Error
+}
+
+func (r *SessionRepository) ListByUser(ctx context.Context, userID uint) ([]domain.Session, error) {
+	var sessionModels []Session
+	if err := r.conn(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&sessionModels).Error; err != nil {
+		return nil, err
+	}
+
+	sessions := make([]domain.Session, len(sessionModels))
+	for i, m := range sessionModels {
+		sessions[i] = *m.ToDomain()
+	}
+	return sessions, nil
 }
 
-func (r *SessionRepository) Delete(id uint) error {
-	return r.db.Delete(&Session{}, id).Error
+func (r *SessionRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result := r.conn(ctx).Where("expires_at < ?", before).Delete(&Session{})
+	return result.RowsAffected, result.Error
 }
@@ -0,0 +1,36 @@
+package postgres
+
+import (
+	"context"
+	"english-learning/internal/database/dbtest"
+	"english-learning/internal/modules/session/repository/repotest"
+	userDomain "english-learning/internal/modules/user/domain"
+	userPostgres "english-learning/internal/modules/user/repository/postgres"
+	"english-learning/pkg/encryption/encryptiontest"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionRepository_Contract(t *testing.T) {
+	db := dbtest.Open(t)
+	repotest.Run(t, func(t *testing.T) repotest.Fixture {
+		dbtest.Truncate(t, db, "users", "sessions")
+		users := userPostgres.NewUserRepository(db, encryptiontest.New(t, encryptiontest.KeyIDs[0]))
+		var created int
+		return repotest.Fixture{
+			Repo: NewSessionRepository(db),
+			NewUser: func(t *testing.T) uint {
+				created++
+				user := &userDomain.User{
+					Email:    fmt.Sprintf("user%d@example.com", created),
+					Password: "hashed",
+					Role:     userDomain.RoleStudent,
+				}
+				require.NoError(t, users.Create(context.Background(), user))
+				return user.ID
+			},
+		}
+	})
+}
@@ -0,0 +1,163 @@
+// Package repotest is the contract every domain.SessionRepository must
+// honour. Each implementation runs it from its own tests; the Postgres run
+// needs TEST_DATABASE_DSN.
+package repotest
+
+import (
+	"context"
+	"english-learning/internal/modules/session/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Fixture is one empty repository under test. NewUser returns the ID of a
+// new user the sessions may belong to.
+type Fixture struct {
+	Repo    domain.SessionRepository
+	NewUser func(t *testing.T) uint
+}
+
+// Run checks fixtures returned by newFixture. Subtests run sequentially so
+// they may share a database.
+func Run(t *testing.T, newFixture func(t *testing.T) Fixture) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, f Fixture)
+	}{
+		{"CreateAndFind", testCreateAndFind},
+		{"FindMissing", testFindMissing},
+		{"Revoke", testRevoke},
+		{"RevokeAllForUser", testRevokeAllForUser},
+		{"Delete", testDelete},
+		{"ListByUser", testListByUser},
+		{"DeleteExpired", testDeleteExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newFixture(t))
+		})
+	}
+}
+
+func create(t *testing.T, f Fixture, userID uint, token string, expiresAt time.Time) *domain.Session {
+	t.Helper()
+	session := &domain.Session{
+		UserID:       userID,
+		RefreshToken: token,
+		UserAgent:    "curl/8.0",
+		ClientIP:     "203.0.113.7",
+		ExpiresAt:    expiresAt,
+	}
+	require.NoError(t, f.Repo.Create(context.Background(), session))
+	return session
+}
+
+func testCreateAndFind(t *testing.T, f Fixture) {
+	ctx := context.Background()
+	userID := f.NewUser(t)
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	session := create(t, f, userID, "token-1", expiresAt)
+	assert.NotZero(t, session.ID)
+	assert.False(t, session.CreatedAt.IsZero())
+
+	for _, find := range []func() (*domain.Session, error){
+		func() (*domain.Session, error) { return f.Repo.FindByID(ctx, session.ID) },
+		func() (*domain.Session, error) { return f.Repo.FindByRefreshToken(ctx, "token-1") },
+	} {
+		found, err := find()
+		require.NoError(t, err)
+		assert.Equal(t, session.ID, found.ID)
+		assert.Equal(t, userID, found.UserID)
+		assert.Equal(t, "token-1", found.RefreshToken)
+		assert.Equal(t, "curl/8.0", found.UserAgent)
+		assert.Equal(t, "203.0.113.7", found.ClientIP)
+		assert.False(t, found.IsRevoked)
+		assert.True(t, expiresAt.Equal(found.ExpiresAt))
+	}
+}
+
+func testFindMissing(t *testing.T, f Fixture) {
+	ctx := context.Background()
+	_, err := f.Repo.FindByID(ctx, 999)
+	assert.ErrorIs(t, err, domain.ErrSessionNotFound)
+	_, err = f.Repo.FindByRefreshToken(ctx, "missing")
+	assert.ErrorIs(t, err, domain.ErrSessionNotFound)
+}
+
+func testRevoke(t *testing.T, f Fixture) {
+	ctx := context.Background()
+	session := create(t, f, f.NewUser(t), "token-1", time.Now().Add(time.Hour))
+
+	require.NoError(t, f.Repo.Revoke(ctx, session.ID))
+	found, err := f.Repo.FindByID(ctx, session.ID)
+	require.NoError(t, err)
+	assert.True(t, found.IsRevoked)
+
+	assert.ErrorIs(t, f.Repo.Revoke(ctx, session.ID), domain.ErrSessionNotFound, "a session is revoked once")
+	assert.ErrorIs(t, f.Repo.Revoke(ctx, 999), domain.ErrSessionNotFound)
+}
+
+func testRevokeAllForUser(t *testing.T, f Fixture) {
+	ctx := context.Background()
+	userID, otherID := f.NewUser(t), f.NewUser(t)
+	a := create(t, f, userID, "token-a", time.Now().Add(time.Hour))
+	b := create(t, f, userID, "token-b", time.Now().Add(time.Hour))
+	other := create(t, f, otherID, "token-other", time.Now().Add(time.Hour))
+
+	require.NoError(t, f.Repo.RevokeAllForUser(ctx, userID))
+
+	for id, revoked := range map[uint]bool{a.ID: true, b.ID: true, other.ID: false} {
+		found, err := f.Repo.FindByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, revoked, found.IsRevoked, "session %d", id)
+	}
+}
+
+func testDelete(t *testing.T, f Fixture) {
+	ctx := context.Background()
+	session := create(t, f, f.NewUser(t), "token-1", time.Now().Add(time.Hour))
+
+	require.NoError(t, f.Repo.Delete(ctx, session.ID))
+	_, err := f.Repo.FindByID(ctx, session.ID)
+	assert.ErrorIs(t, err, domain.ErrSessionNotFound)
+}
+
+func testListByUser(t *testing.T, f Fixture) {
+	ctx := context.Background()
+	userID := f.NewUser(t)
+	older := create(t, f, userID, "token-old", time.Now().Add(time.Hour))
+	time.Sleep(10 * time.Millisecond)
+	newer := create(t, f, userID, "token-new", time.Now().Add(time.Hour))
+	require.NoError(t, f.Repo.Revoke(ctx, newer.ID))
+	create(t, f, f.NewUser(t), "token-other", time.Now().Add(time.Hour))
+
+	sessions, err := f.Repo.ListByUser(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, newer.ID, sessions[0].ID, "newest first, revoked included")
+	assert.Equal(t, older.ID, sessions[1].ID)
+
+	sessions, err = f.Repo.ListByUser(ctx, 999)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+}
+
+func testDeleteExpired(t *testing.T, f Fixture) {
+	ctx := context.Background()
+	userID := f.NewUser(t)
+	now := time.Now()
+	expired := create(t, f, userID, "token-expired", now.Add(-time.Hour))
+	live := create(t, f, userID, "token-live", now.Add(time.Hour))
+
+	deleted, err := f.Repo.DeleteExpired(ctx, now)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, deleted)
+
+	_, err = f.Repo.FindByID(ctx, expired.ID)
+	assert.ErrorIs(t, err, domain.ErrSessionNotFound)
+	_, err = f.Repo.FindByID(ctx, live.ID)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"context"
+	"english-learning/internal/modules/session/domain"
+	"english-learning/pkg/cron"
+	"english-learning/pkg/jobs"
+	"english-learning/pkg/logger"
+	"fmt"
+	"time"
+)
+
+// purgeExpiredSchedule runs the cleanup daily at 03:00 UTC.
+var purgeExpiredSchedule = cron.MustParse("0 3 * * *")
+
+// RegisterJobs registers the session maintenance jobs and their schedules.
+func RegisterJobs(r jobs.Registry, repo domain.SessionRepository) {
+	jobs.Handle(r, func(ctx context.Context, _ domain.PurgeExpiredSessions) error {
+		deleted, err := repo.DeleteExpired(ctx, time.Now())
+		if err != nil {
+			return fmt.Errorf("deleting expired sessions: %w", err)
+		}
+		logger.Infof("session", "Purged %d expired session(s)", deleted)
+		return nil
+	})
+	r.Schedule("session.purge_expired", purgeExpiredSchedule, domain.PurgeExpiredSessions{})
+}
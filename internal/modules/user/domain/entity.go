@@ -4,6 +4,13 @@ import (
 	"time"
 )
 
+// Roles a user can hold. New accounts are students; admins are promoted with
+// the admin CLI.
+const (
+	RoleStudent = "student"
+	RoleAdmin   = "admin"
+)
+
 type User struct {
 	ID          uint
 	Email       string
@@ -12,11 +19,16 @@ type User struct {
 	LastName    string
 	PhoneNumber string
 	Birthdate   *time.Time
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	// DeletedAt removed as it's persistence concern, or Changed to *time.Time if logical delete is domain concept.
-	// For now, I will remove it to be strictly pure as requested, assuming logical delete is an implementation detail of persistence.
-	// If domain logic requires knowing if a user is deleted, I would add `IsDeleted bool` or `DeletedAt *time.Time`.
-	// Given the previous code used gorm.DeletedAt, I'll omit it for now to follow "pure" instructions strictly.
-
+	// Locale is the user's preferred language for API messages ("" = follow
+	// the client's Accept-Language header).
+	Locale string
+	Role   string
+	// Version starts at 1 and is incremented by every update. An update
+	// carrying an outdated Version fails with ErrVersionConflict.
+	Version   int64
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// DeletedAt is set when the user has been soft-deleted. Regular lookups never
+	// return deleted users; it is only populated by the admin-facing queries.
+	DeletedAt *time.Time
 }
@@ -0,0 +1,49 @@
+package domain
+
+// Event names published by the user service. They are persisted in the
+// outbox, so never rename one.
+const (
+	EventUserCreated  = "user.created"
+	EventUserUpdated  = "user.updated"
+	EventUserDeleted  = "user.deleted"
+	EventUserRestored = "user.restored"
+	EventUserPurged   = "user.purged"
+)
+
+// UserCreated is published when a user is created through the user service
+// (self-registration publishes auth.user_registered instead).
+type UserCreated struct {
+	UserID uint   `json:"userId"`
+	Email  string `json:"email"`
+}
+
+func (UserCreated) EventName() string { return EventUserCreated }
+
+// UserUpdated is published when a user's profile changes.
+type UserUpdated struct {
+	UserID uint `json:"userId"`
+}
+
+func (UserUpdated) EventName() string { return EventUserUpdated }
+
+// UserDeleted is published when a user is soft-deleted.
+type UserDeleted struct {
+	UserID uint `json:"userId"`
+}
+
+func (UserDeleted) EventName() string { return EventUserDeleted }
+
+// UserRestored is published when a soft-deleted user is restored.
+type UserRestored struct {
+	UserID uint `json:"userId"`
+}
+
+func (UserRestored) EventName() string { return EventUserRestored }
+
+// UserPurged is published when a user is permanently deleted. Subscribers
+// holding personal data about the user should erase it.
+type UserPurged struct {
+	UserID uint `json:"userId"`
+}
+
+func (UserPurged) EventName() string { return EventUserPurged }
@@ -0,0 +1,8 @@
+package domain
+
+// ReencryptPersonalData seals the personal data of every user with the
+// primary encryption key, after a key rotation or for data written before
+// encryption. It runs on a schedule; the kind is persisted, so never rename it.
+type ReencryptPersonalData struct{}
+
+func (ReencryptPersonalData) JobKind() string { return "user.reencrypt_personal_data" }
@@ -0,0 +1,42 @@
+package domain
+
+import "english-learning/pkg/query"
+
+// UserQuery is what users can be listed by. Sorting by createdAt, the
+// default, is served by idx_users_created_at_id. Phone numbers are encrypted,
+// so they can only be matched exactly, through their blind index.
+var UserQuery = query.Spec{
+	Fields: []query.Field{
+		{Name: "id", Type: query.Int, Sortable: true},
+		{Name: "email", Type: query.String, Ops: []query.Op{query.Eq, query.Contains}, Sortable: true},
+		{Name: "firstName", Type: query.String, Ops: []query.Op{query.Contains}},
+		{Name: "lastName", Type: query.String, Ops: []query.Op{query.Contains}},
+		{Name: "role", Type: query.String, Ops: []query.Op{query.Eq}},
+		{Name: "phoneNumber", Type: query.String, Ops: []query.Op{query.Eq}},
+		{Name: "createdAt", Type: query.Time, Ops: []query.Op{query.Gt, query.Gte, query.Lt, query.Lte}, Sortable: true},
+	},
+	DefaultSort:  "-createdAt",
+	DefaultLimit: 20,
+	MaxLimit:     100,
+}
+
+// QueryValue returns the value of one of UserQuery's fields.
+func (u *User) QueryValue(field string) interface{} {
+	switch field {
+	case "id":
+		return int64(u.ID)
+	case "email":
+		return u.Email
+	case "firstName":
+		return u.FirstName
+	case "lastName":
+		return u.LastName
+	case "role":
+		return u.Role
+	case "phoneNumber":
+		return u.PhoneNumber
+	case "createdAt":
+		return u.CreatedAt
+	}
+	return nil
+}
@@ -1,14 +1,44 @@
 package domain
 
-import "errors"
+import (
+	"context"
+	"english-learning/pkg/query"
+	"errors"
+)
 
-var ErrUserNotFound = errors.New("user not found")
+var (
+	ErrUserNotFound = errors.New("user not found")
+	ErrEmailInUse   = errors.New("email is used by another active user")
+	// ErrVersionConflict means the user changed since the caller read it.
+	ErrVersionConflict = errors.New("user was modified concurrently")
+)
 
 type UserRepository interface {
-	Create(user *User) error
-	FindByEmail(email string) (*User, error)
-	FindByID(id uint) (*User, error)
-	Update(user *User) error
-	Delete(id uint) error
-	List(offset, limit int) ([]User, int64, error)
+	Create(ctx context.Context, user *User) error
+	FindByEmail(ctx context.Context, email string) (*User, error)
+	FindByID(ctx context.Context, id uint) (*User, error)
+	// Update saves user if its Version is still the stored one, and then
+	// increments user.Version. It fails with ErrUserNotFound for a missing or
+	// deleted user and ErrVersionConflict for an outdated Version.
+	Update(ctx context.Context, user *User) error
+	// Delete soft-deletes a user. It fails with ErrUserNotFound for a missing
+	// or already deleted user.
+	Delete(ctx context.Context, id uint) error
+	// List returns a page of active users matching q, built from UserQuery.
+	List(ctx context.Context, q query.Query) (query.Page[User], error)
+
+	// Soft-delete management. These operate only on users that have been deleted.
+	ListDeleted(ctx context.Context, offset, limit int) ([]User, int64, error)
+	Restore(ctx context.Context, id uint) error
+	Purge(ctx context.Context, id uint) error
+}
+
+// PersonalDataReencrypter is implemented by repositories that encrypt
+// personal data at rest.
+type PersonalDataReencrypter interface {
+	// ReencryptPersonalData seals, with the primary key, the personal data of
+	// up to limit users with an ID above after whose data is plaintext or
+	// sealed with another key. It returns the highest ID it looked at, zero
+	// when none are left, and how many users it actually rewrote.
+	ReencryptPersonalData(ctx context.Context, after uint, limit int) (last uint, rewritten int, err error)
 }
@@ -1,10 +1,24 @@
 package domain
 
+import (
+	"context"
+	"english-learning/pkg/query"
+)
+
 // UserService defines the business logic contract for user operations.
 type UserService interface {
-	Create(user *User) error
-	Get(id uint) (*User, error)
-	Update(user *User) error
-	Delete(id uint) error
-	List(page, pageSize int) ([]User, int64, error)
+	Create(ctx context.Context, user *User) error
+	Get(ctx context.Context, id uint) (*User, error)
+	// Update saves a user just read from the repository; see
+	// UserRepository.Update. A non-empty newPassword is hashed into Password
+	// first; with an empty one the stored hash is kept as it is.
+	Update(ctx context.Context, user *User, newPassword string) error
+	// Edit loads a user, lets edit change it and saves the result. edit must
+	// not change ID, Version or Password; returning an error aborts the edit.
+	Edit(ctx context.Context, id uint, edit func(user *User) error) (*User, error)
+	Delete(ctx context.Context, id uint) error
+	List(ctx context.Context, q query.Query) (query.Page[User], error)
+	ListDeleted(ctx context.Context, page, pageSize int) ([]User, int64, error)
+	Restore(ctx context.Context, id uint) error
+	Purge(ctx context.Context, id uint) error
 }
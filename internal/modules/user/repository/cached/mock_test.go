@@ -0,0 +1,73 @@
+package cached
+
+import (
+	"context"
+	"english-learning/internal/modules/user/domain"
+	"english-learning/pkg/query"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockUserRepository is a mock implementation of domain.UserRepository.
+type MockUserRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserRepository) Create(ctx context.Context, user *domain.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
+func (m *MockUserRepository) FindByID(ctx context.Context, id uint) (*domain.User, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
+func (m *MockUserRepository) Update(ctx context.Context, user *domain.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) List(ctx context.Context, q query.Query) (query.Page[domain.User], error) {
+	args := m.Called(ctx, q)
+	return args.Get(0).(query.Page[domain.User]), args.Error(1)
+}
+
+func (m *MockUserRepository) ListDeleted(ctx context.Context, offset, limit int) ([]domain.User, int64, error) {
+	args := m.Called(ctx, offset, limit)
+	return args.Get(0).([]domain.User), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockUserRepository) Restore(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) Purge(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// fakeMetrics counts cache lookups.
+type fakeMetrics struct {
+	hits, misses int
+}
+
+func (m *fakeMetrics) CacheHit(string)  { m.hits++ }
+func (m *fakeMetrics) CacheMiss(string) { m.misses++ }
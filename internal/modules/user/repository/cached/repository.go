@@ -0,0 +1,148 @@
+// Package cached decorates a domain.UserRepository with a cache of users by
+// ID, the lookup behind every token refresh.
+package cached
+
+import (
+	"context"
+	"english-learning/internal/database"
+	"english-learning/internal/modules/user/domain"
+	"english-learning/pkg/cache"
+	"english-learning/pkg/logger"
+	"strconv"
+	"sync"
+)
+
+// Channel is the Postgres NOTIFY channel on which the users table trigger
+// announces the ID of every updated or deleted user, whoever changed it.
+const Channel = "user_changed"
+
+// metricsName labels this cache in the cache metrics.
+const metricsName = "users"
+
+// Metrics records cache lookups.
+type Metrics interface {
+	CacheHit(cache string)
+	CacheMiss(cache string)
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) CacheHit(string)  {}
+func (nopMetrics) CacheMiss(string) {}
+
+// UserRepository serves FindByID from a cache and invalidates entries on
+// Update, Delete, Restore and Purge. Every other method goes straight to the
+// wrapped repository. Changes made by other instances or outside the
+// application are picked up through HandleNotification; if a notification is
+// missed, an entry is stale for at most the backend's TTL.
+type UserRepository struct {
+	domain.UserRepository
+	cache   cache.Cache[uint, domain.User]
+	metrics Metrics
+
+	// mu and generation keep a lookup that raced with an invalidation from
+	// caching the value it read before the change.
+	mu         sync.Mutex
+	generation uint64
+}
+
+// NewUserRepository wraps next with backend. metrics may be nil.
+func NewUserRepository(next domain.UserRepository, backend cache.Cache[uint, domain.User], metrics Metrics) *UserRepository {
+	if metrics == nil {
+		metrics = nopMetrics{}
+	}
+	return &UserRepository{UserRepository: next, cache: backend, metrics: metrics}
+}
+
+// FindByID returns the cached user if present. Lookups inside a unit of work
+// bypass the cache, since they may see uncommitted changes.
+func (r *UserRepository) FindByID(ctx context.Context, id uint) (*domain.User, error) {
+	if database.InTransaction(ctx) {
+		return r.UserRepository.FindByID(ctx, id)
+	}
+
+	if user, ok := r.cache.Get(ctx, id); ok {
+		r.metrics.CacheHit(metricsName)
+		return clone(&user), nil
+	}
+	r.metrics.CacheMiss(metricsName)
+
+	r.mu.Lock()
+	generation := r.generation
+	r.mu.Unlock()
+
+	user, err := r.UserRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	if r.generation == generation {
+		r.cache.Set(ctx, id, *clone(user))
+	}
+	r.mu.Unlock()
+
+	return user, nil
+}
+
+func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
+	defer r.Invalidate(ctx, user.ID)
+	return r.UserRepository.Update(ctx, user)
+}
+
+func (r *UserRepository) Delete(ctx context.Context, id uint) error {
+	defer r.Invalidate(ctx, id)
+	return r.UserRepository.Delete(ctx, id)
+}
+
+func (r *UserRepository) Restore(ctx context.Context, id uint) error {
+	defer r.Invalidate(ctx, id)
+	return r.UserRepository.Restore(ctx, id)
+}
+
+func (r *UserRepository) Purge(ctx context.Context, id uint) error {
+	defer r.Invalidate(ctx, id)
+	return r.UserRepository.Purge(ctx, id)
+}
+
+// Invalidate drops the cached user with the given ID.
+func (r *UserRepository) Invalidate(ctx context.Context, id uint) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.generation++
+	r.cache.Delete(ctx, id)
+}
+
+// Flush drops every cached user. It runs whenever the notification listener
+// (re)connects, as changes may have been missed meanwhile.
+func (r *UserRepository) Flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.generation++
+	r.cache.Clear(context.Background())
+}
+
+// HandleNotification invalidates the user whose ID is the payload of a
+// notification on Channel.
+func (r *UserRepository) HandleNotification(payload string) {
+	id, err := strconv.ParseUint(payload, 10, 0)
+	if err != nil {
+		logger.Warnf("user", "Ignoring %s notification with payload %q", Channel, payload)
+		return
+	}
+	r.Invalidate(context.Background(), uint(id))
+}
+
+// clone copies u so that callers cannot modify the cached value.
+func clone(u *domain.User) *domain.User {
+	c := *u
+	if u.Birthdate != nil {
+		birthdate := *u.Birthdate
+		c.Birthdate = &birthdate
+	}
+	if u.DeletedAt != nil {
+		deletedAt := *u.DeletedAt
+		c.DeletedAt = &deletedAt
+	}
+	return &c
+}
@@ -0,0 +1,145 @@
+package cached
+
+import (
+	"context"
+	"english-learning/internal/modules/user/domain"
+	"english-learning/pkg/cache"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRepository() (*UserRepository, *MockUserRepository, *fakeMetrics) {
+	next := new(MockUserRepository)
+	metrics := &fakeMetrics{}
+	return NewUserRepository(next, cache.NewLRU[uint, domain.User](10, time.Minute), metrics), next, metrics
+}
+
+func TestFindByID_CachesUser(t *testing.T) {
+	t.Parallel()
+	repo, next, metrics := newTestRepository()
+	ctx := context.Background()
+
+	next.On("FindByID", mock.Anything, uint(1)).Return(&domain.User{ID: 1, Email: "a@example.com"}, nil).Once()
+
+	first, err := repo.FindByID(ctx, 1)
+	require.NoError(t, err)
+	second, err := repo.FindByID(ctx, 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, first, second)
+	assert.Equal(t, 1, metrics.hits)
+	assert.Equal(t, 1, metrics.misses)
+	next.AssertExpectations(t)
+}
+
+func TestFindByID_ReturnsCopies(t *testing.T) {
+	t.Parallel()
+	repo, next, _ := newTestRepository()
+	ctx := context.Background()
+	birthdate := time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	next.On("FindByID", mock.Anything, uint(1)).Return(&domain.User{ID: 1, FirstName: "Lan", Birthdate: &birthdate}, nil).Once()
+
+	user, err := repo.FindByID(ctx, 1)
+	require.NoError(t, err)
+	user.FirstName = "changed"
+	*user.Birthdate = time.Time{}
+
+	cached, err := repo.FindByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Lan", cached.FirstName)
+	assert.Equal(t, time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC), *cached.Birthdate)
+}
+
+func TestFindByID_DoesNotCacheErrors(t *testing.T) {
+	t.Parallel()
+	repo, next, _ := newTestRepository()
+	ctx := context.Background()
+
+	next.On("FindByID", mock.Anything, uint(1)).Return(nil, domain.ErrUserNotFound).Twice()
+
+	_, err := repo.FindByID(ctx, 1)
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	_, err = repo.FindByID(ctx, 1)
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	next.AssertExpectations(t)
+}
+
+func TestWrites_Invalidate(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	writes := map[string]func(repo *UserRepository, next *MockUserRepository) error{
+		"Update": func(repo *UserRepository, next *MockUserRepository) error {
+			next.On("Update", mock.Anything, mock.Anything).Return(nil)
+			return repo.Update(ctx, &domain.User{ID: 1})
+		},
+		"Delete": func(repo *UserRepository, next *MockUserRepository) error {
+			next.On("Delete", mock.Anything, uint(1)).Return(nil)
+			return repo.Delete(ctx, 1)
+		},
+		"Restore": func(repo *UserRepository, next *MockUserRepository) error {
+			next.On("Restore", mock.Anything, uint(1)).Return(nil)
+			return repo.Restore(ctx, 1)
+		},
+		"Purge": func(repo *UserRepository, next *MockUserRepository) error {
+			next.On("Purge", mock.Anything, uint(1)).Return(nil)
+			return repo.Purge(ctx, 1)
+		},
+		"notification": func(repo *UserRepository, next *MockUserRepository) error {
+			repo.HandleNotification("1")
+			return nil
+		},
+		"flush": func(repo *UserRepository, next *MockUserRepository) error {
+			repo.Flush()
+			return nil
+		},
+	}
+
+	for name, write := range writes {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			repo, next, metrics := newTestRepository()
+			next.On("FindByID", mock.Anything, uint(1)).Return(&domain.User{ID: 1}, nil).Twice()
+
+			_, err := repo.FindByID(ctx, 1)
+			require.NoError(t, err)
+			require.NoError(t, write(repo, next))
+			_, err = repo.FindByID(ctx, 1)
+			require.NoError(t, err)
+
+			assert.Equal(t, 2, metrics.misses)
+			next.AssertExpectations(t)
+		})
+	}
+}
+
+func TestFindByID_RacingInvalidationIsNotCached(t *testing.T) {
+	t.Parallel()
+	repo, next, metrics := newTestRepository()
+	ctx := context.Background()
+
+	// The user changes while the first lookup is reading the old row.
+	next.On("FindByID", mock.Anything, uint(1)).
+		Run(func(mock.Arguments) { repo.HandleNotification("1") }).
+		Return(&domain.User{ID: 1, Role: domain.RoleStudent}, nil).Once()
+	next.On("FindByID", mock.Anything, uint(1)).Return(&domain.User{ID: 1, Role: domain.RoleAdmin}, nil).Once()
+
+	_, err := repo.FindByID(ctx, 1)
+	require.NoError(t, err)
+	user, err := repo.FindByID(ctx, 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, domain.RoleAdmin, user.Role)
+	assert.Equal(t, 2, metrics.misses)
+}
+
+func TestHandleNotification_IgnoresInvalidPayload(t *testing.T) {
+	t.Parallel()
+	repo, _, _ := newTestRepository()
+
+	assert.NotPanics(t, func() { repo.HandleNotification("not-an-id") })
+}
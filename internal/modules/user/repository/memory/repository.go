@@ -0,0 +1,219 @@
+// Package memory implements domain.UserRepository in memory, for fast tests.
+// It behaves like the Postgres repository, as checked by the repotest contract
+// suite, but does not take part in units of work: writes are applied
+// immediately and never rolled back.
+package memory
+
+import (
+	"context"
+	"english-learning/internal/modules/user/domain"
+	"english-learning/pkg/query"
+	"sort"
+	"sync"
+	"time"
+)
+
+type UserRepository struct {
+	now func() time.Time
+
+	mu     sync.Mutex
+	nextID uint
+	users  map[uint]*domain.User
+}
+
+func NewUserRepository() *UserRepository {
+	return &UserRepository{now: time.Now, users: make(map[uint]*domain.User)}
+}
+
+// clone copies u so callers never share memory with the repository.
+func clone(u *domain.User) *domain.User {
+	c := *u
+	if u.Birthdate != nil {
+		birthdate := *u.Birthdate
+		c.Birthdate = &birthdate
+	}
+	if u.DeletedAt != nil {
+		deletedAt := *u.DeletedAt
+		c.DeletedAt = &deletedAt
+	}
+	return &c
+}
+
+// emailInUse reports whether an active user other than id has email, the
+// rule enforced by idx_users_email in Postgres.
+func (r *UserRepository) emailInUse(email string, id uint) bool {
+	for _, u := range r.users {
+		if u.ID != id && u.DeletedAt == nil && u.Email == email {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *UserRepository) active(id uint) (*domain.User, bool) {
+	u, ok := r.users[id]
+	if !ok || u.DeletedAt != nil {
+		return nil, false
+	}
+	return u, true
+}
+
+func (r *UserRepository) deleted(id uint) (*domain.User, bool) {
+	u, ok := r.users[id]
+	if !ok || u.DeletedAt == nil {
+		return nil, false
+	}
+	return u, true
+}
+
+func (r *UserRepository) Create(_ context.Context, user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.emailInUse(user.Email, 0) {
+		return domain.ErrEmailInUse
+	}
+
+	r.nextID++
+	now := r.now()
+	user.ID = r.nextID
+	user.Version = 1
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	user.DeletedAt = nil
+	r.users[user.ID] = clone(user)
+	return nil
+}
+
+func (r *UserRepository) FindByEmail(_ context.Context, email string) (*domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, u := range r.users {
+		if u.DeletedAt == nil && u.Email == email {
+			return clone(u), nil
+		}
+	}
+	return nil, domain.ErrUserNotFound
+}
+
+func (r *UserRepository) FindByID(_ context.Context, id uint) (*domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.active(id)
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+	return clone(u), nil
+}
+
+func (r *UserRepository) Update(_ context.Context, user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.active(user.ID)
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	if existing.Version != user.Version {
+		return domain.ErrVersionConflict
+	}
+	if r.emailInUse(user.Email, user.ID) {
+		return domain.ErrEmailInUse
+	}
+
+	user.Version++
+	updated := clone(user)
+	updated.CreatedAt = existing.CreatedAt
+	updated.UpdatedAt = r.now()
+	updated.DeletedAt = nil
+	r.users[user.ID] = updated
+	return nil
+}
+
+func (r *UserRepository) Delete(_ context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.active(id)
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	now := r.now()
+	u.DeletedAt = &now
+	return nil
+}
+
+// page returns users[offset:offset+limit], clamped to the slice.
+func page(users []domain.User, offset, limit int) []domain.User {
+	if offset > len(users) {
+		offset = len(users)
+	}
+	end := len(users)
+	if limit >= 0 && offset+limit < end {
+		end = offset + limit
+	}
+	return users[offset:end]
+}
+
+func (r *UserRepository) List(_ context.Context, q query.Query) (query.Page[domain.User], error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var users []domain.User
+	for _, u := range r.users {
+		if u.DeletedAt == nil {
+			users = append(users, *clone(u))
+		}
+	}
+	return query.Slice(users, q,
+		func(u domain.User, field string) interface{} { return u.QueryValue(field) },
+		func(u domain.User) uint64 { return uint64(u.ID) },
+	), nil
+}
+
+func (r *UserRepository) ListDeleted(_ context.Context, offset, limit int) ([]domain.User, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var users []domain.User
+	for _, u := range r.users {
+		if u.DeletedAt != nil {
+			users = append(users, *clone(u))
+		}
+	}
+	sort.Slice(users, func(i, j int) bool {
+		if !users[i].DeletedAt.Equal(*users[j].DeletedAt) {
+			return users[i].DeletedAt.After(*users[j].DeletedAt)
+		}
+		return users[i].ID > users[j].ID
+	})
+	return page(users, offset, limit), int64(len(users)), nil
+}
+
+func (r *UserRepository) Restore(_ context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.deleted(id)
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	if r.emailInUse(u.Email, id) {
+		return domain.ErrEmailInUse
+	}
+	u.DeletedAt = nil
+	return nil
+}
+
+func (r *UserRepository) Purge(_ context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.deleted(id); !ok {
+		return domain.ErrUserNotFound
+	}
+	delete(r.users, id)
+	return nil
+}
@@ -0,0 +1,14 @@
+package memory
+
+import (
+	"english-learning/internal/modules/user/domain"
+	"english-learning/internal/modules/user/repository/repotest"
+	"testing"
+)
+
+func TestUserRepository_Contract(t *testing.T) {
+	t.Parallel()
+	repotest.Run(t, func(*testing.T) domain.UserRepository {
+		return NewUserRepository()
+	})
+}
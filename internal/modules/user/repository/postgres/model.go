@@ -2,60 +2,131 @@ package postgres
 
 import (
 	"english-learning/internal/modules/user/domain"
+	"english-learning/pkg/encryption"
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
 )
 
 type User struct {
-	ID          uint           `gorm:"type:bigserial;primaryKey"`
-	Email       string         `gorm:"type:varchar(255);uniqueIndex;not null"`
-	Password    string         `gorm:"type:varchar(255);not null"`
-	FirstName   string         `gorm:"type:varchar(100)"`
-	LastName    string         `gorm:"type:varchar(100)"`
-	PhoneNumber string         `gorm:"type:varchar(20)"`
-	Birthdate   *time.Time     `gorm:"type:date"`
-	CreatedAt   time.Time      `gorm:"type:timestamp with time zone;autoCreateTime"`
-	UpdatedAt   time.Time      `gorm:"type:timestamp with time zone;autoUpdateTime"`
-	DeletedAt   gorm.DeletedAt `gorm:"type:timestamp with time zone;index"`
+	ID          uint   `gorm:"type:bigserial;primaryKey;index:idx_users_created_at_id,priority:2"`
+	Email       string `gorm:"type:varchar(255);uniqueIndex:idx_users_email,where:deleted_at IS NULL;not null"`
+	Password    string `gorm:"type:varchar(255);not null"`
+	FirstName   string `gorm:"type:varchar(100)"`
+	LastName    string `gorm:"type:varchar(100)"`
+	PhoneNumber string `gorm:"type:text"`
+	// PhoneNumberHash is the blind index of PhoneNumber, for lookups.
+	PhoneNumberHash string         `gorm:"type:varchar(64);index:idx_users_phone_number_hash"`
+	Birthdate       *string        `gorm:"type:text"`
+	Locale          string         `gorm:"type:varchar(10);not null;default:''"`
+	Role            string         `gorm:"type:varchar(20);not null;default:student"`
+	Version         int64          `gorm:"not null;default:1"`
+	CreatedAt       time.Time      `gorm:"type:timestamp with time zone;autoCreateTime;index:idx_users_created_at_id,priority:1,where:deleted_at IS NULL"`
+	UpdatedAt       time.Time      `gorm:"type:timestamp with time zone;autoUpdateTime"`
+	DeletedAt       gorm.DeletedAt `gorm:"type:timestamp with time zone;index"`
 
 
 }
 
-func (m *User) ToDomain() *domain.User {
+// Fields holding personal data are encrypted; the names bind each ciphertext
+// to its column.
+const (
+	phoneNumberField = "users.phone_number"
+	birthdateField   = "users.birthdate"
+)
+
+// ToDomain decrypts the personal data of m with enc.
+func (m *User) ToDomain(enc *encryption.Service) (*domain.User, error) {
     if m == nil {
-        return nil
+        return nil, nil
     }
+	phoneNumber, err := enc.Decrypt(phoneNumberField, m.PhoneNumber)
+	if err != nil {
+		return nil, fmt.Errorf("user %d: phone number: %w", m.ID, err)
+	}
+	birthdate, err := decryptDate(enc, birthdateField, m.Birthdate)
+	if err != nil {
+		return nil, fmt.Errorf("user %d: birthdate: %w", m.ID, err)
+	}
 	return &domain.User{
 		ID:          m.ID,
 		Email:       m.Email,
 		Password:    m.Password,
 		FirstName:   m.FirstName,
 		LastName:    m.LastName,
-		PhoneNumber: m.PhoneNumber,
-		Birthdate:   m.Birthdate,
+		PhoneNumber: phoneNumber,
+		Birthdate:   birthdate,
+		Locale:      m.Locale,
+		Role:        m.Role,
+		Version:     m.Version,
 		CreatedAt:   m.CreatedAt,
 		UpdatedAt:   m.UpdatedAt,
-		// DeletedAt is not part of pure domain usually, or we can add it if needed. 
-        // Plan says remove GORM tags. If domain has DeletedAt as time.Time or custom struct, we map it. 
-        // Checking domain/user.go again, it has gorm.DeletedAt. I should change that to time.Time or remove it.
-        // For now, I will assume domain will not have gorm.DeletedAt. 
-	}
+		DeletedAt:   deletedAtToDomain(m.DeletedAt),
+	}, nil
 }
 
-func FromDomainUser(u *domain.User) *User {
+// FromDomainUser encrypts the personal data of u with enc's primary key.
+func FromDomainUser(u *domain.User, enc *encryption.Service) (*User, error) {
     if u == nil {
-        return nil
+        return nil, nil
     }
+	phoneNumber, err := enc.Encrypt(phoneNumberField, u.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+	birthdate, err := encryptDate(enc, birthdateField, u.Birthdate)
+	if err != nil {
+		return nil, err
+	}
 	return &User{
-		ID:          u.ID,
-		Email:       u.Email,
-		Password:    u.Password,
-		FirstName:   u.FirstName,
-		LastName:    u.LastName,
-		PhoneNumber: u.PhoneNumber,
-		Birthdate:   u.Birthdate,
-		CreatedAt:   u.CreatedAt,
-		UpdatedAt:   u.UpdatedAt,
+		ID:              u.ID,
+		Email:           u.Email,
+		Password:        u.Password,
+		FirstName:       u.FirstName,
+		LastName:        u.LastName,
+		PhoneNumber:     phoneNumber,
+		PhoneNumberHash: enc.BlindIndex(phoneNumberField, u.PhoneNumber),
+		Birthdate:       birthdate,
+		Locale:          u.Locale,
+		Role:            u.Role,
+		Version:         u.Version,
+		CreatedAt:       u.CreatedAt,
+		UpdatedAt:       u.UpdatedAt,
+	}, nil
+}
+
+// encryptDate stores a date as an encrypted YYYY-MM-DD string.
+func encryptDate(enc *encryption.Service, field string, t *time.Time) (*string, error) {
+	if t == nil {
+		return nil, nil
+	}
+	sealed, err := enc.Encrypt(field, t.Format(time.DateOnly))
+	if err != nil {
+		return nil, err
+	}
+	return &sealed, nil
+}
+
+func decryptDate(enc *encryption.Service, field string, s *string) (*time.Time, error) {
+	if s == nil {
+		return nil, nil
+	}
+	opened, err := enc.Decrypt(field, *s)
+	if err != nil {
+		return nil, err
+	}
+	t, err := time.Parse(time.DateOnly, opened)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func deletedAtToDomain(d gorm.DeletedAt) *time.Time {
+	if !d.Valid {
+		return nil
 	}
+	t := d.Time
+	return &t
 }
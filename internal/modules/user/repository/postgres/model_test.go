@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"english-learning/internal/modules/user/domain"
+	"english-learning/pkg/encryption"
+	"english-learning/pkg/encryption/encryptiontest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserModel_EncryptsPersonalData(t *testing.T) {
+	t.Parallel()
+	enc := encryptiontest.New(t, encryptiontest.KeyIDs[0])
+	birthdate := time.Date(2012, 5, 6, 0, 0, 0, 0, time.UTC)
+	user := &domain.User{ID: 1, Email: "lan@example.com", PhoneNumber: "+84901234567", Birthdate: &birthdate}
+
+	model, err := FromDomainUser(user, enc)
+	require.NoError(t, err)
+	assert.True(t, encryption.IsEncrypted(model.PhoneNumber))
+	require.NotNil(t, model.Birthdate)
+	assert.True(t, encryption.IsEncrypted(*model.Birthdate))
+	assert.NotContains(t, *model.Birthdate, "2012")
+	assert.Equal(t, enc.BlindIndex(phoneNumberField, "+84901234567"), model.PhoneNumberHash)
+
+	got, err := model.ToDomain(encryptiontest.New(t, encryptiontest.KeyIDs[1]))
+	require.NoError(t, err)
+	assert.Equal(t, "+84901234567", got.PhoneNumber)
+	assert.Equal(t, &birthdate, got.Birthdate)
+	assert.Equal(t, "lan@example.com", got.Email)
+}
+
+func TestUserModel_EmptyPersonalData(t *testing.T) {
+	t.Parallel()
+	enc := encryptiontest.New(t, encryptiontest.KeyIDs[0])
+
+	model, err := FromDomainUser(&domain.User{ID: 1}, enc)
+	require.NoError(t, err)
+	assert.Empty(t, model.PhoneNumber)
+	assert.Empty(t, model.PhoneNumberHash)
+	assert.Nil(t, model.Birthdate)
+
+	got, err := model.ToDomain(enc)
+	require.NoError(t, err)
+	assert.Empty(t, got.PhoneNumber)
+	assert.Nil(t, got.Birthdate)
+}
+
+func TestUserModel_ReadsPlaintext(t *testing.T) {
+	t.Parallel()
+	birthdate := "2012-05-06"
+	model := &User{ID: 1, PhoneNumber: "+84901234567", Birthdate: &birthdate}
+
+	got, err := model.ToDomain(encryptiontest.New(t, encryptiontest.KeyIDs[0]))
+	require.NoError(t, err)
+	assert.Equal(t, "+84901234567", got.PhoneNumber)
+	assert.Equal(t, "2012-05-06", got.Birthdate.Format(time.DateOnly))
+}
+
+func TestUserModel_RejectsFieldSwap(t *testing.T) {
+	t.Parallel()
+	enc := encryptiontest.New(t, encryptiontest.KeyIDs[0])
+	birthdate := time.Date(2012, 5, 6, 0, 0, 0, 0, time.UTC)
+	model, err := FromDomainUser(&domain.User{ID: 1, PhoneNumber: "+84901234567", Birthdate: &birthdate}, enc)
+	require.NoError(t, err)
+
+	model.PhoneNumber, *model.Birthdate = *model.Birthdate, model.PhoneNumber
+	_, err = model.ToDomain(enc)
+	assert.ErrorIs(t, err, encryption.ErrMalformed)
+}
@@ -1,82 +1,280 @@
 package postgres
 
 import (
+	"context"
+	"english-learning/internal/database"
 	"english-learning/internal/modules/user/domain"
+	"english-learning/pkg/encryption"
+	"english-learning/pkg/query"
 	"errors"
+	"fmt"
 
 	"gorm.io/gorm"
 )
 
+// UserRepository stores users with their personal data encrypted by enc; see
+// FromDomainUser.
 type UserRepository struct {
-	db *gorm.DB
+	db  *gorm.DB
+	enc *encryption.Service
 }
 
-func NewUserRepository(db *gorm.DB) domain.UserRepository {
-	return &UserRepository{db: db}
+func NewUserRepository(db *gorm.DB, enc *encryption.Service) *UserRepository {
+	return &UserRepository{db: db, enc: enc}
 }
 
-func (r *UserRepository) Create(user *domain.User) error {
-	userModel := FromDomainUser(user)
-	if err := r.db.Create(userModel).Error; err != nil {
+// conn joins the caller's unit of work, if any.
+func (r *UserRepository) conn(ctx context.Context) *gorm.DB {
+	return database.Conn(ctx, r.db)
+}
+
+// translateError maps a violation of idx_users_email, the only unique
+// constraint on users, to domain.ErrEmailInUse.
+func translateError(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return domain.ErrEmailInUse
+	}
+	return err
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
+	userModel, err := FromDomainUser(user, r.enc)
+	if err != nil {
 		return err
 	}
+	userModel.Version = 1
+	if err := r.conn(ctx).Create(userModel).Error; err != nil {
+		return translateError(err)
+	}
 	// Update ID back to domain
 	user.ID = userModel.ID
+	user.Version = userModel.Version
     user.CreatedAt = userModel.CreatedAt
     user.UpdatedAt = userModel.UpdatedAt
 	return nil
 }
 
-func (r *UserRepository) FindByEmail(email string) (*domain.User, error) {
+func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
 	var userModel User
-	err := r.db.Where("email = ?", email).First(&userModel).Error
+	err := r.conn(ctx).Where("email = ?", email).First(&userModel).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, domain.ErrUserNotFound
 		}
 		return nil, err
 	}
-	return userModel.ToDomain(), nil
+	return userModel.ToDomain(r.enc)
 }
 
-func (r *UserRepository) FindByID(id uint) (*domain.User, error) {
+func (r *UserRepository) FindByID(ctx context.Context, id uint) (*domain.User, error) {
 	var userModel User
-	err := r.db.First(&userModel, id).Error
+	err := r.conn(ctx).First(&userModel, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, domain.ErrUserNotFound
 		}
 		return nil, err
 	}
-	return userModel.ToDomain(), nil
+	return userModel.ToDomain(r.enc)
+}
+
+// Update writes every field of an active user, guarded by its version.
+// Unlike Save, it never inserts.
+func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
+	userModel, err := FromDomainUser(user, r.enc)
+	if err != nil {
+		return err
+	}
+	userModel.Version = user.Version + 1
+	result := r.conn(ctx).Model(&User{ID: user.ID}).
+		Where("version = ?", user.Version).
+		Select("*").Omit("id", "created_at", "deleted_at").
+		Updates(userModel)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		// Distinguish a missing user from a stale version.
+		if _, err := r.FindByID(ctx, user.ID); err != nil {
+			return err
+		}
+		return domain.ErrVersionConflict
+	}
+	user.Version = userModel.Version
+	return nil
+}
+
+func (r *UserRepository) Delete(ctx context.Context, id uint) error {
+	result := r.conn(ctx).Delete(&User{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
 }
 
-func (r *UserRepository) Update(user *domain.User) error {
-	userModel := FromDomainUser(user)
-	return r.db.Save(userModel).Error
+// userColumns maps the fields of domain.UserQuery to columns.
+var userColumns = database.Columns{
+	"id":          "id",
+	"email":       "email",
+	"firstName":   "first_name",
+	"lastName":    "last_name",
+	"role":        "role",
+	"createdAt":   "created_at",
+	"phoneNumber": "phone_number_hash",
 }
 
-func (r *UserRepository) Delete(id uint) error {
-	return r.db.Delete(&User{}, id).Error
+// blindIndexFilters replaces the phone numbers filtered on with their blind
+// index, as stored in phone_number_hash.
+func (r *UserRepository) blindIndexFilters(q query.Query) query.Query {
+	filters := make([]query.Filter, len(q.Filters))
+	for i, f := range q.Filters {
+		if f.Field.Name == "phoneNumber" {
+			f.Value = r.enc.BlindIndex(phoneNumberField, f.Value.(string))
+		}
+		filters[i] = f
+	}
+	q.Filters = filters
+	return q
 }
 
-func (r *UserRepository) List(offset, limit int) ([]domain.User, int64, error) {
+// List seeks to the cursor instead of using OFFSET, and counts only when
+// asked: both are slow on a large users table.
+func (r *UserRepository) List(ctx context.Context, q query.Query) (query.Page[domain.User], error) {
+	filtered := database.Filter(r.conn(ctx).Model(&User{}), r.blindIndexFilters(q), userColumns)
+
+	var total *int64
+	if q.WithTotal {
+		var count int64
+		if err := filtered.Session(&gorm.Session{}).Count(&count).Error; err != nil {
+			return query.Page[domain.User]{}, err
+		}
+		total = &count
+	}
+
+	var userModels []User
+	if err := database.Seek(filtered, q, userColumns, "id").Find(&userModels).Error; err != nil {
+		return query.Page[domain.User]{}, err
+	}
+
+	users, err := r.toDomain(userModels)
+	if err != nil {
+		return query.Page[domain.User]{}, err
+	}
+
+	page := query.NewPage(users, q, func(u domain.User) query.Cursor {
+		return query.Cursor{Value: u.QueryValue(q.Sort.Field.Name), ID: uint64(u.ID)}
+	})
+	page.Total = total
+	return page, nil
+}
+
+func (r *UserRepository) ListDeleted(ctx context.Context, offset, limit int) ([]domain.User, int64, error) {
 	var userModels []User
 	var count int64
 
-	if err := r.db.Model(&User{}).Count(&count).Error; err != nil {
+	deleted := r.conn(ctx).Unscoped().Model(&User{}).Where("deleted_at IS NOT NULL")
+
+	if err := deleted.Session(&gorm.Session{}).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := deleted.Order("deleted_at DESC").Offset(offset).Limit(limit).Find(&userModels).Error
+	if err != nil {
 		return nil, 0, err
 	}
 
-	err := r.db.Offset(offset).Limit(limit).Find(&userModels).Error
+	users, err := r.toDomain(userModels)
 	if err != nil {
 		return nil, 0, err
 	}
 
+	return users, count, nil
+}
+
+// ReencryptPersonalData seals the personal data of up to limit users above
+// after, deleted or not, that is still plaintext or sealed with a key other
+// than the primary one. Each row is rewritten only if it did not change
+// meanwhile; a concurrent update has sealed it with the primary key anyway.
+// Neither the version nor updated_at change, since the data does not.
+func (r *UserRepository) ReencryptPersonalData(ctx context.Context, after uint, limit int) (uint, int, error) {
+	current := encryption.SealedPrefix(r.enc.PrimaryKeyID())
+	var userModels []User
+	err := r.conn(ctx).Unscoped().
+		Where("id > ?", after).
+		Where("(phone_number <> '' AND NOT starts_with(phone_number, ?)) OR (birthdate IS NOT NULL AND NOT starts_with(birthdate, ?))", current, current).
+		Order("id").Limit(limit).Find(&userModels).Error
+	if err != nil || len(userModels) == 0 {
+		return 0, 0, err
+	}
+
+	rewritten := 0
+	for _, stale := range userModels {
+		user, err := stale.ToDomain(r.enc)
+		if err != nil {
+			return 0, rewritten, err
+		}
+		sealed, err := FromDomainUser(user, r.enc)
+		if err != nil {
+			return 0, rewritten, err
+		}
+		result := r.conn(ctx).Unscoped().Model(&User{}).
+			Where("id = ? AND COALESCE(phone_number, '') = ? AND birthdate IS NOT DISTINCT FROM ?", stale.ID, stale.PhoneNumber, stale.Birthdate).
+			UpdateColumns(map[string]interface{}{
+				"phone_number":      sealed.PhoneNumber,
+				"phone_number_hash": sealed.PhoneNumberHash,
+				"birthdate":         sealed.Birthdate,
+			})
+		if result.Error != nil {
+			return 0, rewritten, fmt.Errorf("user %d: %w", stale.ID, result.Error)
+		}
+		rewritten += int(result.RowsAffected)
+	}
+	return userModels[len(userModels)-1].ID, rewritten, nil
+}
+
+// toDomain maps the rows of a listing.
+func (r *UserRepository) toDomain(userModels []User) ([]domain.User, error) {
 	users := make([]domain.User, len(userModels))
 	for i, model := range userModels {
-		users[i] = *model.ToDomain()
+		user, err := model.ToDomain(r.enc)
+		if err != nil {
+			return nil, err
+		}
+		users[i] = *user
 	}
+	return users, nil
+}
 
-	return users, count, nil
+// Restore clears deleted_at on a soft-deleted user in a single conditional
+// update, so concurrent restores and purges cannot both succeed. It fails with
+// domain.ErrEmailInUse when the email has since been taken by a new account,
+// which the partial unique index on active emails reports.
+func (r *UserRepository) Restore(ctx context.Context, id uint) error {
+	result := r.conn(ctx).Unscoped().Model(&User{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// Purge permanently removes a soft-deleted user; the condition is part of the
+// delete so a user restored concurrently is kept. Sessions are removed by the
+// ON DELETE CASCADE foreign key.
+func (r *UserRepository) Purge(ctx context.Context, id uint) error {
+	result := r.conn(ctx).Unscoped().Where("deleted_at IS NOT NULL").Delete(&User{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
 }
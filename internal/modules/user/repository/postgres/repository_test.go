@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"context"
+	"english-learning/internal/database/dbtest"
+	"english-learning/internal/modules/user/domain"
+	"english-learning/internal/modules/user/repository/repotest"
+	"english-learning/pkg/encryption"
+	"english-learning/pkg/encryption/encryptiontest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserRepository_Contract(t *testing.T) {
+	db := dbtest.Open(t)
+	repotest.Run(t, func(t *testing.T) domain.UserRepository {
+		dbtest.Truncate(t, db, "users")
+		return NewUserRepository(db, encryptiontest.New(t, encryptiontest.KeyIDs[0]))
+	})
+}
+
+func TestUserRepository_ReencryptPersonalData(t *testing.T) {
+	db := dbtest.Open(t)
+	dbtest.Truncate(t, db, "users")
+	ctx := context.Background()
+
+	old := NewUserRepository(db, encryptiontest.New(t, encryptiontest.KeyIDs[0]))
+	sealed := &domain.User{Email: "sealed@example.com", Password: "hashed", Role: domain.RoleStudent, PhoneNumber: "+84901234567"}
+	require.NoError(t, old.Create(ctx, sealed))
+	deleted := &domain.User{Email: "deleted@example.com", Password: "hashed", Role: domain.RoleStudent, PhoneNumber: "+84907654321"}
+	require.NoError(t, old.Create(ctx, deleted))
+	require.NoError(t, old.Delete(ctx, deleted.ID))
+	// Written before encryption: plaintext and no blind index.
+	require.NoError(t, db.Exec(`INSERT INTO users (email, password, role, phone_number, birthdate) VALUES ('legacy@example.com', 'hashed', 'student', '+84900000000', '2012-05-06')`).Error)
+	none := &domain.User{Email: "none@example.com", Password: "hashed", Role: domain.RoleStudent}
+	require.NoError(t, old.Create(ctx, none))
+
+	repo := NewUserRepository(db, encryptiontest.New(t, encryptiontest.KeyIDs[1]))
+	last, rewritten, err := repo.ReencryptPersonalData(ctx, 0, 2)
+	require.NoError(t, err)
+	assert.Equal(t, deleted.ID, last)
+	assert.Equal(t, 2, rewritten)
+
+	last, rewritten, err = repo.ReencryptPersonalData(ctx, last, 2)
+	require.NoError(t, err)
+	assert.NotZero(t, last)
+	assert.Equal(t, 1, rewritten)
+	last, rewritten, err = repo.ReencryptPersonalData(ctx, last, 2)
+	require.NoError(t, err)
+	assert.Zero(t, last)
+	assert.Zero(t, rewritten)
+
+	var rows []User
+	require.NoError(t, db.Unscoped().Where("phone_number <> ''").Find(&rows).Error)
+	require.Len(t, rows, 3)
+	for _, row := range rows {
+		assert.Equal(t, encryptiontest.KeyIDs[1], encryption.KeyID(row.PhoneNumber), row.Email)
+		assert.NotEmpty(t, row.PhoneNumberHash, row.Email)
+		assert.Equal(t, int64(1), row.Version, "re-encryption is not an update")
+	}
+
+	legacy, err := repo.FindByEmail(ctx, "legacy@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "+84900000000", legacy.PhoneNumber)
+	require.NotNil(t, legacy.Birthdate)
+	assert.Equal(t, "2012-05-06", legacy.Birthdate.Format("2006-01-02"))
+}
@@ -0,0 +1,393 @@
+// Package repotest is the contract every domain.UserRepository must honour.
+// Each implementation runs it from its own tests; the Postgres run needs
+// TEST_DATABASE_DSN.
+package repotest
+
+import (
+	"context"
+	"english-learning/internal/modules/user/domain"
+	"english-learning/pkg/query"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Run checks repositories returned by newRepo, which must be empty. Subtests
+// run sequentially so they may share a database.
+func Run(t *testing.T, newRepo func(t *testing.T) domain.UserRepository) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, repo domain.UserRepository)
+	}{
+		{"CreateAndFind", testCreateAndFind},
+		{"CreateDuplicateEmail", testCreateDuplicateEmail},
+		{"FindMissing", testFindMissing},
+		{"Update", testUpdate},
+		{"UpdateMissing", testUpdateMissing},
+		{"UpdateVersion", testUpdateVersion},
+		{"UpdateDuplicateEmail", testUpdateDuplicateEmail},
+		{"DeleteHidesUser", testDeleteHidesUser},
+		{"DeleteMissing", testDeleteMissing},
+		{"List", testList},
+		{"ListCursor", testListCursor},
+		{"ListFilters", testListFilters},
+		{"ListDeleted", testListDeleted},
+		{"Restore", testRestore},
+		{"RestoreEmailTaken", testRestoreEmailTaken},
+		{"Purge", testPurge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t))
+		})
+	}
+}
+
+func newUser(email string) *domain.User {
+	birthdate := time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC)
+	return &domain.User{
+		Email:       email,
+		Password:    "hashed",
+		FirstName:   "Lan",
+		LastName:    "Nguyen",
+		PhoneNumber: "0901234567",
+		Birthdate:   &birthdate,
+		Locale:      "vi",
+		Role:        domain.RoleStudent,
+	}
+}
+
+func create(t *testing.T, repo domain.UserRepository, email string) *domain.User {
+	t.Helper()
+	user := newUser(email)
+	require.NoError(t, repo.Create(context.Background(), user))
+	return user
+}
+
+func testCreateAndFind(t *testing.T, repo domain.UserRepository) {
+	ctx := context.Background()
+	user := create(t, repo, "lan@example.com")
+	assert.NotZero(t, user.ID)
+	assert.False(t, user.CreatedAt.IsZero())
+	assert.False(t, user.UpdatedAt.IsZero())
+
+	for _, find := range []func() (*domain.User, error){
+		func() (*domain.User, error) { return repo.FindByID(ctx, user.ID) },
+		func() (*domain.User, error) { return repo.FindByEmail(ctx, "lan@example.com") },
+	} {
+		found, err := find()
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, found.ID)
+		assert.Equal(t, "lan@example.com", found.Email)
+		assert.Equal(t, "hashed", found.Password)
+		assert.Equal(t, "Lan", found.FirstName)
+		assert.Equal(t, "Nguyen", found.LastName)
+		assert.Equal(t, "0901234567", found.PhoneNumber)
+		require.NotNil(t, found.Birthdate)
+		assert.Equal(t, "2000-01-02", found.Birthdate.Format(time.DateOnly))
+		assert.Equal(t, "vi", found.Locale)
+		assert.Equal(t, domain.RoleStudent, found.Role)
+		assert.EqualValues(t, 1, found.Version)
+		assert.Nil(t, found.DeletedAt)
+	}
+
+	second := create(t, repo, "minh@example.com")
+	assert.NotEqual(t, user.ID, second.ID)
+}
+
+func testCreateDuplicateEmail(t *testing.T, repo domain.UserRepository) {
+	create(t, repo, "lan@example.com")
+	err := repo.Create(context.Background(), newUser("lan@example.com"))
+	assert.ErrorIs(t, err, domain.ErrEmailInUse)
+}
+
+func testFindMissing(t *testing.T, repo domain.UserRepository) {
+	ctx := context.Background()
+	_, err := repo.FindByID(ctx, 999)
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	_, err = repo.FindByEmail(ctx, "nobody@example.com")
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+}
+
+func testUpdate(t *testing.T, repo domain.UserRepository) {
+	ctx := context.Background()
+	user := create(t, repo, "lan@example.com")
+
+	user.FirstName = "Linh"
+	user.Role = domain.RoleAdmin
+	user.Birthdate = nil
+	require.NoError(t, repo.Update(ctx, user))
+
+	found, err := repo.FindByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Linh", found.FirstName)
+	assert.Equal(t, domain.RoleAdmin, found.Role)
+	assert.Nil(t, found.Birthdate)
+	assert.Equal(t, "lan@example.com", found.Email)
+	assert.WithinDuration(t, user.CreatedAt, found.CreatedAt, time.Millisecond, "Update keeps CreatedAt")
+}
+
+func testUpdateMissing(t *testing.T, repo domain.UserRepository) {
+	ctx := context.Background()
+	user := newUser("lan@example.com")
+	user.ID = 999
+	assert.ErrorIs(t, repo.Update(ctx, user), domain.ErrUserNotFound)
+
+	_, err := repo.FindByEmail(ctx, "lan@example.com")
+	assert.ErrorIs(t, err, domain.ErrUserNotFound, "Update must not insert")
+
+	deleted := create(t, repo, "minh@example.com")
+	require.NoError(t, repo.Delete(ctx, deleted.ID))
+	deleted.FirstName = "Changed"
+	assert.ErrorIs(t, repo.Update(ctx, deleted), domain.ErrUserNotFound)
+}
+
+func testUpdateVersion(t *testing.T, repo domain.UserRepository) {
+	ctx := context.Background()
+	user := create(t, repo, "lan@example.com")
+	assert.EqualValues(t, 1, user.Version)
+
+	stale := *user
+	user.FirstName = "Linh"
+	require.NoError(t, repo.Update(ctx, user))
+	assert.EqualValues(t, 2, user.Version)
+
+	stale.LastName = "Tran"
+	assert.ErrorIs(t, repo.Update(ctx, &stale), domain.ErrVersionConflict)
+	assert.EqualValues(t, 1, stale.Version, "a failed update leaves Version alone")
+
+	found, err := repo.FindByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, found.Version)
+	assert.Equal(t, "Linh", found.FirstName)
+	assert.Equal(t, "Nguyen", found.LastName, "the stale update was not applied")
+}
+
+func testUpdateDuplicateEmail(t *testing.T, repo domain.UserRepository) {
+	create(t, repo, "lan@example.com")
+	user := create(t, repo, "minh@example.com")
+
+	user.Email = "lan@example.com"
+	assert.ErrorIs(t, repo.Update(context.Background(), user), domain.ErrEmailInUse)
+}
+
+func testDeleteHidesUser(t *testing.T, repo domain.UserRepository) {
+	ctx := context.Background()
+	user := create(t, repo, "lan@example.com")
+	require.NoError(t, repo.Delete(ctx, user.ID))
+
+	_, err := repo.FindByID(ctx, user.ID)
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	_, err = repo.FindByEmail(ctx, "lan@example.com")
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+
+	// The email is free again once its owner is deleted.
+	create(t, repo, "lan@example.com")
+}
+
+func testDeleteMissing(t *testing.T, repo domain.UserRepository) {
+	ctx := context.Background()
+	assert.ErrorIs(t, repo.Delete(ctx, 999), domain.ErrUserNotFound)
+
+	user := create(t, repo, "lan@example.com")
+	require.NoError(t, repo.Delete(ctx, user.ID))
+	assert.ErrorIs(t, repo.Delete(ctx, user.ID), domain.ErrUserNotFound, "deleting twice")
+}
+
+// parse builds a query from a query string, as the handler does.
+func parse(t *testing.T, raw string) query.Query {
+	t.Helper()
+	values, err := url.ParseQuery(raw)
+	require.NoError(t, err)
+	q, err := domain.UserQuery.Parse(values)
+	require.NoError(t, err)
+	return q
+}
+
+func ids(users []domain.User) []uint {
+	out := make([]uint, len(users))
+	for i, u := range users {
+		out[i] = u.ID
+	}
+	return out
+}
+
+// createSpaced creates users with distinct creation times, oldest first.
+func createSpaced(t *testing.T, repo domain.UserRepository, emails ...string) []*domain.User {
+	users := make([]*domain.User, len(emails))
+	for i, email := range emails {
+		if i > 0 {
+			time.Sleep(5 * time.Millisecond)
+		}
+		users[i] = create(t, repo, email)
+	}
+	return users
+}
+
+func testList(t *testing.T, repo domain.UserRepository) {
+	ctx := context.Background()
+	users := createSpaced(t, repo, "lan@example.com", "minh@example.com", "an@example.com")
+	deleted := create(t, repo, "gone@example.com")
+	require.NoError(t, repo.Delete(ctx, deleted.ID))
+
+	page, err := repo.List(ctx, parse(t, ""))
+	require.NoError(t, err)
+	assert.Equal(t, []uint{users[2].ID, users[1].ID, users[0].ID}, ids(page.Items), "newest first by default, deleted excluded")
+	assert.Empty(t, page.NextCursor)
+	assert.Nil(t, page.Total, "no count unless asked")
+
+	page, err = repo.List(ctx, parse(t, "total=true&limit=1"))
+	require.NoError(t, err)
+	require.NotNil(t, page.Total)
+	assert.EqualValues(t, 3, *page.Total, "total ignores the page")
+	assert.Len(t, page.Items, 1)
+}
+
+func testListCursor(t *testing.T, repo domain.UserRepository) {
+	ctx := context.Background()
+	emails := []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com", "e@example.com"}
+	for _, email := range emails {
+		create(t, repo, email)
+	}
+
+	for _, sort := range []string{"email", "-email", "createdAt", "-createdAt", "id", "-id"} {
+		var seen []string
+		cursor := ""
+		for pages := 0; ; pages++ {
+			require.Lessf(t, pages, 5, "sort %s does not terminate", sort)
+			raw := "limit=2&sort=" + sort
+			if cursor != "" {
+				raw += "&cursor=" + url.QueryEscape(cursor)
+			}
+			page, err := repo.List(ctx, parse(t, raw))
+			require.NoError(t, err)
+			for _, u := range page.Items {
+				seen = append(seen, u.Email)
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			cursor = page.NextCursor
+		}
+
+		want := append([]string(nil), emails...)
+		if sort[0] == '-' {
+			for i, j := 0, len(want)-1; i < j; i, j = i+1, j-1 {
+				want[i], want[j] = want[j], want[i]
+			}
+		}
+		assert.Equalf(t, want, seen, "sort %s", sort)
+	}
+}
+
+func testListFilters(t *testing.T, repo domain.UserRepository) {
+	ctx := context.Background()
+	users := createSpaced(t, repo, "lan.nguyen@example.com", "minh@example.com", "LANH@example.com")
+	users[1].Role = domain.RoleAdmin
+	users[1].PhoneNumber = "+84909999999"
+	require.NoError(t, repo.Update(ctx, users[1]))
+
+	tests := []struct {
+		query string
+		want  []uint
+	}{
+		{"email[contains]=lan", []uint{users[0].ID, users[2].ID}},
+		{"email=minh@example.com", []uint{users[1].ID}},
+		{"role=admin", []uint{users[1].ID}},
+		{"phoneNumber=" + url.QueryEscape("+84909999999"), []uint{users[1].ID}},
+		{"phoneNumber=0901234567", []uint{users[0].ID, users[2].ID}},
+		{"phoneNumber=090123456", nil},
+		{"email[contains]=_", nil},
+		{"email[contains]=%25", nil},
+		{"createdAt[gte]=" + url.QueryEscape(users[1].CreatedAt.Format(time.RFC3339Nano)), []uint{users[1].ID, users[2].ID}},
+		{"createdAt[lt]=" + url.QueryEscape(users[1].CreatedAt.Format(time.RFC3339Nano)), []uint{users[0].ID}},
+		{"email[contains]=lan&role=student", []uint{users[0].ID, users[2].ID}},
+	}
+	for _, tt := range tests {
+		page, err := repo.List(ctx, parse(t, tt.query+"&sort=id"))
+		require.NoError(t, err)
+		assert.Equalf(t, tt.want, nilIfEmpty(ids(page.Items)), "query %s", tt.query)
+	}
+}
+
+func nilIfEmpty(ids []uint) []uint {
+	if len(ids) == 0 {
+		return nil
+	}
+	return ids
+}
+
+func testListDeleted(t *testing.T, repo domain.UserRepository) {
+	ctx := context.Background()
+	create(t, repo, "active@example.com")
+	first := create(t, repo, "lan@example.com")
+	second := create(t, repo, "minh@example.com")
+	require.NoError(t, repo.Delete(ctx, first.ID))
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, repo.Delete(ctx, second.ID))
+
+	users, count, err := repo.ListDeleted(ctx, 0, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+	require.Len(t, users, 2)
+	assert.Equal(t, second.ID, users[0].ID, "most recently deleted first")
+	assert.Equal(t, first.ID, users[1].ID)
+	assert.NotNil(t, users[0].DeletedAt)
+
+	users, count, err = repo.ListDeleted(ctx, 1, 1)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+	require.Len(t, users, 1)
+	assert.Equal(t, first.ID, users[0].ID)
+}
+
+func testRestore(t *testing.T, repo domain.UserRepository) {
+	ctx := context.Background()
+	user := create(t, repo, "lan@example.com")
+
+	assert.ErrorIs(t, repo.Restore(ctx, user.ID), domain.ErrUserNotFound, "active users cannot be restored")
+	assert.ErrorIs(t, repo.Restore(ctx, 999), domain.ErrUserNotFound)
+
+	require.NoError(t, repo.Delete(ctx, user.ID))
+	require.NoError(t, repo.Restore(ctx, user.ID))
+	assert.ErrorIs(t, repo.Restore(ctx, user.ID), domain.ErrUserNotFound, "a user is restored once")
+
+	found, err := repo.FindByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Nil(t, found.DeletedAt)
+}
+
+func testRestoreEmailTaken(t *testing.T, repo domain.UserRepository) {
+	ctx := context.Background()
+	user := create(t, repo, "lan@example.com")
+	require.NoError(t, repo.Delete(ctx, user.ID))
+	create(t, repo, "lan@example.com")
+
+	assert.ErrorIs(t, repo.Restore(ctx, user.ID), domain.ErrEmailInUse)
+
+	deleted, count, err := repo.ListDeleted(ctx, 0, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, count, "the user stays deleted")
+	require.Len(t, deleted, 1)
+	assert.Equal(t, user.ID, deleted[0].ID)
+}
+
+func testPurge(t *testing.T, repo domain.UserRepository) {
+	ctx := context.Background()
+	user := create(t, repo, "lan@example.com")
+
+	assert.ErrorIs(t, repo.Purge(ctx, user.ID), domain.ErrUserNotFound, "only deleted users can be purged")
+	assert.ErrorIs(t, repo.Purge(ctx, 999), domain.ErrUserNotFound)
+
+	require.NoError(t, repo.Delete(ctx, user.ID))
+	require.NoError(t, repo.Purge(ctx, user.ID))
+	assert.ErrorIs(t, repo.Purge(ctx, user.ID), domain.ErrUserNotFound, "a user is purged once")
+
+	_, count, err := repo.ListDeleted(ctx, 0, 10)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	assert.ErrorIs(t, repo.Restore(ctx, user.ID), domain.ErrUserNotFound)
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"english-learning/internal/modules/user/domain"
+	"english-learning/pkg/cron"
+	"english-learning/pkg/jobs"
+	"english-learning/pkg/logger"
+	"fmt"
+)
+
+// reencryptSchedule runs the re-encryption daily at 04:00 UTC, after the
+// session cleanup.
+var reencryptSchedule = cron.MustParse("0 4 * * *")
+
+// reencryptBatchSize is how many users are re-encrypted per query.
+const reencryptBatchSize = 500
+
+// RegisterJobs registers the user maintenance jobs and their schedules.
+func RegisterJobs(r jobs.Registry, repo domain.PersonalDataReencrypter) {
+	jobs.Handle(r, func(ctx context.Context, _ domain.ReencryptPersonalData) error {
+		rewritten, err := ReencryptPersonalData(ctx, repo)
+		if err != nil {
+			return err
+		}
+		logger.Infof("user", "Re-encrypted the personal data of %d user(s)", rewritten)
+		return nil
+	})
+	r.Schedule("user.reencrypt_personal_data", reencryptSchedule, domain.ReencryptPersonalData{})
+}
+
+// ReencryptPersonalData re-encrypts the personal data of every user that
+// needs it, batch by batch in ID order, and returns how many users it
+// rewrote. Each user is looked at once, so a row that keeps failing to be
+// rewritten cannot hold the loop; the next run picks it up. It is safe to
+// interrupt and to run concurrently with other writes.
+func ReencryptPersonalData(ctx context.Context, repo domain.PersonalDataReencrypter) (int, error) {
+	total := 0
+	var after uint
+	for {
+		last, rewritten, err := repo.ReencryptPersonalData(ctx, after, reencryptBatchSize)
+		total += rewritten
+		if err != nil {
+			return total, fmt.Errorf("re-encrypting personal data: %w", err)
+		}
+		if last == 0 {
+			return total, nil
+		}
+		after = last
+	}
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stuckReencrypter holds users whose rewrite never succeeds, as when every
+// row changes between being read and being rewritten.
+type stuckReencrypter struct {
+	ids   []uint
+	calls int
+}
+
+func (r *stuckReencrypter) ReencryptPersonalData(_ context.Context, after uint, limit int) (uint, int, error) {
+	r.calls++
+	var last uint
+	found := 0
+	for _, id := range r.ids {
+		if id > after && found < limit {
+			last = id
+			found++
+		}
+	}
+	return last, 0, nil
+}
+
+func TestReencryptPersonalData_EndsWhenRowsAreNotRewritten(t *testing.T) {
+	t.Parallel()
+	repo := &stuckReencrypter{}
+	for id := uint(1); id <= reencryptBatchSize+1; id++ {
+		repo.ids = append(repo.ids, id)
+	}
+
+	rewritten, err := ReencryptPersonalData(context.Background(), repo)
+
+	require.NoError(t, err)
+	assert.Zero(t, rewritten)
+	assert.Equal(t, 3, repo.calls, "two batches and the empty one that ends the run")
+}
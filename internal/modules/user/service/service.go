@@ -1,49 +1,77 @@
 package service
 
 import (
+	"context"
 	"english-learning/internal/modules/user/domain"
+	"english-learning/pkg/events"
+	"english-learning/pkg/query"
+	"english-learning/pkg/uow"
 	"errors"
 	"fmt"
 
+	"go.opentelemetry.io/otel"
 	"golang.org/x/crypto/bcrypt"
 )
 
-// Service implements domain.UserService.
+var tracer = otel.Tracer("english-learning/internal/modules/user/service")
+
+// Service implements domain.UserService. Every change is written together
+// with its domain event in one unit of work.
 type Service struct {
-	repo domain.UserRepository
+	repo   domain.UserRepository
+	uow    uow.UnitOfWork
+	events events.Publisher
 }
 
 // NewService creates a new user Service.
-func NewService(repo domain.UserRepository) *Service {
-	return &Service{repo: repo}
+func NewService(repo domain.UserRepository, unitOfWork uow.UnitOfWork, publisher events.Publisher) *Service {
+	return &Service{repo: repo, uow: unitOfWork, events: publisher}
 }
 
-func (s *Service) Create(req *domain.User) error {
-	existing, err := s.repo.FindByEmail(req.Email)
+// write runs change and publishes event in one transaction.
+func (s *Service) write(ctx context.Context, change func(ctx context.Context) error, event func() events.Event) error {
+	return s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := change(ctx); err != nil {
+			return err
+		}
+		if err := s.events.Publish(ctx, event()); err != nil {
+			return fmt.Errorf("publishing events: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *Service) Create(ctx context.Context, req *domain.User) error {
+	existing, err := s.repo.FindByEmail(ctx, req.Email)
 	if err == nil && existing != nil {
-		return errors.New("email already exists")
+		return domain.ErrEmailInUse
 	}
 
 	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
 		return fmt.Errorf("checking existing user: %w", err)
 	}
 
+	_, span := tracer.Start(ctx, "bcrypt.GenerateFromPassword")
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	span.End()
 	if err != nil {
 		return fmt.Errorf("hashing password: %w", err)
 	}
 
 	req.Password = string(hashedPassword)
 
-	if err := s.repo.Create(req); err != nil {
-		return fmt.Errorf("creating user: %w", err)
-	}
-
-	return nil
+	return s.write(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, req); err != nil {
+			return fmt.Errorf("creating user: %w", err)
+		}
+		return nil
+	}, func() events.Event {
+		return domain.UserCreated{UserID: req.ID, Email: req.Email}
+	})
 }
 
-func (s *Service) Get(id uint) (*domain.User, error) {
-	user, err := s.repo.FindByID(id)
+func (s *Service) Get(ctx context.Context, id uint) (*domain.User, error) {
+	user, err := s.repo.FindByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("finding user by id: %w", err)
 	}
@@ -51,31 +79,76 @@ func (s *Service) Get(id uint) (*domain.User, error) {
 	return user, nil
 }
 
-func (s *Service) Update(user *domain.User) error {
-	if user.Password != "" {
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+func (s *Service) Update(ctx context.Context, user *domain.User, newPassword string) error {
+	if newPassword != "" {
+		_, span := tracer.Start(ctx, "bcrypt.GenerateFromPassword")
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+		span.End()
 		if err != nil {
 			return fmt.Errorf("hashing password: %w", err)
 		}
 		user.Password = string(hashedPassword)
 	}
 
-	if err := s.repo.Update(user); err != nil {
-		return fmt.Errorf("updating user: %w", err)
+	return s.write(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, user); err != nil {
+			return fmt.Errorf("updating user: %w", err)
+		}
+		return nil
+	}, func() events.Event {
+		return domain.UserUpdated{UserID: user.ID}
+	})
+}
+
+// Edit runs the read-modify-write of a user in one unit of work. Because
+// Update checks the version edit started from, a concurrent change makes it
+// fail with domain.ErrVersionConflict instead of being overwritten.
+func (s *Service) Edit(ctx context.Context, id uint, edit func(user *domain.User) error) (*domain.User, error) {
+	var user *domain.User
+	err := s.write(ctx, func(ctx context.Context) error {
+		var err error
+		if user, err = s.repo.FindByID(ctx, id); err != nil {
+			return fmt.Errorf("finding user by id: %w", err)
+		}
+		if err := edit(user); err != nil {
+			return err
+		}
+		if err := s.repo.Update(ctx, user); err != nil {
+			return fmt.Errorf("updating user: %w", err)
+		}
+		return nil
+	}, func() events.Event {
+		return domain.UserUpdated{UserID: id}
+	})
+	if err != nil {
+		return nil, err
 	}
+	return user, nil
+}
 
-	return nil
+// Delete soft-deletes a user. UserDeleted is only published when the user
+// existed, since a missing user fails the unit of work.
+func (s *Service) Delete(ctx context.Context, id uint) error {
+	return s.write(ctx, func(ctx context.Context) error {
+		if err := s.repo.Delete(ctx, id); err != nil {
+			return fmt.Errorf("deleting user: %w", err)
+		}
+		return nil
+	}, func() events.Event {
+		return domain.UserDeleted{UserID: id}
+	})
 }
 
-func (s *Service) Delete(id uint) error {
-	if err := s.repo.Delete(id); err != nil {
-		return fmt.Errorf("deleting user: %w", err)
+func (s *Service) List(ctx context.Context, q query.Query) (query.Page[domain.User], error) {
+	page, err := s.repo.List(ctx, q)
+	if err != nil {
+		return query.Page[domain.User]{}, fmt.Errorf("listing users: %w", err)
 	}
 
-	return nil
+	return page, nil
 }
 
-func (s *Service) List(page, pageSize int) ([]domain.User, int64, error) {
+func (s *Service) ListDeleted(ctx context.Context, page, pageSize int) ([]domain.User, int64, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -84,10 +157,32 @@ func (s *Service) List(page, pageSize int) ([]domain.User, int64, error) {
 	}
 	offset := (page - 1) * pageSize
 
-	users, count, err := s.repo.List(offset, pageSize)
+	users, count, err := s.repo.ListDeleted(ctx, offset, pageSize)
 	if err != nil {
-		return nil, 0, fmt.Errorf("listing users: %w", err)
+		return nil, 0, fmt.Errorf("listing deleted users: %w", err)
 	}
 
 	return users, count, nil
 }
+
+func (s *Service) Restore(ctx context.Context, id uint) error {
+	return s.write(ctx, func(ctx context.Context) error {
+		if err := s.repo.Restore(ctx, id); err != nil {
+			return fmt.Errorf("restoring user: %w", err)
+		}
+		return nil
+	}, func() events.Event {
+		return domain.UserRestored{UserID: id}
+	})
+}
+
+func (s *Service) Purge(ctx context.Context, id uint) error {
+	return s.write(ctx, func(ctx context.Context) error {
+		if err := s.repo.Purge(ctx, id); err != nil {
+			return fmt.Errorf("purging user: %w", err)
+		}
+		return nil
+	}, func() events.Event {
+		return domain.UserPurged{UserID: id}
+	})
+}
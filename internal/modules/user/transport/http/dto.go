@@ -17,8 +17,12 @@ type UserResponseDTO struct {
 	LastName    string     `json:"lastName"`
 	PhoneNumber string     `json:"phoneNumber"`
 	Birthdate   *time.Time `json:"birthdate"`
+	Locale      string     `json:"locale"`
+	Role        string     `json:"role"`
+	Version     int64      `json:"version"`
 	CreatedAt   time.Time  `json:"createdAt"`
 	UpdatedAt   time.Time  `json:"updatedAt"`
+	DeletedAt   *time.Time `json:"deletedAt,omitempty"`
 }
 
 type UpdateUserRequestDTO struct {
@@ -26,6 +30,28 @@ type UpdateUserRequestDTO struct {
 	LastName    string     `json:"lastName" binding:"required"`
 	PhoneNumber string     `json:"phoneNumber"`
 	Birthdate   *time.Time `json:"birthdate"`
+	Locale      string     `json:"locale" binding:"omitempty,oneof=en vi"`
+}
+
+// ToUpdateUserRequest returns the editable profile of user, the document a
+// merge patch applies to.
+func ToUpdateUserRequest(user *domain.User) UpdateUserRequestDTO {
+	return UpdateUserRequestDTO{
+		FirstName:   user.FirstName,
+		LastName:    user.LastName,
+		PhoneNumber: user.PhoneNumber,
+		Birthdate:   user.Birthdate,
+		Locale:      user.Locale,
+	}
+}
+
+// applyTo copies the profile fields onto user.
+func (r UpdateUserRequestDTO) applyTo(user *domain.User) {
+	user.FirstName = r.FirstName
+	user.LastName = r.LastName
+	user.PhoneNumber = r.PhoneNumber
+	user.Birthdate = r.Birthdate
+	user.Locale = r.Locale
 }
 
 func ToUserResponse(user *domain.User) UserResponseDTO {
@@ -39,8 +65,12 @@ func ToUserResponse(user *domain.User) UserResponseDTO {
 		LastName:    user.LastName,
 		PhoneNumber: user.PhoneNumber,
 		Birthdate:   user.Birthdate,
+		Locale:      user.Locale,
+		Role:        user.Role,
+		Version:     user.Version,
 		CreatedAt:   user.CreatedAt,
 		UpdatedAt:   user.UpdatedAt,
+		DeletedAt:   user.DeletedAt,
 	}
 }
 
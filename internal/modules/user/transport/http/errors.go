@@ -0,0 +1,13 @@
+package http
+
+import (
+	"english-learning/internal/modules/user/domain"
+	"english-learning/pkg/response"
+	"net/http"
+)
+
+func init() {
+	response.RegisterError(domain.ErrUserNotFound, http.StatusNotFound, response.CodeUserNotFound, response.MsgUserNotFound)
+	response.RegisterError(domain.ErrEmailInUse, http.StatusConflict, response.CodeEmailInUse, response.MsgEmailInUse)
+	response.RegisterError(domain.ErrVersionConflict, http.StatusPreconditionFailed, response.CodeVersionConflict, response.MsgUserVersionConflict)
+}
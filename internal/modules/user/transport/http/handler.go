@@ -1,13 +1,19 @@
 package http
 
 import (
+	"bytes"
+	"encoding/json"
 	"english-learning/internal/modules/user/domain"
+	"english-learning/pkg/etag"
+	"english-learning/pkg/mergepatch"
+	"english-learning/pkg/query"
 	"english-learning/pkg/response"
-	"english-learning/pkg/validation"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
 // UserHandler handles HTTP requests for user operations.
@@ -23,7 +29,7 @@ func NewUserHandler(service domain.UserService) *UserHandler {
 func (h *UserHandler) Create(c *gin.Context) {
 	var req RegisterRequestDTO
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.Error(c, http.StatusBadRequest, response.CodeBadRequest, validation.FormatError(err))
+		response.BindError(c, err)
 		return
 	}
 
@@ -33,14 +39,16 @@ func (h *UserHandler) Create(c *gin.Context) {
 		Password: req.Password,
 	}
 
-	if err := h.service.Create(domainReq); err != nil {
-		response.Error(c, http.StatusBadRequest, response.CodeBadRequest, err.Error())
+	if err := h.service.Create(c.Request.Context(), domainReq); err != nil {
+		response.HandleError(c, err)
 		return
 	}
 
 	response.Created(c, nil, response.MsgUserCreated)
 }
 
+// Get serves GET /users/:id. The ETag is the user's version; a client
+// revalidating with If-None-Match gets 304 while its copy is current.
 func (h *UserHandler) Get(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
@@ -49,16 +57,35 @@ func (h *UserHandler) Get(c *gin.Context) {
 		return
 	}
 
-	user, err := h.service.Get(uint(id))
+	user, err := h.service.Get(c.Request.Context(), uint(id))
 	if err != nil {
-		response.Error(c, http.StatusNotFound, response.CodeNotFound, response.MsgUserNotFound)
+		response.HandleError(c, err)
+		return
+	}
+
+	tag := etag.FromVersion(user.Version)
+	c.Header("ETag", tag)
+	if etag.NoneMatch(c.GetHeader("If-None-Match"), tag) {
+		c.Status(http.StatusNotModified)
 		return
 	}
 
 	response.Success(c, ToUserResponse(user), response.MsgSuccess)
 }
 
+// Update serves PUT /users/:id, replacing the whole profile. The request
+// must carry If-Match.
 func (h *UserHandler) Update(c *gin.Context) {
+	h.update(c, true)
+}
+
+// UpdateLegacy serves PUT /users/:id to apps released before ETags, which
+// send no If-Match. One that is sent is still checked.
+func (h *UserHandler) UpdateLegacy(c *gin.Context) {
+	h.update(c, false)
+}
+
+func (h *UserHandler) update(c *gin.Context, ifMatchRequired bool) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
@@ -66,26 +93,130 @@ func (h *UserHandler) Update(c *gin.Context) {
 		return
 	}
 
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatchRequired && !requireIfMatch(c, ifMatch) {
+		return
+	}
+
 	var req UpdateUserRequestDTO
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.Error(c, http.StatusBadRequest, response.CodeBadRequest, validation.FormatError(err))
+		response.BindError(c, err)
+		return
+	}
+
+	user, err := h.service.Edit(c.Request.Context(), uint(id), func(user *domain.User) error {
+		if err := checkIfMatch(ifMatch, user); err != nil {
+			return err
+		}
+		req.applyTo(user)
+		return nil
+	})
+	if err != nil {
+		response.HandleError(c, err)
 		return
 	}
 
-	user := &domain.User{
-		ID:          uint(id),
-		FirstName:   req.FirstName,
-		LastName:    req.LastName,
-		PhoneNumber: req.PhoneNumber,
-		Birthdate:   req.Birthdate,
+	c.Header("ETag", etag.FromVersion(user.Version))
+	response.Success(c, nil, response.MsgUserUpdated)
+}
+
+// patchError marks a merge patch that does not apply to the profile.
+type patchError struct{ err error }
+
+func (e *patchError) Error() string { return e.err.Error() }
+
+// Patch serves PATCH /users/:id with a JSON Merge Patch of the profile
+// fields of UpdateUserRequestDTO: members present are replaced, members set
+// to null are cleared and the rest is left alone.
+func (h *UserHandler) Patch(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, response.CodeBadRequest, response.MsgInvalidID)
+		return
 	}
 
-	if err := h.service.Update(user); err != nil {
-		response.Error(c, http.StatusInternalServerError, response.CodeServerInternalError, err.Error())
+	ifMatch := c.GetHeader("If-Match")
+	if !requireIfMatch(c, ifMatch) {
 		return
 	}
 
-	response.Success(c, nil, response.MsgUserUpdated)
+	if ct := c.ContentType(); ct != mergepatch.ContentType && ct != binding.MIMEJSON {
+		response.Error(c, http.StatusUnsupportedMediaType, response.CodeUnsupportedMediaType, response.MsgUnsupportedMediaType)
+		return
+	}
+	patch, err := c.GetRawData()
+	if err != nil {
+		response.BindError(c, err)
+		return
+	}
+
+	user, err := h.service.Edit(c.Request.Context(), uint(id), func(user *domain.User) error {
+		if err := checkIfMatch(ifMatch, user); err != nil {
+			return err
+		}
+		req, err := patchProfile(user, patch)
+		if err != nil {
+			return err
+		}
+		req.applyTo(user)
+		return nil
+	})
+	var perr *patchError
+	if errors.As(err, &perr) {
+		response.BindError(c, perr.err)
+		return
+	}
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	c.Header("ETag", etag.FromVersion(user.Version))
+	response.Success(c, ToUserResponse(user), response.MsgUserUpdated)
+}
+
+// requireIfMatch answers a change without an If-Match header with 428 and
+// reports whether it had one. Without it, a client could overwrite someone
+// else's change without noticing.
+func requireIfMatch(c *gin.Context, ifMatch string) bool {
+	if ifMatch == "" {
+		response.Error(c, http.StatusPreconditionRequired, response.CodePreconditionRequired, response.MsgIfMatchRequired)
+		return false
+	}
+	return true
+}
+
+// checkIfMatch enforces an If-Match header, if any, against the stored user.
+func checkIfMatch(ifMatch string, user *domain.User) error {
+	if ifMatch != "" && !etag.Match(ifMatch, etag.FromVersion(user.Version)) {
+		return domain.ErrVersionConflict
+	}
+	return nil
+}
+
+// patchProfile applies patch to user's profile and validates the result as
+// PUT would.
+func patchProfile(user *domain.User, patch []byte) (UpdateUserRequestDTO, error) {
+	current, err := json.Marshal(ToUpdateUserRequest(user))
+	if err != nil {
+		return UpdateUserRequestDTO{}, err
+	}
+	patched, err := mergepatch.Apply(current, patch)
+	if err != nil {
+		return UpdateUserRequestDTO{}, &patchError{err}
+	}
+
+	var req UpdateUserRequestDTO
+	dec := json.NewDecoder(bytes.NewReader(patched))
+	dec.DisallowUnknownFields() // e.g. email, which cannot be changed here
+	if err := dec.Decode(&req); err != nil {
+		return UpdateUserRequestDTO{}, &patchError{err}
+	}
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		return UpdateUserRequestDTO{}, &patchError{err}
+	}
+	return req, nil
 }
 
 func (h *UserHandler) Delete(c *gin.Context) {
@@ -96,27 +227,109 @@ func (h *UserHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.Delete(uint(id)); err != nil {
-		response.Error(c, http.StatusInternalServerError, response.CodeServerInternalError, err.Error())
+	if err := h.service.Delete(c.Request.Context(), uint(id)); err != nil {
+		response.HandleError(c, err)
 		return
 	}
 
 	response.Success(c, nil, response.MsgUserDeleted)
 }
 
+// defaultPageSize is the page size of the legacy offset-paginated listings.
+const defaultPageSize = 10
+
+// List serves GET /v2/users, paginated with keyset cursors; see pkg/query for
+// the parameters and domain.UserQuery for the fields.
 func (h *UserHandler) List(c *gin.Context) {
-	pageStr := c.DefaultQuery("page", "1")
-	pageSizeStr := c.DefaultQuery("page_size", "10")
+	q, err := domain.UserQuery.Parse(c.Request.URL.Query())
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	page, err := h.service.List(c.Request.Context(), q)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.SuccessCursor(c, ToUserListResponse(page.Items), page.NextCursor, page.Total, response.MsgSuccess)
+}
+
+// ListV1 serves GET /v1/users: offset pages, as before cursors, when the
+// request has page or page_size, and cursor pages otherwise.
+func (h *UserHandler) ListV1(c *gin.Context) {
+	if query.HasPageParams(c.Request.URL.Query()) {
+		h.ListOffset(c)
+		return
+	}
+	h.List(c)
+}
 
-	page, _ := strconv.Atoi(pageStr)
-	pageSize, _ := strconv.Atoi(pageSizeStr)
+// ListOffset serves GET /users on the unversioned routes, which predate
+// cursors: pages are selected with page and page_size and come in the
+// {items, total, page, size} envelope. Filters and sort work as in List.
+func (h *UserHandler) ListOffset(c *gin.Context) {
+	q, page, pageSize, err := domain.UserQuery.ParseOffset(c.Request.URL.Query(), defaultPageSize)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	result, err := h.service.List(c.Request.Context(), q)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.SuccessList(c, ToUserListResponse(result.Items), *result.Total, page, pageSize, response.MsgSuccess)
+}
+
+func (h *UserHandler) ListDeleted(c *gin.Context) {
+	page, pageSize, err := query.ParsePage(c.Request.URL.Query(), defaultPageSize, domain.UserQuery.MaxLimit)
+	if err != nil {
+		response.HandleError(c, err)
+		return
+	}
 
-	users, count, err := h.service.List(page, pageSize)
+	users, count, err := h.service.ListDeleted(c.Request.Context(), page, pageSize)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, response.CodeServerInternalError, err.Error())
+		response.HandleError(c, err)
 		return
 	}
 
 	resp := ToUserListResponse(users)
 	response.SuccessList(c, resp, count, page, pageSize, response.MsgSuccess)
 }
+
+func (h *UserHandler) Restore(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, response.CodeBadRequest, response.MsgInvalidID)
+		return
+	}
+
+	if err := h.service.Restore(c.Request.Context(), uint(id)); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.Success(c, nil, response.MsgUserRestored)
+}
+
+func (h *UserHandler) Purge(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, response.CodeBadRequest, response.MsgInvalidID)
+		return
+	}
+
+	if err := h.service.Purge(c.Request.Context(), uint(id)); err != nil {
+		response.HandleError(c, err)
+		return
+	}
+
+	response.Success(c, nil, response.MsgUserPurged)
+}
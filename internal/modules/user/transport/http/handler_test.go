@@ -0,0 +1,361 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"english-learning/internal/modules/user/domain"
+	"english-learning/internal/modules/user/repository/memory"
+	"english-learning/internal/modules/user/service"
+	"english-learning/pkg/events"
+	"english-learning/pkg/response"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// directUnitOfWork runs functions without a transaction, which is all the
+// memory repository supports.
+type directUnitOfWork struct{}
+
+func (directUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type nopPublisher struct{}
+
+func (nopPublisher) Publish(context.Context, ...events.Event) error { return nil }
+
+// setup returns a router backed by the real service over an in-memory
+// repository holding one user.
+func setup(t *testing.T) (*gin.Engine, *memory.UserRepository, *domain.User) {
+	t.Helper()
+	repo := memory.NewUserRepository()
+	birthdate := time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC)
+	user := &domain.User{
+		Email:       "lan@example.com",
+		Password:    "hashed",
+		FirstName:   "Lan",
+		LastName:    "Nguyen",
+		PhoneNumber: "+84901234567",
+		Birthdate:   &birthdate,
+		Role:        domain.RoleStudent,
+	}
+	require.NoError(t, repo.Create(context.Background(), user))
+
+	h := NewUserHandler(service.NewService(repo, directUnitOfWork{}, nopPublisher{}))
+	r := gin.New()
+	r.GET("/users/:id", h.Get)
+	r.PUT("/users/:id", h.Update)
+	r.PUT("/v0/users/:id", h.UpdateLegacy)
+	r.PATCH("/users/:id", h.Patch)
+	r.GET("/users/deleted", h.ListDeleted)
+	r.GET("/v0/users", h.ListOffset)
+	r.GET("/v1/users", h.ListV1)
+	r.GET("/v2/users", h.List)
+	return r, repo, user
+}
+
+func request(r *gin.Engine, method, path, body string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func decodeCode(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body response.APIResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return body.Code
+}
+
+func stored(t *testing.T, repo *memory.UserRepository, id uint) *domain.User {
+	t.Helper()
+	user, err := repo.FindByID(context.Background(), id)
+	require.NoError(t, err)
+	return user
+}
+
+func TestGetHandler_ETag(t *testing.T) {
+	t.Parallel()
+	r, _, _ := setup(t)
+
+	w := request(r, http.MethodGet, "/users/1", "", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"1"`, w.Header().Get("ETag"))
+	assert.Contains(t, w.Body.String(), `"version":1`)
+
+	w = request(r, http.MethodGet, "/users/1", "", map[string]string{"If-None-Match": `"1"`})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	w = request(r, http.MethodGet, "/users/1", "", map[string]string{"If-None-Match": `"0"`})
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestPatchHandler_MergesProfile(t *testing.T) {
+	t.Parallel()
+	r, repo, user := setup(t)
+
+	w := request(r, http.MethodPatch, "/users/1", `{"firstName":"Linh","birthdate":null}`, map[string]string{"If-Match": `"1"`})
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+	got := stored(t, repo, user.ID)
+	assert.Equal(t, "Linh", got.FirstName)
+	assert.Nil(t, got.Birthdate, "null clears a member")
+	assert.Equal(t, "Nguyen", got.LastName, "absent members are kept")
+	assert.Equal(t, "+84901234567", got.PhoneNumber)
+	assert.Equal(t, "lan@example.com", got.Email)
+	assert.Equal(t, "hashed", got.Password)
+	assert.EqualValues(t, 2, got.Version)
+}
+
+func TestPatchHandler_StaleIfMatch(t *testing.T) {
+	t.Parallel()
+	r, repo, user := setup(t)
+	require.Equal(t, http.StatusOK, request(r, http.MethodPatch, "/users/1", `{"lastName":"Tran"}`, map[string]string{"If-Match": "*"}).Code)
+
+	w := request(r, http.MethodPatch, "/users/1", `{"firstName":"Linh"}`, map[string]string{"If-Match": `"1"`})
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.Equal(t, response.CodeVersionConflict, decodeCode(t, w))
+	assert.Equal(t, "Lan", stored(t, repo, user.ID).FirstName)
+}
+
+func TestPatchHandler_RejectsInvalidPatches(t *testing.T) {
+	t.Parallel()
+	r, repo, user := setup(t)
+
+	tests := []struct {
+		name string
+		body string
+		code string
+	}{
+		{"clears a required member", `{"firstName":null}`, response.CodeValidationFailed},
+		{"invalid locale", `{"locale":"fr"}`, response.CodeValidationFailed},
+		{"not editable here", `{"email":"x@example.com"}`, response.CodeBadRequest},
+		{"wrong type", `{"firstName":5}`, response.CodeBadRequest},
+		{"not an object", `["firstName"]`, response.CodeBadRequest},
+		{"malformed", `{"firstName":`, response.CodeBadRequest},
+	}
+	for _, tt := range tests {
+		w := request(r, http.MethodPatch, "/users/1", tt.body, map[string]string{"If-Match": `"1"`})
+		assert.Equalf(t, http.StatusBadRequest, w.Code, tt.name)
+		assert.Equalf(t, tt.code, decodeCode(t, w), tt.name)
+	}
+	assert.EqualValues(t, 1, stored(t, repo, user.ID).Version, "nothing was saved")
+}
+
+func TestPatchHandler_UnsupportedMediaType(t *testing.T) {
+	t.Parallel()
+	r, _, _ := setup(t)
+
+	w := request(r, http.MethodPatch, "/users/1", `{"firstName":"Linh"}`, map[string]string{
+		"Content-Type": "text/plain",
+		"If-Match":     `"1"`,
+	})
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+}
+
+func TestPatchHandler_UnknownUser(t *testing.T) {
+	t.Parallel()
+	r, _, _ := setup(t)
+
+	w := request(r, http.MethodPatch, "/users/99", `{"firstName":"Linh"}`, map[string]string{"If-Match": "*"})
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestChangeHandlers_RequireIfMatch(t *testing.T) {
+	t.Parallel()
+	r, repo, user := setup(t)
+
+	for _, method := range []string{http.MethodPut, http.MethodPatch} {
+		w := request(r, method, "/users/1", `{"firstName":"Linh","lastName":"Tran"}`, map[string]string{"Content-Type": "application/json"})
+		assert.Equalf(t, http.StatusPreconditionRequired, w.Code, method)
+		assert.Equalf(t, response.CodePreconditionRequired, decodeCode(t, w), method)
+	}
+	assert.EqualValues(t, 1, stored(t, repo, user.ID).Version, "nothing was saved")
+}
+
+func TestUpdateLegacyHandler_IfMatchOptional(t *testing.T) {
+	t.Parallel()
+	r, repo, user := setup(t)
+	header := map[string]string{"Content-Type": "application/json"}
+
+	w := request(r, http.MethodPut, "/v0/users/1", `{"firstName":"Linh","lastName":"Tran"}`, header)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "Linh", stored(t, repo, user.ID).FirstName)
+
+	header["If-Match"] = `"1"`
+	w = request(r, http.MethodPut, "/v0/users/1", `{"firstName":"An","lastName":"Le"}`, header)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code, "an If-Match sent is checked")
+}
+
+// PUT used to save a user holding only the profile fields, wiping the
+// email and password hash.
+func TestUpdateHandler_KeepsCredentials(t *testing.T) {
+	t.Parallel()
+	r, repo, user := setup(t)
+
+	w := request(r, http.MethodPut, "/users/1", `{"firstName":"Linh","lastName":"Tran"}`, map[string]string{
+		"Content-Type": "application/json",
+		"If-Match":     `"1"`,
+	})
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+	got := stored(t, repo, user.ID)
+	assert.Equal(t, "Linh", got.FirstName)
+	assert.Equal(t, "lan@example.com", got.Email)
+	assert.Equal(t, "hashed", got.Password)
+	assert.Equal(t, domain.RoleStudent, got.Role)
+	assert.Empty(t, got.PhoneNumber, "PUT replaces the whole profile")
+
+	w = request(r, http.MethodPut, "/users/1", `{"firstName":"An","lastName":"Le"}`, map[string]string{
+		"Content-Type": "application/json",
+		"If-Match":     `"1"`,
+	})
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+}
+
+func decodeData(t *testing.T, w *httptest.ResponseRecorder) map[string]interface{} {
+	t.Helper()
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return body.Data
+}
+
+func TestListHandlers_Pagination(t *testing.T) {
+	t.Parallel()
+	r, repo, _ := setup(t)
+	require.NoError(t, repo.Create(context.Background(), &domain.User{Email: "minh@example.com", Password: "hashed", Role: domain.RoleStudent}))
+
+	// Offset pages, the only kind on the unversioned routes and still
+	// accepted by v1.
+	for _, path := range []string{"/v0/users?page=2&page_size=1&sort=id", "/v1/users?page=2&page_size=1&sort=id"} {
+		w := request(r, http.MethodGet, path, "", nil)
+		require.Equalf(t, http.StatusOK, w.Code, "%s: %s", path, w.Body.String())
+		data := decodeData(t, w)
+		assert.EqualValuesf(t, 2, data["total"], path)
+		assert.EqualValuesf(t, 2, data["page"], path)
+		assert.EqualValuesf(t, 1, data["size"], path)
+		require.Lenf(t, data["items"], 1, path)
+		assert.Equalf(t, "minh@example.com", data["items"].([]interface{})[0].(map[string]interface{})["email"], path)
+	}
+	data := decodeData(t, request(r, http.MethodGet, "/v0/users", "", nil))
+	assert.EqualValues(t, 10, data["size"], "the unversioned route defaults to offset pages")
+
+	// Cursor pages on v1 without page parameters, and always on v2.
+	for _, path := range []string{"/v1/users?limit=1", "/v2/users?limit=1"} {
+		w := request(r, http.MethodGet, path, "", nil)
+		require.Equalf(t, http.StatusOK, w.Code, "%s: %s", path, w.Body.String())
+		data := decodeData(t, w)
+		assert.NotEmptyf(t, data["nextCursor"], path)
+		assert.NotContainsf(t, data, "page", path)
+	}
+
+	for _, path := range []string{"/v2/users?page=2", "/v1/users?page_size=101", "/v1/users?page=2&cursor=abc", "/users/deleted?page_size=101"} {
+		w := request(r, http.MethodGet, path, "", nil)
+		assert.Equalf(t, http.StatusBadRequest, w.Code, path)
+		assert.Equalf(t, response.CodeInvalidQuery, decodeCode(t, w), path)
+	}
+}
+
+// recordingPublisher keeps the names of the events published through it.
+type recordingPublisher struct {
+	names []string
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, evts ...events.Event) error {
+	for _, e := range evts {
+		p.names = append(p.names, e.EventName())
+	}
+	return nil
+}
+
+func TestDelete_PublishesOnlyWhenAUserWasDeleted(t *testing.T) {
+	t.Parallel()
+	repo := memory.NewUserRepository()
+	user := &domain.User{Email: "lan@example.com", Password: "hashed", Role: domain.RoleStudent}
+	require.NoError(t, repo.Create(context.Background(), user))
+	publisher := &recordingPublisher{}
+	h := NewUserHandler(service.NewService(repo, directUnitOfWork{}, publisher))
+	r := gin.New()
+	r.DELETE("/users/:id", h.Delete)
+
+	w := request(r, http.MethodDelete, "/users/1", "", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	for _, path := range []string{"/users/1", "/users/999"} {
+		w = request(r, http.MethodDelete, path, "", nil)
+		assert.Equalf(t, http.StatusNotFound, w.Code, path)
+		assert.Equalf(t, response.CodeUserNotFound, decodeCode(t, w), path)
+	}
+	assert.Equal(t, []string{domain.EventUserDeleted}, publisher.names)
+}
+
+func TestRestoreAndPurgeHandlers(t *testing.T) {
+	t.Parallel()
+	repo := memory.NewUserRepository()
+	ctx := context.Background()
+	restored := &domain.User{Email: "lan@example.com", Password: "hashed", Role: domain.RoleStudent}
+	taken := &domain.User{Email: "minh@example.com", Password: "hashed", Role: domain.RoleStudent}
+	for _, u := range []*domain.User{restored, taken} {
+		require.NoError(t, repo.Create(ctx, u))
+		require.NoError(t, repo.Delete(ctx, u.ID))
+	}
+	require.NoError(t, repo.Create(ctx, &domain.User{Email: "minh@example.com", Password: "hashed", Role: domain.RoleStudent}))
+	publisher := &recordingPublisher{}
+	h := NewUserHandler(service.NewService(repo, directUnitOfWork{}, publisher))
+	r := gin.New()
+	r.POST("/users/:id/restore", h.Restore)
+	r.DELETE("/users/:id/purge", h.Purge)
+
+	w := request(r, http.MethodPost, "/users/1/restore", "", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Nil(t, stored(t, repo, restored.ID).DeletedAt)
+
+	for _, tt := range []struct {
+		method, path string
+		status       int
+		code         string
+	}{
+		{http.MethodPost, "/users/1/restore", http.StatusNotFound, response.CodeUserNotFound},
+		{http.MethodPost, "/users/999/restore", http.StatusNotFound, response.CodeUserNotFound},
+		{http.MethodPost, "/users/2/restore", http.StatusConflict, response.CodeEmailInUse},
+		{http.MethodDelete, "/users/1/purge", http.StatusNotFound, response.CodeUserNotFound},
+		{http.MethodDelete, "/users/999/purge", http.StatusNotFound, response.CodeUserNotFound},
+	} {
+		w = request(r, tt.method, tt.path, "", nil)
+		assert.Equalf(t, tt.status, w.Code, "%s %s", tt.method, tt.path)
+		assert.Equalf(t, tt.code, decodeCode(t, w), "%s %s", tt.method, tt.path)
+	}
+
+	w = request(r, http.MethodDelete, "/users/2/purge", "", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = request(r, http.MethodDelete, "/users/2/purge", "", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	assert.Equal(t, []string{domain.EventUserRestored, domain.EventUserPurged}, publisher.names)
+}
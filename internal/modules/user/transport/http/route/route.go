@@ -2,21 +2,40 @@ package route
 
 import (
 	"english-learning/configs"
+	"english-learning/internal/modules/user/domain"
 	handler "english-learning/internal/modules/user/transport/http"
+	"english-learning/pkg/apiversion"
 	"english-learning/pkg/middleware"
 
 	"github.com/gin-gonic/gin"
 )
 
-// Register registers all user routes on the given router.
-func Register(r *gin.Engine, cfg *configs.Config, h *handler.UserHandler) {
+// Register registers all user routes on the given router. idempotent guards
+// the routes that accept an Idempotency-Key header. Users may read, edit and
+// delete their own account; everything else requires the admin role.
+func Register(r gin.IRouter, cfg *configs.Config, h *handler.UserHandler, idempotent gin.HandlerFunc) {
 	group := r.Group("/users")
 	group.Use(middleware.AuthMiddleware(cfg.JWT))
+
+	self := group.Group("/:id")
+	self.Use(middleware.RequireSelfOrRole("id", domain.RoleAdmin))
+	{
+		self.GET("", h.Get)
+		// Apps released before versioning send no If-Match.
+		self.PUT("", apiversion.Switch(map[int]gin.HandlerFunc{0: h.UpdateLegacy, 1: h.Update}))
+		// PATCH came with /v1; the unprefixed alias answers 404.
+		self.PATCH("", apiversion.Switch(map[int]gin.HandlerFunc{1: h.Patch}))
+		self.DELETE("", h.Delete)
+	}
+
+	admin := group.Group("")
+	admin.Use(middleware.RequireRole(domain.RoleAdmin))
 	{
-		group.POST("", h.Create)
-		group.GET("", h.List)
-		group.GET("/:id", h.Get)
-		group.PUT("/:id", h.Update)
-		group.DELETE("/:id", h.Delete)
+		admin.POST("", idempotent, h.Create)
+		// Version 2 dropped the offset pagination of earlier versions.
+		admin.GET("", apiversion.Switch(map[int]gin.HandlerFunc{0: h.ListOffset, 1: h.ListV1, 2: h.List}))
+		admin.GET("/deleted", h.ListDeleted)
+		admin.POST("/:id/restore", h.Restore)
+		admin.DELETE("/:id/purge", h.Purge)
 	}
 }
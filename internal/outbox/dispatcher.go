@@ -0,0 +1,242 @@
+// Package outbox implements events.Publisher with a transactional outbox
+// table, and a Dispatcher that delivers the stored events to in-process
+// subscribers once their transaction has committed.
+package outbox
+
+import (
+	"context"
+	"english-learning/configs"
+	"english-learning/pkg/events"
+	"english-learning/pkg/logger"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Dispatcher polls outbox_events and hands each event to the handlers
+// subscribed to its name. Delivery is at-least-once: an event is marked
+// processed only after every handler succeeded, and a failure retries the
+// event for all of them with exponential backoff. Events are claimed with
+// FOR UPDATE SKIP LOCKED under a lease of cfg.LockTimeout, so several
+// instances can dispatch concurrently; events whose dispatcher vanished are
+// claimed again once their lease runs out.
+type Dispatcher struct {
+	db  *gorm.DB
+	cfg configs.OutboxConfig
+
+	mu       sync.RWMutex
+	handlers map[string][]events.Handler
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewDispatcher creates a Dispatcher for db. Register handlers with Subscribe
+// before calling Start.
+func NewDispatcher(db *gorm.DB, cfg configs.OutboxConfig) *Dispatcher {
+	return &Dispatcher{
+		db:       db,
+		cfg:      cfg,
+		handlers: make(map[string][]events.Handler),
+	}
+}
+
+// Subscribe implements events.Subscriber.
+func (d *Dispatcher) Subscribe(name string, h events.Handler) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.handlers[name] = append(d.handlers[name], h)
+}
+
+// Start launches the polling loop. It has the signature of a lifecycle
+// OnStart hook.
+func (d *Dispatcher) Start(context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+	d.done = make(chan struct{})
+	go d.run(ctx)
+	return nil
+}
+
+// Stop ends the polling loop, letting the batch in flight finish unless ctx
+// expires first.
+func (d *Dispatcher) Stop(ctx context.Context) error {
+	if d.cancel == nil {
+		return nil
+	}
+	d.cancel()
+	select {
+	case <-d.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("waiting for outbox dispatcher: %w", ctx.Err())
+	}
+}
+
+func (d *Dispatcher) run(ctx context.Context) {
+	defer close(d.done)
+
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for ctx.Err() == nil {
+		// A batch is not interrupted by Stop: cancelling it half way would
+		// redeliver events whose handlers already ran.
+		n, err := d.DispatchBatch(context.WithoutCancel(ctx))
+		if err != nil {
+			logger.Errorf("outbox", "Dispatching events: %v", err)
+		}
+		if err == nil && n == d.cfg.BatchSize {
+			continue // more events are probably waiting
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+		}
+	}
+}
+
+// errLockExpired is recorded on events whose dispatcher vanished on their
+// last attempt.
+var errLockExpired = errors.New("lock expired before the event was delivered")
+
+// DispatchBatch claims up to BatchSize due events, delivers them and records
+// the outcome. It returns how many events were claimed. Handlers run after
+// the claim has committed, so no row lock is held while they do.
+func (d *Dispatcher) DispatchBatch(ctx context.Context) (int, error) {
+	rows, err := d.claim(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	leaseCtx, cancel := context.WithTimeout(ctx, d.cfg.LockTimeout)
+	defer cancel()
+
+	var errs []error
+	for i := range rows {
+		// Events left over when the lease runs out are claimed again.
+		if leaseCtx.Err() != nil {
+			break
+		}
+		row := &rows[i]
+		deliveryErr := d.deliver(leaseCtx, row.toEnvelope())
+		if deliveryErr != nil {
+			logger.Warnf("outbox", "Event %d (%s) attempt %d failed: %v", row.ID, row.Name, row.Attempts, deliveryErr)
+		}
+
+		// The attempt counter fences the update, so a dispatcher whose lease
+		// expired cannot overwrite the outcome of the one that reclaimed the
+		// event.
+		err := d.db.WithContext(ctx).Model(&Event{}).
+			Where("id = ? AND attempts = ? AND processed_at IS NULL AND failed_at IS NULL", row.ID, row.Attempts).
+			Updates(d.outcome(row, deliveryErr, time.Now())).Error
+		if err != nil {
+			errs = append(errs, fmt.Errorf("recording outcome of event %d: %w", row.ID, err))
+		}
+	}
+	return len(rows), errors.Join(errs...)
+}
+
+// claim marks events whose last attempt was abandoned as failed, then leases
+// up to BatchSize due events by moving their available_at past the lease and
+// counting the attempt, and returns them.
+func (d *Dispatcher) claim(ctx context.Context) ([]Event, error) {
+	now := time.Now()
+	db := d.db.WithContext(ctx)
+
+	err := db.Model(&Event{}).
+		Where("processed_at IS NULL AND failed_at IS NULL AND available_at <= ? AND attempts >= ?", now, d.cfg.MaxAttempts).
+		Updates(map[string]interface{}{
+			"failed_at":  now,
+			"last_error": errLockExpired.Error(),
+		}).Error
+	if err != nil {
+		return nil, fmt.Errorf("failing abandoned events: %w", err)
+	}
+
+	var rows []Event
+	err = db.Raw(`
+		UPDATE outbox_events SET attempts = attempts + 1, available_at = ?
+		WHERE id IN (
+			SELECT id FROM outbox_events
+			WHERE processed_at IS NULL AND failed_at IS NULL AND available_at <= ?
+			ORDER BY available_at, id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		now.Add(d.cfg.LockTimeout), now, d.cfg.BatchSize,
+	).Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("claiming events: %w", err)
+	}
+	// RETURNING does not keep the order of the subquery.
+	sort.Slice(rows, func(i, j int) bool { return rows[i].ID < rows[j].ID })
+	return rows, nil
+}
+
+// deliver runs every handler subscribed to e.Name and joins their errors.
+// Events nobody subscribes to are simply marked processed.
+func (d *Dispatcher) deliver(ctx context.Context, e events.Envelope) error {
+	d.mu.RLock()
+	handlers := d.handlers[e.Name]
+	d.mu.RUnlock()
+
+	var errs []error
+	for _, h := range handlers {
+		if err := safeHandle(ctx, h, e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// safeHandle turns a handler panic into an error so one bad subscriber cannot
+// take down the dispatcher.
+func safeHandle(ctx context.Context, h events.Handler, e events.Envelope) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+	return h(ctx, e)
+}
+
+// outcome returns the column updates that record a delivery attempt.
+// Attempts were already counted when the event was claimed.
+func (d *Dispatcher) outcome(row *Event, deliveryErr error, now time.Time) map[string]interface{} {
+	if deliveryErr == nil {
+		return map[string]interface{}{
+			"processed_at": now,
+			"last_error":   nil,
+		}
+	}
+
+	updates := map[string]interface{}{
+		"last_error": deliveryErr.Error(),
+	}
+	if row.Attempts >= d.cfg.MaxAttempts {
+		updates["failed_at"] = now
+	} else {
+		updates["available_at"] = now.Add(d.backoff(row.Attempts))
+	}
+	return updates
+}
+
+// backoff returns RetryBase doubled for every failed attempt after the first,
+// capped at RetryMax.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.cfg.RetryBase
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= d.cfg.RetryMax {
+			return d.cfg.RetryMax
+		}
+	}
+	return min(delay, d.cfg.RetryMax)
+}
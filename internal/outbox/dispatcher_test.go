@@ -0,0 +1,284 @@
+package outbox
+
+import (
+	"context"
+	"english-learning/configs"
+	"english-learning/internal/database/dbtest"
+	"english-learning/pkg/events"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func newTestDispatcher() *Dispatcher {
+	return NewDispatcher(nil, configs.OutboxConfig{
+		BatchSize:   10,
+		MaxAttempts: 3,
+		RetryBase:   time.Second,
+		RetryMax:    10 * time.Second,
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	t.Parallel()
+	d := newTestDispatcher()
+
+	assert.Equal(t, time.Second, d.backoff(1))
+	assert.Equal(t, 2*time.Second, d.backoff(2))
+	assert.Equal(t, 8*time.Second, d.backoff(4))
+	assert.Equal(t, 10*time.Second, d.backoff(5))
+	assert.Equal(t, 10*time.Second, d.backoff(100))
+}
+
+func TestDeliver_FansOutToSubscribers(t *testing.T) {
+	t.Parallel()
+	d := newTestDispatcher()
+
+	var got []string
+	d.Subscribe("user.created", func(ctx context.Context, e events.Envelope) error {
+		got = append(got, "first")
+		return nil
+	})
+	d.Subscribe("user.created", func(ctx context.Context, e events.Envelope) error {
+		got = append(got, "second")
+		return nil
+	})
+	d.Subscribe("user.deleted", func(ctx context.Context, e events.Envelope) error {
+		got = append(got, "other")
+		return nil
+	})
+
+	err := d.deliver(context.Background(), events.Envelope{Name: "user.created"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"first", "second"}, got)
+}
+
+func TestDeliver_JoinsErrorsAndRunsEveryHandler(t *testing.T) {
+	t.Parallel()
+	d := newTestDispatcher()
+
+	errFirst := errors.New("first failed")
+	var secondRan bool
+	d.Subscribe("user.created", func(ctx context.Context, e events.Envelope) error {
+		return errFirst
+	})
+	d.Subscribe("user.created", func(ctx context.Context, e events.Envelope) error {
+		secondRan = true
+		return nil
+	})
+
+	err := d.deliver(context.Background(), events.Envelope{Name: "user.created"})
+
+	assert.ErrorIs(t, err, errFirst)
+	assert.True(t, secondRan)
+}
+
+func TestDeliver_RecoversPanics(t *testing.T) {
+	t.Parallel()
+	d := newTestDispatcher()
+	d.Subscribe("user.created", func(ctx context.Context, e events.Envelope) error {
+		panic("boom")
+	})
+
+	err := d.deliver(context.Background(), events.Envelope{Name: "user.created"})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "boom")
+}
+
+func TestDeliver_NoSubscribers(t *testing.T) {
+	t.Parallel()
+	d := newTestDispatcher()
+
+	assert.NoError(t, d.deliver(context.Background(), events.Envelope{Name: "user.created"}))
+}
+
+func TestOutcome_Success(t *testing.T) {
+	t.Parallel()
+	d := newTestDispatcher()
+	now := time.Now()
+
+	updates := d.outcome(&Event{Attempts: 2}, nil, now)
+
+	assert.Equal(t, map[string]interface{}{
+		"processed_at": now,
+		"last_error":   nil,
+	}, updates)
+}
+
+func TestOutcome_RetryWithBackoff(t *testing.T) {
+	t.Parallel()
+	d := newTestDispatcher()
+	now := time.Now()
+
+	updates := d.outcome(&Event{Attempts: 2}, errors.New("smtp down"), now)
+
+	assert.Equal(t, map[string]interface{}{
+		"last_error":   "smtp down",
+		"available_at": now.Add(2 * time.Second),
+	}, updates)
+}
+
+func TestOutcome_FailsAfterMaxAttempts(t *testing.T) {
+	t.Parallel()
+	d := newTestDispatcher()
+	now := time.Now()
+
+	updates := d.outcome(&Event{Attempts: 3}, errors.New("smtp down"), now)
+
+	assert.Equal(t, map[string]interface{}{
+		"last_error": "smtp down",
+		"failed_at":  now,
+	}, updates)
+}
+
+func TestEnvelopeDecode(t *testing.T) {
+	t.Parallel()
+	row := &Event{ID: 5, Name: "user.created", Payload: []byte(`{"UserID":9}`), Attempts: 3}
+
+	env := row.toEnvelope()
+	var payload struct{ UserID uint }
+
+	require.NoError(t, env.Decode(&payload))
+	assert.Equal(t, uint(9), payload.UserID)
+	assert.Equal(t, 3, env.Attempt)
+	assert.Equal(t, uint64(5), env.ID)
+}
+
+const testEventName = "test.greeted"
+
+type testEvent struct {
+	Name string
+}
+
+func (testEvent) EventName() string { return testEventName }
+
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := dbtest.Open(t)
+	require.NoError(t, db.Exec("DELETE FROM outbox_events WHERE name LIKE 'test.%'").Error)
+	return db
+}
+
+// claimTest claims due events and returns the test event among them, if any.
+func claimTest(t *testing.T, d *Dispatcher) *Event {
+	t.Helper()
+	rows, err := d.claim(context.Background())
+	require.NoError(t, err)
+	for i := range rows {
+		if rows[i].Name == testEventName {
+			return &rows[i]
+		}
+	}
+	return nil
+}
+
+func TestDispatchBatch_ClaimRetryAndGiveUp(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	d := NewDispatcher(db, configs.OutboxConfig{
+		BatchSize:   100,
+		MaxAttempts: 2,
+		RetryBase:   time.Millisecond,
+		RetryMax:    time.Millisecond,
+		LockTimeout: time.Minute,
+	})
+
+	var attempts []int
+	d.Subscribe(testEventName, func(ctx context.Context, e events.Envelope) error {
+		attempts = append(attempts, e.Attempt)
+		// The claim has committed: the row is not locked while handlers run.
+		return db.Exec("SELECT id FROM outbox_events WHERE id = ? FOR UPDATE NOWAIT", e.ID).Error
+	})
+	d.Subscribe(testEventName, func(ctx context.Context, e events.Envelope) error {
+		return errors.New("always fails")
+	})
+
+	require.NoError(t, NewPublisher(db).Publish(ctx, testEvent{Name: "Lan"}))
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		time.Sleep(5 * time.Millisecond) // let the backoff elapse
+		_, err := d.DispatchBatch(ctx)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []int{1, 2}, attempts)
+
+	var row Event
+	require.NoError(t, db.Where("name = ?", testEventName).First(&row).Error)
+	assert.Equal(t, 2, row.Attempts)
+	assert.NotNil(t, row.FailedAt, "given up after max_attempts")
+	assert.Nil(t, row.ProcessedAt)
+	require.NotNil(t, row.LastError)
+	assert.Contains(t, *row.LastError, "always fails")
+
+	time.Sleep(5 * time.Millisecond)
+	_, err := d.DispatchBatch(ctx)
+	require.NoError(t, err)
+	assert.Len(t, attempts, 2, "failed events are not retried")
+}
+
+func TestDispatchBatch_Processes(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	d := NewDispatcher(db, configs.OutboxConfig{BatchSize: 100, MaxAttempts: 3, LockTimeout: time.Minute})
+
+	var got testEvent
+	d.Subscribe(testEventName, func(ctx context.Context, e events.Envelope) error {
+		return e.Decode(&got)
+	})
+	require.NoError(t, NewPublisher(db).Publish(ctx, testEvent{Name: "Lan"}))
+
+	_, err := d.DispatchBatch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "Lan", got.Name)
+
+	var row Event
+	require.NoError(t, db.Where("name = ?", testEventName).First(&row).Error)
+	assert.NotNil(t, row.ProcessedAt)
+	assert.Equal(t, 1, row.Attempts)
+}
+
+func TestClaim_LeasesAndReclaimsAbandonedEvents(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	cfg := configs.OutboxConfig{BatchSize: 100, MaxAttempts: 2, LockTimeout: time.Minute}
+	d := NewDispatcher(db, cfg)
+
+	require.NoError(t, NewPublisher(db).Publish(ctx, testEvent{Name: "Lan"}))
+
+	row := claimTest(t, d)
+	require.NotNil(t, row)
+	assert.Equal(t, 1, row.Attempts)
+	assert.Nil(t, claimTest(t, d), "a leased event is not claimed twice")
+
+	// The dispatcher holding the lease vanished.
+	expire := func() {
+		require.NoError(t, db.Model(&Event{}).Where("id = ?", row.ID).
+			Update("available_at", time.Now().Add(-time.Second)).Error)
+	}
+	expire()
+	again := claimTest(t, d)
+	require.NotNil(t, again)
+	assert.Equal(t, 2, again.Attempts)
+
+	// A late outcome from the first claim is ignored.
+	require.NoError(t, db.Model(&Event{}).
+		Where("id = ? AND attempts = ? AND processed_at IS NULL AND failed_at IS NULL", row.ID, row.Attempts).
+		Updates(d.outcome(row, nil, time.Now())).Error)
+	var stored Event
+	require.NoError(t, db.First(&stored, row.ID).Error)
+	assert.Nil(t, stored.ProcessedAt)
+
+	// The last attempt was abandoned too.
+	expire()
+	assert.Nil(t, claimTest(t, d))
+	require.NoError(t, db.First(&stored, row.ID).Error)
+	assert.NotNil(t, stored.FailedAt)
+	require.NotNil(t, stored.LastError)
+	assert.Equal(t, errLockExpired.Error(), *stored.LastError)
+}
@@ -0,0 +1,43 @@
+package outbox
+
+import (
+	"context"
+	"english-learning/configs"
+	"english-learning/pkg/cron"
+	"english-learning/pkg/jobs"
+	"english-learning/pkg/logger"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PurgeProcessed deletes processed and failed events older than
+// cfg.Retention. It is scheduled daily by RegisterJobs.
+type PurgeProcessed struct{}
+
+func (PurgeProcessed) JobKind() string { return "outbox.purge_processed" }
+
+var purgeProcessedSchedule = cron.MustParse("45 3 * * *")
+
+// RegisterJobs registers the outbox cleanup and its schedule.
+func RegisterJobs(r jobs.Registry, db *gorm.DB, cfg configs.OutboxConfig) {
+	jobs.Handle(r, func(ctx context.Context, _ PurgeProcessed) error {
+		deleted, err := purgeProcessed(ctx, db, time.Now().Add(-cfg.Retention))
+		if err != nil {
+			return fmt.Errorf("deleting processed outbox events: %w", err)
+		}
+		logger.Infof("outbox", "Purged %d processed or failed event(s)", deleted)
+		return nil
+	})
+	r.Schedule("outbox.purge_processed", purgeProcessedSchedule, PurgeProcessed{})
+}
+
+// purgeProcessed deletes the events processed or failed before cutoff.
+// Pending events are kept however old they are.
+func purgeProcessed(ctx context.Context, db *gorm.DB, cutoff time.Time) (int64, error) {
+	result := db.WithContext(ctx).
+		Where("processed_at < ? OR failed_at < ?", cutoff, cutoff).
+		Delete(&Event{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,31 @@
+package outbox
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurgeProcessed_KeepsPendingAndRecentEvents(t *testing.T) {
+	db := openTestDB(t)
+	now := time.Now()
+	old := now.Add(-48 * time.Hour)
+	rows := []Event{
+		{Name: "test.processed_old", Payload: []byte(`{}`), OccurredAt: old, AvailableAt: old, ProcessedAt: &old},
+		{Name: "test.failed_old", Payload: []byte(`{}`), OccurredAt: old, AvailableAt: old, FailedAt: &old},
+		{Name: "test.processed_new", Payload: []byte(`{}`), OccurredAt: now, AvailableAt: now, ProcessedAt: &now},
+		{Name: "test.pending_old", Payload: []byte(`{}`), OccurredAt: old, AvailableAt: old},
+	}
+	require.NoError(t, db.Create(&rows).Error)
+
+	deleted, err := purgeProcessed(context.Background(), db, now.Add(-24*time.Hour))
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, deleted, int64(2))
+
+	var left []string
+	require.NoError(t, db.Model(&Event{}).Where("name LIKE 'test.%'").Order("name").Pluck("name", &left).Error)
+	assert.Equal(t, []string{"test.pending_old", "test.processed_new"}, left)
+}
@@ -0,0 +1,39 @@
+package outbox
+
+import (
+	"encoding/json"
+	"english-learning/pkg/events"
+	"time"
+)
+
+// Event is a row of outbox_events. Attempts counts claims, including the one
+// in progress. AvailableAt is when the event is due; a claim moves it past the
+// lease, so that the event is claimed again if its dispatcher vanishes.
+type Event struct {
+	ID          uint64          `gorm:"primaryKey;index:idx_outbox_events_pending,priority:2,where:processed_at IS NULL AND failed_at IS NULL"`
+	Name        string          `gorm:"type:varchar(100);not null"`
+	Payload     json.RawMessage `gorm:"type:jsonb;not null"`
+	OccurredAt  time.Time       `gorm:"type:timestamp with time zone;not null"`
+	AvailableAt time.Time       `gorm:"type:timestamp with time zone;not null;index:idx_outbox_events_pending,priority:1"`
+	Attempts    int             `gorm:"not null;default:0"`
+	LastError   *string         `gorm:"type:text"`
+	ProcessedAt *time.Time      `gorm:"type:timestamp with time zone"`
+	// FailedAt is set once MaxAttempts deliveries have failed. Such events
+	// are kept for inspection until PurgeProcessed deletes them, and never
+	// retried automatically.
+	FailedAt *time.Time `gorm:"type:timestamp with time zone"`
+}
+
+func (Event) TableName() string {
+	return "outbox_events"
+}
+
+func (m *Event) toEnvelope() events.Envelope {
+	return events.Envelope{
+		ID:         m.ID,
+		Name:       m.Name,
+		Payload:    m.Payload,
+		OccurredAt: m.OccurredAt,
+		Attempt:    m.Attempts,
+	}
+}
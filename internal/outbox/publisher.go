@@ -0,0 +1,50 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"english-learning/internal/database"
+	"english-learning/pkg/events"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Publisher implements events.Publisher by inserting into outbox_events
+// through database.Conn, so events join the caller's unit of work.
+type Publisher struct {
+	db *gorm.DB
+}
+
+// NewPublisher creates a Publisher for db.
+func NewPublisher(db *gorm.DB) *Publisher {
+	return &Publisher{db: db}
+}
+
+// Publish implements events.Publisher.
+func (p *Publisher) Publish(ctx context.Context, evts ...events.Event) error {
+	if len(evts) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	rows := make([]Event, len(evts))
+	for i, e := range evts {
+		payload, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("encoding %s: %w", e.EventName(), err)
+		}
+		rows[i] = Event{
+			Name:        e.EventName(),
+			Payload:     payload,
+			OccurredAt:  now,
+			AvailableAt: now,
+		}
+	}
+
+	if err := database.Conn(ctx, p.db).Create(&rows).Error; err != nil {
+		return fmt.Errorf("writing outbox: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"english-learning/api"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// docsScript starts Swagger UI. It is inline, so docsPolicy allows it by hash.
+const docsScript = `window.ui = SwaggerUIBundle({ url: "/openapi.json", dom_id: "#swagger-ui" });`
+
+// docsPage renders Swagger UI from a CDN against /openapi.json. It is only
+// mounted in dev, so production never depends on the CDN.
+const docsPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>English Learning API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>` + docsScript + `</script>
+</body>
+</html>
+`
+
+// docsPolicy relaxes the API's Content-Security-Policy just enough for the
+// docs page: the CDN's script and styles (Swagger UI also sets inline styles),
+// its inline images, and fetching the spec.
+var docsPolicy = "default-src 'none'; " +
+	"script-src https://unpkg.com 'sha256-" + scriptHash(docsScript) + "'; " +
+	"style-src https://unpkg.com 'unsafe-inline'; " +
+	"img-src 'self' data:; connect-src 'self'; frame-ancestors 'none'; base-uri 'none'"
+
+func scriptHash(script string) string {
+	sum := sha256.Sum256([]byte(script))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// registerDocs serves the OpenAPI spec, and the docs UI at /docs in dev.
+func registerDocs(r *gin.Engine, env string) {
+	r.GET("/openapi.json", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", api.Spec)
+	})
+
+	if env == "dev" {
+		r.GET("/docs", func(c *gin.Context) {
+			c.Header("Content-Security-Policy", docsPolicy)
+			c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(docsPage))
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package server
+
+import (
+	"encoding/json"
+	"english-learning/api"
+	"english-learning/configs"
+	authHandler "english-learning/internal/modules/auth/transport/http"
+	featureHandler "english-learning/internal/modules/feature/transport/http"
+	jobHandler "english-learning/internal/modules/job/transport/http"
+	userHandler "english-learning/internal/modules/user/transport/http"
+	"english-learning/pkg/buildinfo"
+	"english-learning/pkg/health"
+	"english-learning/pkg/lifecycle"
+	"english-learning/pkg/metrics"
+	"english-learning/pkg/response"
+	"english-learning/pkg/validation"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// specSchemas maps every component schema in api/openapi.json to the Go type
+// it documents. Adding a schema to the spec or a DTO to the API means adding
+// it here.
+var specSchemas = map[string]interface{}{
+	"APIResponse":              response.APIResponse{},
+	"FieldError":               validation.FieldError{},
+	"PaginatedData":            response.PaginatedData{},
+	"CursorData":               response.CursorData{},
+	"RegisterRequest":          authHandler.RegisterRequestDTO{},
+	"LoginRequest":             authHandler.LoginRequestDTO{},
+	"RefreshTokenRequest":      authHandler.RefreshTokenRequestDTO{},
+	"TokenPair":                authHandler.TokenPairResponseDTO{},
+	"CreateUserRequest":        userHandler.RegisterRequestDTO{},
+	"UpdateUserRequest":        userHandler.UpdateUserRequestDTO{},
+	"User":                     userHandler.UserResponseDTO{},
+	"HealthReport":             health.Report{},
+	"BuildInfo":                buildinfo.Info{},
+	"Job":                      jobHandler.JobResponseDTO{},
+	"FeatureFlagRule":          featureHandler.RuleDTO{},
+	"CreateFeatureFlagRequest": featureHandler.CreateFlagRequestDTO{},
+	"UpdateFeatureFlagRequest": featureHandler.UpdateFlagRequestDTO{},
+	"FeatureFlag":              featureHandler.FlagResponseDTO{},
+}
+
+// undocumentedRoutes are registered but intentionally left out of the spec.
+var undocumentedRoutes = map[string]bool{
+	"GET /docs": true,
+}
+
+type openAPISpec struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+			Required   []string                   `json:"required"`
+		} `json:"schemas"`
+	} `json:"components"`
+}
+
+func loadSpec(t *testing.T) openAPISpec {
+	t.Helper()
+	var spec openAPISpec
+	require.NoError(t, json.Unmarshal(api.Spec, &spec))
+	return spec
+}
+
+func newTestEngine(t *testing.T) *gin.Engine {
+	t.Helper()
+	cfg := &configs.Config{
+		Server:  configs.ServerConfig{Env: "dev"},
+		Metrics: configs.MetricsConfig{Enabled: true, Path: "/metrics"},
+	}
+	r, err := New(cfg, Deps{
+		Lifecycle: lifecycle.New(),
+		Health:    health.NewRegistry(time.Second),
+		Metrics:   metrics.New(),
+	})
+	require.NoError(t, err)
+	return r
+}
+
+var pathParam = regexp.MustCompile(`[:*]([^/]+)`)
+
+// versionPrefix matches the prefix of routes mounted by apiversion.Mount.
+var versionPrefix = regexp.MustCompile(`^/v\d+`)
+
+// legacyPath matches the spec paths of the unprefixed alias.
+var legacyPath = regexp.MustCompile(`^/(auth|users)(/|$)`)
+
+// TestOpenAPI_RoutesMatchSpec fails when a route is registered without being
+// documented, or documented without being registered. Spec paths are
+// relative to the version servers, except for paths with their own servers,
+// which are registered unversioned.
+func TestOpenAPI_RoutesMatchSpec(t *testing.T) {
+	t.Parallel()
+	spec := loadSpec(t)
+
+	// registered maps each route, without its version prefix, to the
+	// prefixes it is registered under.
+	registered := make(map[string]map[string]bool)
+	for _, route := range newTestEngine(t).Routes() {
+		path := pathParam.ReplaceAllString(route.Path, "{$1}")
+		prefix := versionPrefix.FindString(path)
+		key := route.Method + " " + strings.TrimPrefix(path, prefix)
+		if undocumentedRoutes[key] {
+			continue
+		}
+		if registered[key] == nil {
+			registered[key] = make(map[string]bool)
+		}
+		registered[key][prefix] = true
+	}
+
+	// versions maps each spec path to the prefixes serving it: every
+	// version, except that the unprefixed alias only serves the auth and
+	// user routes.
+	versions := func(path string) map[string]bool {
+		prefixes := make(map[string]bool)
+		for _, v := range apiVersions(configs.APIConfig{}) {
+			if !legacy(v) || legacyPath.MatchString(path) {
+				prefixes[v.Prefix] = true
+			}
+		}
+		return prefixes
+	}
+
+	documented := make(map[string]bool)
+	for path, item := range spec.Paths {
+		_, unversioned := item["servers"]
+		for method := range item {
+			if method == "parameters" || method == "summary" || method == "description" || method == "servers" {
+				continue
+			}
+			key := strings.ToUpper(method) + " " + path
+			documented[key] = true
+
+			if !assert.Containsf(t, registered, key, "api/openapi.json documents %s, which is not registered", key) {
+				continue
+			}
+			if unversioned {
+				assert.Equalf(t, map[string]bool{"": true}, registered[key], "%s is documented as unversioned", key)
+			} else {
+				assert.Equalf(t, versions(path), registered[key], "%s is registered under the wrong API versions", key)
+			}
+		}
+	}
+
+	for key := range registered {
+		assert.Truef(t, documented[key], "route %s is not documented in api/openapi.json", key)
+	}
+}
+
+// TestOpenAPI_SchemasMatchDTOs fails when a DTO field is missing from its
+// schema, a schema property has no field, or a required field is not marked
+// required.
+func TestOpenAPI_SchemasMatchDTOs(t *testing.T) {
+	t.Parallel()
+	spec := loadSpec(t)
+
+	for name := range spec.Components.Schemas {
+		_, mapped := specSchemas[name]
+		assert.Truef(t, mapped, "schema %s has no Go type in specSchemas", name)
+	}
+
+	for name, dto := range specSchemas {
+		schema, ok := spec.Components.Schemas[name]
+		if !assert.Truef(t, ok, "schema %s is missing from api/openapi.json", name) {
+			continue
+		}
+
+		fields := jsonFields(reflect.TypeOf(dto))
+		for field, required := range fields {
+			_, documented := schema.Properties[field]
+			assert.Truef(t, documented, "%s.%s is missing from the spec", name, field)
+			if required {
+				assert.Containsf(t, schema.Required, field, "%s.%s is required but not marked so in the spec", name, field)
+			}
+		}
+		for property := range schema.Properties {
+			_, exists := fields[property]
+			assert.Truef(t, exists, "%s.%s is in the spec but not on %T", name, property, dto)
+		}
+	}
+}
+
+// jsonFields returns the JSON names of t's fields and whether binding
+// requires each of them.
+func jsonFields(t reflect.Type) map[string]bool {
+	fields := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = strings.Contains(","+f.Tag.Get("binding")+",", ",required,")
+	}
+	return fields
+}
+
+func TestOpenAPI_Served(t *testing.T) {
+	t.Parallel()
+	r := newTestEngine(t)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, string(api.Spec), w.Body.String())
+}
+
+func TestDocsUI_OnlyInDev(t *testing.T) {
+	t.Parallel()
+	for env, want := range map[string]int{"dev": http.StatusOK, "prod": http.StatusNotFound} {
+		r := gin.New()
+		registerDocs(r, env)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+
+		assert.Equalf(t, want, w.Code, "env %s", env)
+	}
+}
@@ -2,16 +2,44 @@ package server
 
 import (
 	"english-learning/configs"
+	"english-learning/internal/database"
+	idempotencyStore "english-learning/internal/idempotency"
 	authService "english-learning/internal/modules/auth/service"
 	authHandler "english-learning/internal/modules/auth/transport/http"
 	authRoute "english-learning/internal/modules/auth/transport/http/route"
+	featureCached "english-learning/internal/modules/feature/repository/cached"
+	featurePostgres "english-learning/internal/modules/feature/repository/postgres"
+	featureService "english-learning/internal/modules/feature/service"
+	featureHandler "english-learning/internal/modules/feature/transport/http"
+	featureRoute "english-learning/internal/modules/feature/transport/http/route"
+	jobPostgres "english-learning/internal/modules/job/repository/postgres"
+	jobService "english-learning/internal/modules/job/service"
+	jobHandler "english-learning/internal/modules/job/transport/http"
+	jobRoute "english-learning/internal/modules/job/transport/http/route"
 	sessionPostgres "english-learning/internal/modules/session/repository/postgres"
+	sessionService "english-learning/internal/modules/session/service"
+	userDomain "english-learning/internal/modules/user/domain"
+	userCached "english-learning/internal/modules/user/repository/cached"
 	userPostgres "english-learning/internal/modules/user/repository/postgres"
 	userService "english-learning/internal/modules/user/service"
 	userHandler "english-learning/internal/modules/user/transport/http"
 	userRoute "english-learning/internal/modules/user/transport/http/route"
+	"english-learning/internal/outbox"
+	"english-learning/pkg/apiversion"
+	"english-learning/pkg/buildinfo"
+	"english-learning/pkg/cache"
+	"english-learning/pkg/encryption"
+	"english-learning/pkg/events"
+	"english-learning/pkg/health"
+	"english-learning/pkg/idempotency"
+	"english-learning/pkg/jobs"
+	"english-learning/pkg/lifecycle"
+	"english-learning/pkg/metrics"
 	"english-learning/pkg/middleware"
+	"english-learning/pkg/response"
+	"english-learning/pkg/tracing"
 	"english-learning/pkg/validation"
+	"fmt"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
@@ -19,36 +47,126 @@ import (
 	"gorm.io/gorm"
 )
 
+// Deps are the application-level dependencies shared by every module.
+type Deps struct {
+	DB *gorm.DB
+	// Lifecycle is where modules that run background work register their
+	// start/stop hooks.
+	Lifecycle *lifecycle.Lifecycle
+	// Health is where modules with external dependencies register readiness checks.
+	Health *health.Registry
+	// Metrics is nil when metrics are disabled; its methods are nil-safe.
+	Metrics *metrics.Metrics
+	// Events is where modules subscribe to domain events published by
+	// others. It may be nil when nothing needs to subscribe (e.g. in tests).
+	Events events.Subscriber
+	// Jobs is where modules register background job handlers and recurring
+	// schedules. It may be nil when no worker runs (e.g. in tests).
+	Jobs jobs.Registry
+	// Encryption seals personal data stored in the database.
+	Encryption *encryption.Service
+}
+
 // New creates and configures the Gin router with all routes and middleware.
-func New(cfg *configs.Config, db *gorm.DB) *gin.Engine {
+func New(cfg *configs.Config, deps Deps) (*gin.Engine, error) {
 	r := gin.New()
 
+	// Only the configured load balancers may set the client IP through
+	// X-Forwarded-For; configs.Validate reports invalid entries up front.
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("setting trusted proxies: %w", err)
+	}
+
 	// Middleware
+	r.Use(middleware.RequestIDMiddleware())
+	r.Use(middleware.LocaleMiddleware())
+	r.Use(tracing.Middleware())
+	r.Use(deps.Metrics.Middleware())
 	r.Use(middleware.LoggerMiddleware())
 	r.Use(gin.Recovery())
+	r.Use(middleware.SecurityHeadersMiddleware(cfg.Security))
+	r.Use(middleware.CORSMiddleware(cfg.CORS))
+	r.Use(middleware.TimeoutMiddleware(cfg.Server.RequestTimeout))
 
 	// Register Custom Validators
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		validation.RegisterTagName(v)
 		_ = v.RegisterValidation("date_format", validation.ValidateDateFormat);
 		_ = v.RegisterValidation("phone", validation.ValidatePhone)
+		_ = v.RegisterValidation("version", validation.ValidateVersion)
 	}
 
 	// Init Repositories
-	userRepo := userPostgres.NewUserRepository(db)
-	sessionRepo := sessionPostgres.NewSessionRepository(db)
+	userStore := userPostgres.NewUserRepository(deps.DB, deps.Encryption)
+	var userRepo userDomain.UserRepository = userStore
+	if cfg.Cache.Users.Enabled {
+		cachedUsers := userCached.NewUserRepository(userRepo,
+			cache.NewLRU[uint, userDomain.User](cfg.Cache.Users.Size, cfg.Cache.Users.TTL), deps.Metrics)
+		listener := database.NewListener(cfg.Database.DSN, userCached.Channel, cachedUsers.HandleNotification, cachedUsers.Flush)
+		deps.Lifecycle.Append(lifecycle.Hook{Name: "user cache invalidation", OnStart: listener.Start, OnStop: listener.Stop})
+		userRepo = cachedUsers
+	}
+	flagRepo := featurePostgres.NewFlagRepository(deps.DB)
+	if cfg.Cache.Features.Enabled {
+		cachedFlags := featureCached.NewFlagRepository(flagRepo, cfg.Cache.Features.TTL, deps.Metrics)
+		listener := database.NewListener(cfg.Database.DSN, featureCached.Channel, cachedFlags.HandleNotification, cachedFlags.Flush)
+		deps.Lifecycle.Append(lifecycle.Hook{Name: "feature flag cache invalidation", OnStart: listener.Start, OnStop: listener.Stop})
+		flagRepo = cachedFlags
+	}
+	sessionRepo := sessionPostgres.NewSessionRepository(deps.DB)
+	jobRepo := jobPostgres.NewJobRepository(deps.DB)
+	unitOfWork := database.NewUnitOfWork(deps.DB)
+	publisher := outbox.NewPublisher(deps.DB)
+	idempotencyKeys := idempotencyStore.NewStore(deps.DB)
 
 	// Init Services
-	userSvc := userService.NewService(userRepo)
-	authSvc := authService.NewService(userRepo, sessionRepo, cfg.JWT.Secret)
+	userSvc := userService.NewService(userRepo, unitOfWork, publisher)
+	authSvc := authService.NewService(userRepo, sessionRepo, unitOfWork, publisher, authService.TokenConfig{
+		Secret:     cfg.JWT.Secret,
+		AccessTTL:  cfg.JWT.AccessTTL,
+		RefreshTTL: cfg.JWT.RefreshTTL,
+	}, deps.Metrics)
+	jobSvc := jobService.NewService(jobRepo)
+	// featureSvc is the domain.Evaluator for services that gate behaviour
+	// on a feature flag.
+	featureSvc := featureService.NewService(flagRepo)
+
+	// Register Background Jobs
+	if deps.Jobs != nil {
+		sessionService.RegisterJobs(deps.Jobs, sessionRepo)
+		idempotencyStore.RegisterJobs(deps.Jobs, idempotencyKeys)
+		userService.RegisterJobs(deps.Jobs, userStore)
+	}
 
 	// Init Handlers
 	userH := userHandler.NewUserHandler(userSvc)
 	authH := authHandler.NewAuthHandler(authSvc)
+	jobH := jobHandler.NewJobHandler(jobSvc)
+	featureH := featureHandler.NewFlagHandler(featureSvc)
 
 	// Register Routes
-	authRoute.Register(r, authH)
-	userRoute.Register(r, cfg, userH)
+	r.GET("/healthz", deps.Health.LivenessHandler())
+	r.GET("/readyz", deps.Health.ReadinessHandler())
+	r.GET("/version", func(c *gin.Context) {
+		response.Success(c, buildinfo.Get(), response.MsgSuccess)
+	})
+	if deps.Metrics != nil {
+		r.GET(cfg.Metrics.Path, deps.Metrics.Handler(cfg.Metrics.Token))
+	}
+
+	registerDocs(r, cfg.Server.Env)
+
+	idempotent := idempotency.Middleware(idempotencyKeys, cfg.Idempotency)
+	for _, v := range apiVersions(cfg.API) {
+		api := apiversion.Mount(r, v)
+		authRoute.Register(api, authH, idempotent)
+		userRoute.Register(api, cfg, userH, idempotent)
+		if legacy(v) {
+			continue
+		}
+		jobRoute.Register(api, cfg, jobH)
+		featureRoute.Register(api, cfg, featureH)
+	}
 
-	return r
+	return r, nil
 }
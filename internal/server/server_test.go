@@ -0,0 +1,106 @@
+package server
+
+import (
+	"english-learning/configs"
+	"english-learning/pkg/health"
+	"english-learning/pkg/lifecycle"
+	"english-learning/pkg/metrics"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_TrustsOnlyConfiguredProxies(t *testing.T) {
+	t.Parallel()
+	for proxies, want := range map[string]string{
+		"":           "10.1.2.3",
+		"10.0.0.0/8": "203.0.113.7",
+	} {
+		cfg := &configs.Config{Server: configs.ServerConfig{Env: "prod"}}
+		if proxies != "" {
+			cfg.Server.TrustedProxies = strings.Split(proxies, ",")
+		}
+		r, err := New(cfg, Deps{Lifecycle: lifecycle.New(), Health: health.NewRegistry(time.Second), Metrics: metrics.New()})
+		require.NoError(t, err)
+		r.GET("/ip", func(c *gin.Context) { c.String(http.StatusOK, c.ClientIP()) })
+
+		req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+		req.RemoteAddr = "10.1.2.3:4567"
+		req.Header.Set("X-Forwarded-For", "203.0.113.7")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equalf(t, want, w.Body.String(), "trusted proxies %q", proxies)
+	}
+}
+
+func TestNew_RejectsInvalidTrustedProxies(t *testing.T) {
+	t.Parallel()
+	cfg := &configs.Config{Server: configs.ServerConfig{Env: "prod", TrustedProxies: []string{"load-balancer"}}}
+
+	_, err := New(cfg, Deps{Lifecycle: lifecycle.New(), Health: health.NewRegistry(time.Second), Metrics: metrics.New()})
+	assert.ErrorContains(t, err, "setting trusted proxies")
+}
+
+func TestNew_SecurityHeadersAndCORS(t *testing.T) {
+	t.Parallel()
+	cfg := &configs.Config{
+		Server:   configs.ServerConfig{Env: "dev"},
+		CORS:     configs.CORSConfig{AllowedOrigins: []string{"https://app.example.com"}},
+		Security: configs.SecurityConfig{HSTSMaxAge: time.Hour},
+	}
+	r, err := New(cfg, Deps{Lifecycle: lifecycle.New(), Health: health.NewRegistry(time.Second), Metrics: metrics.New()})
+	require.NoError(t, err)
+
+	// Preflights are answered before routing, even for routes without an
+	// OPTIONS handler.
+	req := httptest.NewRequest(http.MethodOptions, "/users/1", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPatch)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "max-age=3600", w.Header().Get("Strict-Transport-Security"))
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Security-Policy"), "script-src https://unpkg.com 'sha256-")
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+}
+
+func TestNew_LegacyAlias(t *testing.T) {
+	t.Parallel()
+	cfg := &configs.Config{
+		Server: configs.ServerConfig{Env: "dev"},
+		API:    configs.APIConfig{LegacySince: "2026-10-19", LegacySunset: "2027-04-19"},
+	}
+	r, err := New(cfg, Deps{Lifecycle: lifecycle.New(), Health: health.NewRegistry(time.Second), Metrics: metrics.New()})
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "@1792368000", w.Header().Get("Deprecation"))
+	assert.Equal(t, "Mon, 19 Apr 2027 00:00:00 GMT", w.Header().Get("Sunset"))
+	assert.Equal(t, `</v1/users>; rel="successor-version"`, w.Header().Get("Link"))
+
+	// Routes added after /v1 are not served unprefixed.
+	for _, path := range []string{"/admin/jobs", "/features"} {
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equalf(t, http.StatusNotFound, w.Code, path)
+
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1"+path, nil))
+		assert.Equalf(t, http.StatusUnauthorized, w.Code, "/v1"+path)
+		assert.Emptyf(t, w.Header().Get("Deprecation"), "/v1"+path)
+	}
+}
@@ -0,0 +1,31 @@
+package server
+
+import (
+	"english-learning/configs"
+	"english-learning/pkg/apiversion"
+)
+
+// apiVersions returns the mounts of the module routes. A new version is added
+// here as soon as the first route needs a breaking change, which then picks
+// its handler with apiversion.Switch.
+func apiVersions(cfg configs.APIConfig) []apiversion.Version {
+	since, sunset := cfg.LegacyDates()
+	return []apiversion.Version{
+		{Major: 1, Prefix: "/v1"},
+		{Major: 2, Prefix: "/v2"},
+		// The unprefixed routes of apps shipped before /v1 was added. Major 0
+		// keeps the handlers they were built against; routes added since are
+		// left out (see legacy).
+		{Major: 0, Prefix: "", Deprecation: &apiversion.Deprecation{
+			Since:     since,
+			Sunset:    sunset,
+			Successor: "/v1",
+		}},
+	}
+}
+
+// legacy reports whether v is the unprefixed alias, which serves only the
+// auth and user routes.
+func legacy(v apiversion.Version) bool {
+	return v.Major < 1
+}
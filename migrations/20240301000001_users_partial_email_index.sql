@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Only active users need a unique email, so a soft-deleted account no longer
+-- blocks someone from registering again with the same address.
+DROP INDEX IF EXISTS "idx_users_email";
+CREATE UNIQUE INDEX "idx_users_email" ON "users" ("email") WHERE "deleted_at" IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Fails if a deleted and an active user share an email; purge one of them first.
+DROP INDEX IF EXISTS "idx_users_email";
+CREATE UNIQUE INDEX "idx_users_email" ON "users" ("email");
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+UPDATE "sessions" SET "is_revoked" = false WHERE "is_revoked" IS NULL;
+ALTER TABLE "sessions" ALTER COLUMN "is_revoked" SET NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "sessions" ALTER COLUMN "is_revoked" DROP NOT NULL;
+-- +goose StatementEnd
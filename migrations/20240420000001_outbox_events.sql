@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE "outbox_events" (
+  "id" bigserial PRIMARY KEY,
+  "name" varchar(100) NOT NULL,
+  "payload" jsonb NOT NULL,
+  "occurred_at" timestamptz NOT NULL,
+  "available_at" timestamptz NOT NULL,
+  "attempts" integer NOT NULL DEFAULT 0,
+  "last_error" text,
+  "processed_at" timestamptz,
+  "failed_at" timestamptz
+);
+
+-- Pending events, in the order the dispatcher claims them.
+CREATE INDEX "idx_outbox_events_pending" ON "outbox_events" ("available_at", "id")
+  WHERE "processed_at" IS NULL AND "failed_at" IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE "outbox_events";
+-- +goose StatementEnd
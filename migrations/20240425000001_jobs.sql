@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE "jobs" (
+  "id" bigserial PRIMARY KEY,
+  "kind" varchar(100) NOT NULL,
+  "payload" jsonb NOT NULL,
+  "status" varchar(20) NOT NULL DEFAULT 'pending',
+  "attempts" integer NOT NULL DEFAULT 0,
+  "max_attempts" integer NOT NULL,
+  "run_at" timestamptz NOT NULL,
+  "locked_until" timestamptz,
+  "last_error" text,
+  "created_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+  "finished_at" timestamptz
+);
+
+-- Due and stale jobs, in the order workers claim them; also serves the admin
+-- listing by status.
+CREATE INDEX "idx_jobs_status_run_at" ON "jobs" ("status", "run_at");
+
+CREATE TABLE "job_schedules" (
+  "name" varchar(100) PRIMARY KEY,
+  "spec" varchar(100) NOT NULL,
+  "next_run_at" timestamptz NOT NULL,
+  "last_run_at" timestamptz
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE "job_schedules";
+DROP TABLE "jobs";
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Announces every updated or deleted user on the user_changed channel so that
+-- each instance can drop it from its user cache. NOTIFY is delivered on
+-- commit, and covers writes made outside the application too.
+CREATE FUNCTION "notify_user_changed"() RETURNS trigger AS $$
+BEGIN
+  PERFORM pg_notify('user_changed', OLD.id::text);
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "users_notify_changed"
+  AFTER UPDATE OR DELETE ON "users"
+  FOR EACH ROW EXECUTE FUNCTION "notify_user_changed"();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER "users_notify_changed" ON "users";
+DROP FUNCTION "notify_user_changed"();
+-- +goose StatementEnd
@@ -0,0 +1,8 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+-- Serves the keyset pagination of GET /users, sorted by creation time by
+-- default. Built concurrently so the large users table stays writable.
+CREATE INDEX CONCURRENTLY IF NOT EXISTS "idx_users_created_at_id" ON "users" ("created_at", "id") WHERE "deleted_at" IS NULL;
+
+-- +goose Down
+DROP INDEX CONCURRENTLY IF EXISTS "idx_users_created_at_id";
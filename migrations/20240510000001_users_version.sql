@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Incremented by every update; clients send it back in If-Match so that
+-- concurrent edits of the same user fail instead of overwriting each other.
+ALTER TABLE "users" ADD COLUMN "version" bigint NOT NULL DEFAULT 1;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "users" DROP COLUMN "version";
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Responses to requests sent with an Idempotency-Key header, replayed to
+-- retries until expires_at. response_status is NULL while the first request
+-- is still running under the locked_until lease.
+CREATE TABLE "idempotency_keys" (
+  "id" bigserial PRIMARY KEY,
+  "scope" varchar(64) NOT NULL,
+  "idempotency_key" varchar(255) NOT NULL,
+  "route" varchar(255) NOT NULL,
+  "fingerprint" varchar(64) NOT NULL,
+  "response_status" integer,
+  "response_headers" jsonb,
+  "response_body" bytea,
+  "locked_until" timestamptz NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL
+);
+
+CREATE UNIQUE INDEX "idx_idempotency_keys_scope_key_route" ON "idempotency_keys" ("scope", "idempotency_key", "route");
+CREATE INDEX "idx_idempotency_keys_expires_at" ON "idempotency_keys" ("expires_at");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE "idempotency_keys";
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Feature flags and their targeting rules, a JSON array of objects with
+-- optional userIds, roles, minAppVersion and percentage.
+CREATE TABLE "feature_flags" (
+  "key" varchar(100) PRIMARY KEY,
+  "description" text NOT NULL DEFAULT '',
+  "enabled" boolean NOT NULL DEFAULT false,
+  "rules" jsonb NOT NULL,
+  "created_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamptz DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Announces every changed flag on the feature_flag_changed channel so that
+-- each instance can drop its cached flags, as for users.
+CREATE FUNCTION "notify_feature_flag_changed"() RETURNS trigger AS $$
+BEGIN
+  PERFORM pg_notify('feature_flag_changed', COALESCE(NEW.key, OLD.key));
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "feature_flags_notify_changed"
+  AFTER INSERT OR UPDATE OR DELETE ON "feature_flags"
+  FOR EACH ROW EXECUTE FUNCTION "notify_feature_flag_changed"();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE "feature_flags";
+DROP FUNCTION "notify_feature_flag_changed"();
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Phone numbers and birthdates are stored encrypted by the application
+-- (pkg/encryption), so both become text. Existing values stay readable as
+-- plaintext until the user.reencrypt_personal_data job seals them and fills
+-- phone_number_hash, the blind index that phone number lookups use.
+ALTER TABLE "users"
+  ALTER COLUMN "phone_number" TYPE text,
+  ALTER COLUMN "birthdate" TYPE text USING to_char("birthdate", 'YYYY-MM-DD'),
+  ADD COLUMN "phone_number_hash" varchar(64);
+CREATE INDEX "idx_users_phone_number_hash" ON "users" ("phone_number_hash");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Only plaintext values can be restored: run the down migration on a database
+-- holding encrypted values and it fails rather than losing them.
+DROP INDEX "idx_users_phone_number_hash";
+ALTER TABLE "users"
+  DROP COLUMN "phone_number_hash",
+  ALTER COLUMN "phone_number" TYPE varchar(20),
+  ALTER COLUMN "birthdate" TYPE date USING "birthdate"::date;
+-- +goose StatementEnd
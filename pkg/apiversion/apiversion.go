@@ -0,0 +1,138 @@
+// Package apiversion mounts the HTTP API under version prefixes (/v1, /v2, …)
+// and signals deprecation.
+//
+// Versions serve the same module routes, unless the server leaves some out of
+// a version; a version otherwise only differs where a route selects its
+// handler with Switch. Shipped clients keep calling the
+// version they were built against, so a breaking change to a DTO goes into a
+// new version's handler while the old one keeps its shape.
+//
+// Deprecated versions and routes answer with Deprecation (RFC 9745) and
+// Sunset (RFC 8594) headers and a Link to their successor, and every call is
+// logged with the caller's app version, so we know who still uses them.
+package apiversion
+
+import (
+	"english-learning/pkg/logger"
+	"english-learning/pkg/reqctx"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// AppVersionHeader is sent by our apps with their release, e.g. "2.3.1".
+const AppVersionHeader = "X-App-Version"
+
+const contextKey = "api_version"
+
+// Version is one mount of the API.
+type Version struct {
+	// Major selects handlers in Switch.
+	Major int
+	// Prefix is where the version is mounted, e.g. "/v1". The legacy alias is
+	// mounted at "".
+	Prefix string
+	// Deprecation, when set, marks every route of the version deprecated.
+	Deprecation *Deprecation
+}
+
+// Deprecation describes a deprecated version or route.
+type Deprecation struct {
+	// Since is when it was deprecated.
+	Since time.Time
+	// Sunset, when set, is when it stops being served.
+	Sunset time.Time
+	// Successor is the prefix of the version replacing a deprecated version,
+	// e.g. "/v1", or the path of the route replacing a deprecated route.
+	Successor string
+}
+
+// Mount registers a route group for v on r. Its handlers can read v with Of.
+func Mount(r *gin.Engine, v Version) *gin.RouterGroup {
+	group := r.Group(v.Prefix, func(c *gin.Context) {
+		c.Set(contextKey, v)
+	})
+	if d := v.Deprecation; d != nil {
+		group.Use(deprecate(*d, func(c *gin.Context) string {
+			if d.Successor == "" {
+				return ""
+			}
+			return d.Successor + strings.TrimPrefix(c.Request.URL.Path, v.Prefix)
+		}))
+	}
+	return group
+}
+
+// Of returns the version the request was routed through. Routes outside any
+// version report the zero Version.
+func Of(c *gin.Context) Version {
+	v, _ := c.Get(contextKey)
+	version, _ := v.(Version)
+	return version
+}
+
+// Switch returns a handler running handlers[m] for the request's version,
+// where m is the highest major not above it. A version without its own
+// handler thus inherits the previous one. Requests below every major get a
+// 404.
+func Switch(handlers map[int]gin.HandlerFunc) gin.HandlerFunc {
+	majors := make([]int, 0, len(handlers))
+	for m := range handlers {
+		majors = append(majors, m)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(majors)))
+
+	return func(c *gin.Context) {
+		major := Of(c).Major
+		for _, m := range majors {
+			if m <= major {
+				handlers[m](c)
+				return
+			}
+		}
+		c.AbortWithStatus(http.StatusNotFound)
+	}
+}
+
+// Deprecate marks a single route deprecated. Put it first in the route's
+// handlers.
+func Deprecate(d Deprecation) gin.HandlerFunc {
+	return deprecate(d, func(*gin.Context) string { return d.Successor })
+}
+
+func deprecate(d Deprecation, successor func(*gin.Context) string) gin.HandlerFunc {
+	deprecation := "@" + strconv.FormatInt(d.Since.Unix(), 10)
+	sunset := ""
+	if !d.Sunset.IsZero() {
+		sunset = d.Sunset.UTC().Format(http.TimeFormat)
+	}
+
+	return func(c *gin.Context) {
+		c.Header("Deprecation", deprecation)
+		if sunset != "" {
+			c.Header("Sunset", sunset)
+		}
+		if link := successor(c); link != "" {
+			c.Header("Link", "<"+link+`>; rel="successor-version"`)
+		}
+
+		c.Next()
+
+		// Logged after the handler so that the user is known.
+		fields := []zap.Field{
+			zap.String("route", c.Request.Method+" "+c.FullPath()),
+			zap.Int("status", c.Writer.Status()),
+			zap.String("app_version", c.GetHeader(AppVersionHeader)),
+			zap.String("user-agent", c.Request.UserAgent()),
+		}
+		if id, ok := reqctx.UserID(c.Request.Context()); ok {
+			fields = append(fields, zap.Uint("user_id", id))
+		}
+		logger.FromContext(c.Request.Context()).Named("deprecation").Warn("Deprecated endpoint called", fields...)
+	}
+}
@@ -0,0 +1,94 @@
+package apiversion
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+var (
+	since  = time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC)
+	sunset = time.Date(2025, 5, 20, 0, 0, 0, 0, time.UTC)
+
+	versions = []Version{
+		{Major: 1, Prefix: "/v1"},
+		{Major: 2, Prefix: "/v2"},
+		{Major: 3, Prefix: "/v3"},
+		{Major: 1, Prefix: "", Deprecation: &Deprecation{Since: since, Sunset: sunset, Successor: "/v1"}},
+	}
+)
+
+func router() *gin.Engine {
+	r := gin.New()
+	for _, v := range versions {
+		api := Mount(r, v)
+		api.GET("/users/:id", Switch(map[int]gin.HandlerFunc{
+			1: func(c *gin.Context) { c.String(http.StatusOK, "v1 user") },
+			2: func(c *gin.Context) { c.String(http.StatusOK, "v2 user") },
+		}))
+		api.GET("/version", func(c *gin.Context) { c.String(http.StatusOK, "%d", Of(c).Major) })
+		api.GET("/old", Deprecate(Deprecation{Since: since, Successor: "/v2/new"}), func(c *gin.Context) {
+			c.Status(http.StatusNoContent)
+		})
+	}
+	return r
+}
+
+func get(r http.Handler, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+func TestMount_VersionOfRequest(t *testing.T) {
+	t.Parallel()
+	r := router()
+	for path, want := range map[string]string{"/v1/version": "1", "/v2/version": "2", "/version": "1"} {
+		assert.Equalf(t, want, get(r, path).Body.String(), "GET %s", path)
+	}
+}
+
+func TestSwitch_InheritsPreviousHandler(t *testing.T) {
+	t.Parallel()
+	r := router()
+	for path, want := range map[string]string{
+		"/v1/users/7": "v1 user",
+		"/v2/users/7": "v2 user",
+		"/v3/users/7": "v2 user",
+		"/users/7":    "v1 user",
+	} {
+		assert.Equalf(t, want, get(r, path).Body.String(), "GET %s", path)
+	}
+}
+
+func TestMount_DeprecatedVersion(t *testing.T) {
+	t.Parallel()
+	r := router()
+
+	w := get(r, "/users/7")
+	assert.Equal(t, "@1716163200", w.Header().Get("Deprecation"))
+	assert.Equal(t, "Tue, 20 May 2025 00:00:00 GMT", w.Header().Get("Sunset"))
+	assert.Equal(t, `</v1/users/7>; rel="successor-version"`, w.Header().Get("Link"))
+
+	w = get(r, "/v1/users/7")
+	assert.Empty(t, w.Header().Get("Deprecation"))
+	assert.Empty(t, w.Header().Get("Link"))
+}
+
+func TestDeprecate_Route(t *testing.T) {
+	t.Parallel()
+	w := get(router(), "/v2/old")
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "@1716163200", w.Header().Get("Deprecation"))
+	assert.Empty(t, w.Header().Get("Sunset"))
+	assert.Equal(t, `</v2/new>; rel="successor-version"`, w.Header().Get("Link"))
+}
@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestLRU(size int, ttl time.Duration) (*LRU[string, int], *time.Time) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	c := NewLRU[string, int](size, ttl)
+	c.now = func() time.Time { return now }
+	return c, &now
+}
+
+func TestLRU_GetSet(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	c, _ := newTestLRU(2, time.Minute)
+
+	_, ok := c.Get(ctx, "a")
+	assert.False(t, ok)
+
+	c.Set(ctx, "a", 1)
+	c.Set(ctx, "a", 2)
+	v, ok := c.Get(ctx, "a")
+	assert.True(t, ok)
+	assert.Equal(t, 2, v)
+	assert.Equal(t, 1, c.Len())
+}
+
+func TestLRU_EvictsLeastRecentlyUsed(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	c, _ := newTestLRU(2, time.Minute)
+
+	c.Set(ctx, "a", 1)
+	c.Set(ctx, "b", 2)
+	c.Get(ctx, "a") // b is now the least recently used
+	c.Set(ctx, "c", 3)
+
+	_, ok := c.Get(ctx, "b")
+	assert.False(t, ok)
+	_, ok = c.Get(ctx, "a")
+	assert.True(t, ok)
+	_, ok = c.Get(ctx, "c")
+	assert.True(t, ok)
+}
+
+func TestLRU_Expiry(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	c, now := newTestLRU(2, time.Minute)
+
+	c.Set(ctx, "a", 1)
+	*now = now.Add(59 * time.Second)
+	_, ok := c.Get(ctx, "a")
+	assert.True(t, ok)
+
+	*now = now.Add(time.Second)
+	_, ok = c.Get(ctx, "a")
+	assert.False(t, ok)
+	assert.Zero(t, c.Len(), "expired entries are dropped on access")
+}
+
+func TestLRU_DeleteAndClear(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	c, _ := newTestLRU(3, time.Minute)
+
+	c.Set(ctx, "a", 1)
+	c.Set(ctx, "b", 2)
+	c.Delete(ctx, "a")
+	c.Delete(ctx, "missing")
+	_, ok := c.Get(ctx, "a")
+	assert.False(t, ok)
+
+	c.Clear(ctx)
+	assert.Zero(t, c.Len())
+	_, ok = c.Get(ctx, "b")
+	assert.False(t, ok)
+}
@@ -0,0 +1,199 @@
+// Package cron parses standard five-field cron expressions ("minute hour
+// day-of-month month day-of-week") and computes their next activation.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron expression. Times are evaluated in UTC.
+type Schedule struct {
+	spec   string
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+	// domStar and dowStar record an unrestricted field: when both day fields
+	// are restricted, a day matches if either does (as in Vixie cron).
+	domStar bool
+	dowStar bool
+}
+
+type bounds struct {
+	name     string
+	min, max uint
+}
+
+var (
+	minutes = bounds{"minute", 0, 59}
+	hours   = bounds{"hour", 0, 23}
+	doms    = bounds{"day of month", 1, 31}
+	months  = bounds{"month", 1, 12}
+	// 7 is accepted as an alias for Sunday and folded into 0 by Parse.
+	dows = bounds{"day of week", 0, 7}
+)
+
+// descriptors are the supported @-shorthands.
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses spec. Each field accepts "*", a value, a range "a-b", a step
+// "*/n" or "a-b/n", and comma-separated lists of those. Day of week is 0-6
+// with Sunday as 0 (7 is accepted as Sunday too). The @yearly, @monthly,
+// @weekly, @daily and @hourly shorthands are also accepted.
+func Parse(spec string) (Schedule, error) {
+	expr := strings.TrimSpace(spec)
+	if d, ok := descriptors[expr]; ok {
+		expr = d
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return Schedule{}, fmt.Errorf("cron %q: expected 5 fields, got %d", spec, len(fields))
+	}
+
+	s := Schedule{spec: spec}
+	var err error
+	if s.minute, err = parseField(fields[0], minutes); err != nil {
+		return Schedule{}, fmt.Errorf("cron %q: %w", spec, err)
+	}
+	if s.hour, err = parseField(fields[1], hours); err != nil {
+		return Schedule{}, fmt.Errorf("cron %q: %w", spec, err)
+	}
+	if s.dom, err = parseField(fields[2], doms); err != nil {
+		return Schedule{}, fmt.Errorf("cron %q: %w", spec, err)
+	}
+	if s.month, err = parseField(fields[3], months); err != nil {
+		return Schedule{}, fmt.Errorf("cron %q: %w", spec, err)
+	}
+	if s.dow, err = parseField(fields[4], dows); err != nil {
+		return Schedule{}, fmt.Errorf("cron %q: %w", spec, err)
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+	s.domStar = strings.HasPrefix(fields[2], "*")
+	s.dowStar = strings.HasPrefix(fields[4], "*")
+
+	return s, nil
+}
+
+// MustParse is like Parse but panics on an invalid spec. It is meant for
+// schedules declared as package-level variables.
+func MustParse(spec string) Schedule {
+	s, err := Parse(spec)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// String returns the spec the schedule was parsed from.
+func (s Schedule) String() string {
+	return s.spec
+}
+
+// Next returns the first activation strictly after t, truncated to the
+// minute, in UTC. It returns the zero time if the schedule never fires
+// (e.g. "0 0 30 2 *").
+func (s Schedule) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+
+	// Every activation repeats within a leap-year cycle.
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (s Schedule) dayMatches(t time.Time) bool {
+	domOK := s.dom&(1<<uint(t.Day())) != 0
+	dowOK := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domOK && dowOK
+	}
+	return domOK || dowOK
+}
+
+// parseField returns a bit set of the values field selects within b.
+func parseField(field string, b bounds) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		bits, err := parseRange(part, b)
+		if err != nil {
+			return 0, err
+		}
+		set |= bits
+	}
+	return set, nil
+}
+
+func parseRange(part string, b bounds) (uint64, error) {
+	rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+	lo, hi := b.min, b.max
+	if rangePart != "*" {
+		from, to, isRange := strings.Cut(rangePart, "-")
+		var err error
+		if lo, err = parseValue(from, b); err != nil {
+			return 0, err
+		}
+		hi = lo
+		if isRange {
+			if hi, err = parseValue(to, b); err != nil {
+				return 0, err
+			}
+		} else if hasStep {
+			hi = b.max // "a/n" means from a to the end
+		}
+		if lo > hi {
+			return 0, fmt.Errorf("%s range %q is reversed", b.name, rangePart)
+		}
+	}
+
+	step := uint(1)
+	if hasStep {
+		n, err := strconv.ParseUint(stepPart, 10, 8)
+		if err != nil || n == 0 {
+			return 0, fmt.Errorf("invalid %s step %q", b.name, stepPart)
+		}
+		step = uint(n)
+	}
+
+	var bits uint64
+	for v := lo; v <= hi; v += step {
+		bits |= 1 << v
+	}
+	return bits, nil
+}
+
+func parseValue(s string, b bounds) (uint, error) {
+	n, err := strconv.ParseUint(s, 10, 8)
+	if err != nil || uint(n) < b.min || uint(n) > b.max {
+		return 0, fmt.Errorf("%s %q must be between %d and %d", b.name, s, b.min, b.max)
+	}
+	return uint(n), nil
+}
@@ -0,0 +1,78 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestNext(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		spec string
+		from string
+		want string
+	}{
+		{"* * * * *", "2024-05-10 12:30", "2024-05-10 12:31"},
+		{"*/15 * * * *", "2024-05-10 12:30", "2024-05-10 12:45"},
+		{"0 3 * * *", "2024-05-10 12:30", "2024-05-11 03:00"},
+		{"0 3 * * *", "2024-05-10 02:59", "2024-05-10 03:00"},
+		{"30 9 * * 1-5", "2024-05-10 12:00", "2024-05-13 09:30"}, // Friday -> Monday
+		{"0 0 * * 7", "2024-05-10 12:00", "2024-05-12 00:00"},    // 7 is Sunday
+		{"0 0 1,15 * *", "2024-05-10 12:00", "2024-05-15 00:00"},
+		{"0 0 29 2 *", "2024-03-01 00:00", "2028-02-29 00:00"},
+		{"0 12 13 * 5", "2024-05-10 12:00", "2024-05-13 12:00"}, // 13th or Friday
+		{"@hourly", "2024-12-31 23:59", "2025-01-01 00:00"},
+		{"@monthly", "2024-12-15 00:00", "2025-01-01 00:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec+" after "+tt.from, func(t *testing.T) {
+			t.Parallel()
+			s, err := Parse(tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, date(tt.want), s.Next(date(tt.from)))
+		})
+	}
+}
+
+func TestNext_NeverFires(t *testing.T) {
+	t.Parallel()
+	s := MustParse("0 0 30 2 *")
+
+	assert.True(t, s.Next(date("2024-01-01 00:00")).IsZero())
+}
+
+func TestParse_Invalid(t *testing.T) {
+	t.Parallel()
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"@every 5m",
+	} {
+		_, err := Parse(spec)
+		assert.Errorf(t, err, "spec %q", spec)
+	}
+}
+
+func TestMustParse_Panics(t *testing.T) {
+	t.Parallel()
+	assert.Panics(t, func() { MustParse("not a spec") })
+}
@@ -0,0 +1,237 @@
+// Package encryption encrypts personal data stored in the database.
+//
+// Values are sealed with envelope encryption: each value gets a fresh data key
+// that encrypts it with AES-256-GCM, and the data key is itself encrypted
+// (wrapped) with a master key. A sealed value reads
+//
+//	enc:v1:<key ID>:<wrapped data key>:<nonce and ciphertext>
+//
+// so that it names the master key needed to open it. Rotating keys means
+// adding a new master key, making it primary and re-encrypting what
+// NeedsReencryption reports; old keys stay loaded until nothing uses them.
+//
+// Encrypted columns cannot be searched, so lookups go through a blind index:
+// a keyed hash of the plaintext stored next to the ciphertext.
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// KeySize is the size of master and data keys: AES-256.
+const KeySize = 32
+
+// prefix marks sealed values; anything else is read as legacy plaintext.
+const prefix = "enc:v1:"
+
+var (
+	// ErrUnknownKey means a value was sealed with a master key that is not
+	// loaded.
+	ErrUnknownKey = errors.New("encryption: unknown key ID")
+	// ErrMalformed means a sealed value was truncated or tampered with, or
+	// is stored in another field than it was sealed for.
+	ErrMalformed = errors.New("encryption: malformed or tampered value")
+)
+
+var keyIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+// encoding keeps sealed values free of the ':' separator.
+var encoding = base64.RawStdEncoding
+
+// Service seals and opens values and computes blind indexes. It is safe for
+// concurrent use.
+type Service struct {
+	keys     map[string]cipher.AEAD
+	primary  string
+	indexKey []byte
+}
+
+// NewService creates a Service sealing new values with the master key
+// primary. keys maps key IDs to KeySize-byte master keys; indexKey, at least
+// KeySize bytes, keys the blind indexes.
+func NewService(keys map[string][]byte, primary string, indexKey []byte) (*Service, error) {
+	s := &Service{
+		keys:     make(map[string]cipher.AEAD, len(keys)),
+		primary:  primary,
+		indexKey: indexKey,
+	}
+	for id, key := range keys {
+		if !keyIDPattern.MatchString(id) {
+			return nil, fmt.Errorf("encryption: key ID %q must be 1 to 32 letters, digits, '_' or '-'", id)
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("encryption: key %q: %w", id, err)
+		}
+		s.keys[id] = aead
+	}
+	if _, ok := s.keys[primary]; !ok {
+		return nil, fmt.Errorf("encryption: primary key %q is not loaded", primary)
+	}
+	if len(indexKey) < KeySize {
+		return nil, fmt.Errorf("encryption: blind index key must be at least %d bytes", KeySize)
+	}
+	return s, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// PrimaryKeyID returns the ID of the key new values are sealed with.
+func (s *Service) PrimaryKeyID() string {
+	return s.primary
+}
+
+// Encrypt seals plaintext for field, e.g. "users.phone_number". The field is
+// authenticated, so a value copied into another field fails to open. The
+// empty string is stored as is.
+func (s *Service) Encrypt(field, plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+
+	dataKey := make([]byte, KeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", fmt.Errorf("encryption: generating data key: %w", err)
+	}
+	data, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := seal(data, []byte(plaintext), []byte(field))
+	if err != nil {
+		return "", err
+	}
+	wrapped, err := seal(s.keys[s.primary], dataKey, []byte(s.primary))
+	if err != nil {
+		return "", err
+	}
+
+	return prefix + s.primary + ":" + encoding.EncodeToString(wrapped) + ":" + encoding.EncodeToString(sealed), nil
+}
+
+// seal encrypts plaintext under a random nonce and prepends the nonce.
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("encryption: generating nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// Decrypt opens a value sealed by Encrypt for field. Values that were never
+// sealed, i.e. written before the field was encrypted, are returned as they
+// are until re-encrypted.
+func (s *Service) Decrypt(field, value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	keyID, wrapped, sealed, err := parse(value)
+	if err != nil {
+		return "", err
+	}
+	master, ok := s.keys[keyID]
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrUnknownKey, keyID)
+	}
+	dataKey, err := open(master, wrapped, []byte(keyID))
+	if err != nil {
+		return "", err
+	}
+	data, err := newAEAD(dataKey)
+	if err != nil {
+		return "", ErrMalformed
+	}
+	plaintext, err := open(data, sealed, []byte(field))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+func open(aead cipher.AEAD, sealed, additionalData []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrMalformed
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], additionalData)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	return plaintext, nil
+}
+
+func parse(value string) (keyID string, wrapped, sealed []byte, err error) {
+	parts := strings.Split(strings.TrimPrefix(value, prefix), ":")
+	if len(parts) != 3 {
+		return "", nil, nil, ErrMalformed
+	}
+	wrapped, err = encoding.DecodeString(parts[1])
+	if err != nil {
+		return "", nil, nil, ErrMalformed
+	}
+	sealed, err = encoding.DecodeString(parts[2])
+	if err != nil {
+		return "", nil, nil, ErrMalformed
+	}
+	return parts[0], wrapped, sealed, nil
+}
+
+// IsEncrypted reports whether value was sealed by Encrypt.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// KeyID returns the ID of the master key value was sealed with, or "" for a
+// value that is not sealed.
+func KeyID(value string) string {
+	if !IsEncrypted(value) {
+		return ""
+	}
+	keyID, _, _ := strings.Cut(strings.TrimPrefix(value, prefix), ":")
+	return keyID
+}
+
+// SealedPrefix returns the prefix of every value sealed with the key keyID,
+// e.g. to find values under other keys in a database.
+func SealedPrefix(keyID string) string {
+	return prefix + keyID + ":"
+}
+
+// NeedsReencryption reports whether value is plaintext or sealed with a key
+// other than the primary one.
+func (s *Service) NeedsReencryption(value string) bool {
+	return value != "" && KeyID(value) != s.primary
+}
+
+// BlindIndex returns a keyed hash of value for field, to store next to its
+// ciphertext and look it up by equality. Equal values hash equally, so
+// callers should normalize value first. The empty string hashes to "".
+func (s *Service) BlindIndex(field, value string) string {
+	if value == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, s.indexKey)
+	mac.Write([]byte(field))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,163 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"english-learning/configs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const field = "users.phone_number"
+
+var (
+	oldKey   = bytes.Repeat([]byte{1}, KeySize)
+	newKey   = bytes.Repeat([]byte{2}, KeySize)
+	indexKey = bytes.Repeat([]byte{3}, KeySize)
+)
+
+func newTestService(t *testing.T, primary string) *Service {
+	t.Helper()
+	s, err := NewService(map[string][]byte{"old": oldKey, "new": newKey}, primary, indexKey)
+	require.NoError(t, err)
+	return s
+}
+
+func TestService_RoundTrip(t *testing.T) {
+	t.Parallel()
+	s := newTestService(t, "new")
+
+	sealed, err := s.Encrypt(field, "+84901234567")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(sealed, "enc:v1:new:"))
+	assert.NotContains(t, sealed, "84901234567")
+	assert.Equal(t, "new", KeyID(sealed))
+
+	again, err := s.Encrypt(field, "+84901234567")
+	require.NoError(t, err)
+	assert.NotEqual(t, sealed, again, "every value gets its own data key and nonce")
+
+	opened, err := s.Decrypt(field, sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "+84901234567", opened)
+}
+
+func TestService_EmptyAndPlaintext(t *testing.T) {
+	t.Parallel()
+	s := newTestService(t, "new")
+
+	sealed, err := s.Encrypt(field, "")
+	require.NoError(t, err)
+	assert.Empty(t, sealed)
+
+	opened, err := s.Decrypt(field, "+84901234567")
+	require.NoError(t, err)
+	assert.Equal(t, "+84901234567", opened, "values written before encryption are read as they are")
+	assert.True(t, s.NeedsReencryption("+84901234567"))
+	assert.False(t, s.NeedsReencryption(""))
+}
+
+func TestService_Rotation(t *testing.T) {
+	t.Parallel()
+	before := newTestService(t, "old")
+	sealed, err := before.Encrypt(field, "+84901234567")
+	require.NoError(t, err)
+	assert.False(t, before.NeedsReencryption(sealed))
+
+	after := newTestService(t, "new")
+	assert.True(t, after.NeedsReencryption(sealed))
+	opened, err := after.Decrypt(field, sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "+84901234567", opened, "retired keys still decrypt")
+
+	retired, err := NewService(map[string][]byte{"new": newKey}, "new", indexKey)
+	require.NoError(t, err)
+	_, err = retired.Decrypt(field, sealed)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestService_RejectsTamperedValues(t *testing.T) {
+	t.Parallel()
+	s := newTestService(t, "new")
+	sealed, err := s.Encrypt(field, "+84901234567")
+	require.NoError(t, err)
+
+	_, err = s.Decrypt("users.birthdate", sealed)
+	assert.ErrorIs(t, err, ErrMalformed, "values are bound to their field")
+
+	parts := strings.Split(sealed, ":")
+	data, err := base64.RawStdEncoding.DecodeString(parts[4])
+	require.NoError(t, err)
+	data[len(data)-1] ^= 1
+	parts[4] = base64.RawStdEncoding.EncodeToString(data)
+	_, err = s.Decrypt(field, strings.Join(parts, ":"))
+	assert.ErrorIs(t, err, ErrMalformed)
+
+	for _, value := range []string{"enc:v1:new", "enc:v1:new:!!:!!", "enc:v1:new::"} {
+		_, err = s.Decrypt(field, value)
+		assert.ErrorIsf(t, err, ErrMalformed, "Decrypt(%q)", value)
+	}
+}
+
+func TestService_BlindIndex(t *testing.T) {
+	t.Parallel()
+	s := newTestService(t, "new")
+
+	hash := s.BlindIndex(field, "+84901234567")
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, newTestService(t, "old").BlindIndex(field, "+84901234567"), "independent of the master keys")
+	assert.NotEqual(t, hash, s.BlindIndex(field, "+84901234568"))
+	assert.NotEqual(t, hash, s.BlindIndex("users.other", "+84901234567"))
+	assert.Empty(t, s.BlindIndex(field, ""))
+}
+
+func TestNewService_ValidatesKeys(t *testing.T) {
+	t.Parallel()
+	_, err := NewService(map[string][]byte{"a": oldKey}, "b", indexKey)
+	assert.ErrorContains(t, err, `primary key "b" is not loaded`)
+
+	_, err = NewService(map[string][]byte{"a": oldKey[:16]}, "a", indexKey)
+	assert.ErrorContains(t, err, "must be 32 bytes")
+
+	_, err = NewService(map[string][]byte{"a:b": oldKey}, "a:b", indexKey)
+	assert.ErrorContains(t, err, "key ID")
+
+	_, err = NewService(map[string][]byte{"a": oldKey}, "a", indexKey[:16])
+	assert.ErrorContains(t, err, "blind index key")
+}
+
+func TestNew_LoadsKeysFromConfigAndFiles(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	encode := base64.StdEncoding.EncodeToString
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new.key"), []byte(encode(newKey)+"\n"), 0o600))
+
+	s, err := New(configs.EncryptionConfig{
+		PrimaryKeyID:  "new",
+		Keys:          []string{"old:" + encode(oldKey)},
+		KeyDir:        dir,
+		BlindIndexKey: encode(indexKey),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "new", s.PrimaryKeyID())
+
+	old := newTestService(t, "old")
+	sealed, err := old.Encrypt(field, "+84901234567")
+	require.NoError(t, err)
+	opened, err := s.Decrypt(field, sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "+84901234567", opened)
+
+	_, err = New(configs.EncryptionConfig{
+		PrimaryKeyID:  "new",
+		Keys:          []string{"new:" + encode(oldKey)},
+		KeyDir:        dir,
+		BlindIndexKey: encode(indexKey),
+	})
+	assert.ErrorContains(t, err, `key "new" is defined twice`)
+}
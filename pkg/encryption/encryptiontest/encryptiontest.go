@@ -0,0 +1,28 @@
+// Package encryptiontest gives tests an encryption.Service with fixed keys.
+package encryptiontest
+
+import (
+	"bytes"
+	"english-learning/pkg/encryption"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// KeyIDs are the keys every Service from New holds, oldest first; tests
+// rotate by creating a Service with a later primary.
+var KeyIDs = []string{"test-1", "test-2"}
+
+// New returns a Service holding KeyIDs and sealing new values with primary.
+// Every Service it returns shares the same keys, including the blind index
+// key.
+func New(t testing.TB, primary string) *encryption.Service {
+	t.Helper()
+	keys := make(map[string][]byte, len(KeyIDs))
+	for i, id := range KeyIDs {
+		keys[id] = bytes.Repeat([]byte{byte(i + 1)}, encryption.KeySize)
+	}
+	enc, err := encryption.NewService(keys, primary, bytes.Repeat([]byte{0xff}, encryption.KeySize))
+	require.NoError(t, err)
+	return enc
+}
@@ -0,0 +1,62 @@
+package encryption
+
+import (
+	"encoding/base64"
+	"english-learning/configs"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// keyFileExt is the extension of the key files in configs.EncryptionConfig.KeyDir.
+const keyFileExt = ".key"
+
+// New creates a Service from the keys in cfg and in the files of cfg.KeyDir.
+func New(cfg configs.EncryptionConfig) (*Service, error) {
+	keys := make(map[string][]byte)
+	add := func(id, material, source string) error {
+		if _, ok := keys[id]; ok {
+			return fmt.Errorf("encryption: key %q is defined twice (%s)", id, source)
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(material))
+		if err != nil {
+			return fmt.Errorf("encryption: key %q in %s is not valid base64", id, source)
+		}
+		keys[id] = key
+		return nil
+	}
+
+	for _, entry := range cfg.Keys {
+		id, material, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("encryption: encryption.keys entries must be <id>:<base64 key>")
+		}
+		if err := add(id, material, "encryption.keys"); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.KeyDir != "" {
+		files, err := filepath.Glob(filepath.Join(cfg.KeyDir, "*"+keyFileExt))
+		if err != nil {
+			return nil, fmt.Errorf("encryption: listing %s: %w", cfg.KeyDir, err)
+		}
+		for _, file := range files {
+			material, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("encryption: reading key: %w", err)
+			}
+			if err := add(strings.TrimSuffix(filepath.Base(file), keyFileExt), string(material), file); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	indexKey, err := base64.StdEncoding.DecodeString(cfg.BlindIndexKey)
+	if err != nil {
+		return nil, fmt.Errorf("encryption: encryption.blind_index_key is not valid base64")
+	}
+
+	return NewService(keys, cfg.PrimaryKeyID, indexKey)
+}
@@ -0,0 +1,45 @@
+// Package etag builds entity tags from resource versions and evaluates the
+// If-Match and If-None-Match preconditions (RFC 9110, section 13.1).
+package etag
+
+import (
+	"strconv"
+	"strings"
+)
+
+// FromVersion returns the strong entity tag of a resource version.
+func FromVersion(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// Match reports whether an If-Match header value admits tag. It uses strong
+// comparison, so weak tags never match; "*" matches any current resource.
+func Match(header, tag string) bool {
+	for _, candidate := range split(header) {
+		if candidate == "*" || candidate == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// NoneMatch reports whether an If-None-Match header value lists tag, i.e.
+// whether the client's copy is current. It uses weak comparison.
+func NoneMatch(header, tag string) bool {
+	for _, candidate := range split(header) {
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(tag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+func split(header string) []string {
+	var tags []string
+	for _, part := range strings.Split(header, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			tags = append(tags, part)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,45 @@
+package etag
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+	tag := FromVersion(3)
+	assert.Equal(t, `"3"`, tag)
+
+	for header, want := range map[string]bool{
+		`"3"`:         true,
+		`"2", "3"`:    true,
+		`*`:           true,
+		`"2"`:         false,
+		`W/"3"`:       false,
+		`3`:           false,
+		``:            false,
+		`"30"`:        false,
+		` "1" ,"3" `:  true,
+		`"1",W/"3"`:   false,
+		`"3"garbage`:  false,
+		`"2",, "3" ,`: true,
+	} {
+		assert.Equalf(t, want, Match(header, tag), "If-Match: %s", header)
+	}
+}
+
+func TestNoneMatch(t *testing.T) {
+	t.Parallel()
+	tag := FromVersion(3)
+	for header, want := range map[string]bool{
+		`"3"`:      true,
+		`W/"3"`:    true,
+		`"1", "3"`: true,
+		`*`:        true,
+		`"2"`:      false,
+		``:         false,
+	} {
+		assert.Equalf(t, want, NoneMatch(header, tag), "If-None-Match: %s", header)
+	}
+}
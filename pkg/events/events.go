@@ -0,0 +1,50 @@
+// Package events defines the domain event abstractions shared by every module.
+// Services publish events inside their unit of work; subscribers receive them
+// asynchronously, at least once, after the transaction has committed. Like
+// pkg/uow it has no dependencies so domain packages can use it;
+// internal/outbox provides the Postgres-backed implementation.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Event is a fact that happened in the domain. It must marshal to JSON.
+type Event interface {
+	// EventName identifies the event type, e.g. "auth.user_registered".
+	// Names are persisted, so never rename an existing one.
+	EventName() string
+}
+
+// Publisher records events for delivery. Called with a ctx inside a unit of
+// work, the events are only delivered if that transaction commits.
+type Publisher interface {
+	Publish(ctx context.Context, events ...Event) error
+}
+
+// Envelope is a published event as seen by subscribers.
+type Envelope struct {
+	ID         uint64
+	Name       string
+	Payload    json.RawMessage
+	OccurredAt time.Time
+	// Attempt is 1 on first delivery and increases with every retry.
+	Attempt int
+}
+
+// Decode unmarshals the payload into v, normally a pointer to the event type
+// named by e.Name.
+func (e Envelope) Decode(v interface{}) error {
+	return json.Unmarshal(e.Payload, v)
+}
+
+// Handler reacts to an event. Delivery is at-least-once, so handlers must be
+// idempotent; returning an error schedules a retry.
+type Handler func(ctx context.Context, e Envelope) error
+
+// Subscriber registers handlers by event name.
+type Subscriber interface {
+	Subscribe(name string, h Handler)
+}
@@ -0,0 +1,19 @@
+package health
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// DBPing checks that the database connection pool can reach the server.
+func DBPing(db *gorm.DB) CheckFunc {
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return fmt.Errorf("getting sql.DB: %w", err)
+		}
+		return sqlDB.PingContext(ctx)
+	}
+}
@@ -0,0 +1,142 @@
+package health
+
+import (
+	"context"
+	"english-learning/pkg/logger"
+	"english-learning/pkg/response"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ErrShuttingDown is reported by readiness once graceful shutdown has begun.
+var ErrShuttingDown = errors.New("shutting down")
+
+// CheckFunc reports whether a dependency is usable. It must honour ctx.
+type CheckFunc func(ctx context.Context) error
+
+type namedCheck struct {
+	name  string
+	check CheckFunc
+}
+
+// Registry holds the readiness checks registered by the application and its
+// modules and serves the liveness and readiness endpoints.
+type Registry struct {
+	timeout      time.Duration
+	mu           sync.RWMutex
+	checks       []namedCheck
+	shuttingDown atomic.Bool
+}
+
+// NewRegistry creates a Registry that gives every check at most timeout to
+// complete. A non-positive timeout means checks only obey the request context.
+func NewRegistry(timeout time.Duration) *Registry {
+	return &Registry{timeout: timeout}
+}
+
+// Register adds a readiness check under the given name.
+func (r *Registry) Register(name string, check CheckFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, namedCheck{name: name, check: check})
+}
+
+// SetShuttingDown makes readiness fail from now on so that load balancers stop
+// routing new traffic while in-flight requests drain.
+func (r *Registry) SetShuttingDown() {
+	r.shuttingDown.Store(true)
+}
+
+// Report is the outcome of a readiness evaluation. Checks maps each check to
+// "ok" or "fail"; the reasons are logged, not reported, because /readyz is
+// public and errors can name hosts, users or queries.
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// Ready reports whether every check passed.
+func (rep Report) Ready() bool {
+	return rep.Status == statusOK
+}
+
+const (
+	statusOK   = "ok"
+	statusFail = "fail"
+)
+
+// Check runs all registered checks concurrently and collects their results.
+func (r *Registry) Check(ctx context.Context) Report {
+	r.mu.RLock()
+	checks := make([]namedCheck, len(r.checks))
+	copy(checks, r.checks)
+	r.mu.RUnlock()
+
+	rep := Report{Status: statusOK, Checks: make(map[string]string, len(checks))}
+	if r.shuttingDown.Load() {
+		rep.Status = statusFail
+		rep.Checks["shutdown"] = statusFail
+		return rep
+	}
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	for _, c := range checks {
+		wg.Add(1)
+		go func(c namedCheck) {
+			defer wg.Done()
+			err := r.run(ctx, c.check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				logger.FromContext(ctx).Named("health").Warn("Readiness check failed",
+					zap.String("check", c.name), zap.Error(err))
+				rep.Status = statusFail
+				rep.Checks[c.name] = statusFail
+				return
+			}
+			rep.Checks[c.name] = statusOK
+		}(c)
+	}
+	wg.Wait()
+
+	return rep
+}
+
+func (r *Registry) run(ctx context.Context, check CheckFunc) error {
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+	return check(ctx)
+}
+
+// LivenessHandler reports that the process is up and serving HTTP. It does not
+// touch dependencies, so a database outage does not get the instance killed.
+func (r *Registry) LivenessHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		response.Success(c, gin.H{"status": statusOK}, response.MsgSuccess)
+	}
+}
+
+// ReadinessHandler runs every registered check and answers 503 if any fails.
+func (r *Registry) ReadinessHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rep := r.Check(c.Request.Context())
+		if !rep.Ready() {
+			response.ErrorWithData(c, http.StatusServiceUnavailable, response.CodeServiceUnavailable, response.MsgServiceNotReady, rep)
+			return
+		}
+		response.Success(c, rep, response.MsgSuccess)
+	}
+}
@@ -0,0 +1,81 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func serve(h gin.HandlerFunc) *httptest.ResponseRecorder {
+	r := gin.New()
+	r.GET("/", h)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	return w
+}
+
+func TestRegistry_AllChecksPass(t *testing.T) {
+	t.Parallel()
+	reg := NewRegistry(time.Second)
+	reg.Register("database", func(ctx context.Context) error { return nil })
+	reg.Register("cache", func(ctx context.Context) error { return nil })
+
+	rep := reg.Check(context.Background())
+
+	assert.True(t, rep.Ready())
+	assert.Equal(t, map[string]string{"database": "ok", "cache": "ok"}, rep.Checks)
+	assert.Equal(t, http.StatusOK, serve(reg.ReadinessHandler()).Code)
+}
+
+func TestRegistry_FailingCheck(t *testing.T) {
+	t.Parallel()
+	reg := NewRegistry(time.Second)
+	reg.Register("database", func(ctx context.Context) error { return errors.New("connection refused") })
+
+	rep := reg.Check(context.Background())
+
+	assert.False(t, rep.Ready())
+	assert.Equal(t, "fail", rep.Checks["database"])
+	w := serve(reg.ReadinessHandler())
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.NotContains(t, w.Body.String(), "connection refused", "reasons are logged, not served")
+}
+
+func TestRegistry_CheckTimeout(t *testing.T) {
+	t.Parallel()
+	reg := NewRegistry(10 * time.Millisecond)
+	reg.Register("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	rep := reg.Check(context.Background())
+
+	assert.False(t, rep.Ready())
+	assert.Equal(t, "fail", rep.Checks["slow"])
+}
+
+func TestRegistry_ShuttingDown(t *testing.T) {
+	t.Parallel()
+	reg := NewRegistry(time.Second)
+	reg.Register("database", func(ctx context.Context) error { return nil })
+
+	reg.SetShuttingDown()
+
+	rep := reg.Check(context.Background())
+	assert.False(t, rep.Ready())
+	assert.Equal(t, map[string]string{"shutdown": "fail"}, rep.Checks)
+	assert.Equal(t, http.StatusServiceUnavailable, serve(reg.ReadinessHandler()).Code)
+	// Liveness is unaffected: the process is still serving in-flight requests.
+	assert.Equal(t, http.StatusOK, serve(reg.LivenessHandler()).Code)
+}
@@ -0,0 +1,107 @@
+// Package i18n holds the translated API message catalog and negotiates the
+// locale of a request.
+//
+// Catalog keys are message IDs (the response.Msg* constants), error codes, and
+// validation keys of the form "validation.<tag>" and "field.<jsonName>".
+// Values may contain {field} and {param} placeholders.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// Supported locales.
+const (
+	English    = "en"
+	Vietnamese = "vi"
+	Default    = English
+)
+
+//go:embed locales/*.json
+var localesFS embed.FS
+
+var (
+	catalogs = mustLoad()
+	matcher  = language.NewMatcher([]language.Tag{language.English, language.Vietnamese})
+)
+
+func mustLoad() map[string]map[string]string {
+	files, err := localesFS.ReadDir("locales")
+	if err != nil {
+		panic(fmt.Sprintf("i18n: reading locales: %v", err))
+	}
+
+	out := make(map[string]map[string]string, len(files))
+	for _, f := range files {
+		data, err := localesFS.ReadFile(path.Join("locales", f.Name()))
+		if err != nil {
+			panic(fmt.Sprintf("i18n: reading %s: %v", f.Name(), err))
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: parsing %s: %v", f.Name(), err))
+		}
+		out[strings.TrimSuffix(f.Name(), ".json")] = messages
+	}
+	return out
+}
+
+// IsSupported reports whether a catalog exists for locale.
+func IsSupported(locale string) bool {
+	_, ok := catalogs[locale]
+	return ok
+}
+
+// Negotiate picks the best supported locale for an Accept-Language header.
+func Negotiate(acceptLanguage string) string {
+	if acceptLanguage == "" {
+		return Default
+	}
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return Default
+	}
+	_, idx, conf := matcher.Match(tags...)
+	if conf == language.No {
+		return Default
+	}
+	return []string{English, Vietnamese}[idx]
+}
+
+// Lookup returns the message for key in locale, falling back to the default
+// locale. ok is false if neither catalog has the key.
+func Lookup(locale, key string) (string, bool) {
+	if msg, ok := catalogs[locale][key]; ok {
+		return msg, true
+	}
+	msg, ok := catalogs[Default][key]
+	return msg, ok
+}
+
+// T translates key into locale. Unknown keys are returned unchanged so that a
+// missing translation degrades to the raw message ID rather than an empty string.
+func T(locale, key string) string {
+	if msg, ok := Lookup(locale, key); ok {
+		return msg
+	}
+	return key
+}
+
+// Format translates key and substitutes {name} placeholders from vars.
+func Format(locale, key string, vars map[string]string) string {
+	msg := T(locale, key)
+	if len(vars) == 0 {
+		return msg
+	}
+	pairs := make([]string, 0, len(vars)*2)
+	for k, v := range vars {
+		pairs = append(pairs, "{"+k+"}", v)
+	}
+	return strings.NewReplacer(pairs...).Replace(msg)
+}
@@ -0,0 +1,61 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", English},
+		{"vi", Vietnamese},
+		{"vi-VN,vi;q=0.9,en-US;q=0.8", Vietnamese},
+		{"en-GB,en;q=0.9", English},
+		{"fr-FR,vi;q=0.5", Vietnamese},
+		{"ja", English},
+		{"not a header;;", English},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Negotiate(tt.header), "Accept-Language: %q", tt.header)
+	}
+}
+
+func TestT_FallsBackToDefaultThenKey(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "Không tìm thấy người dùng", T(Vietnamese, "USER_NOT_FOUND"))
+	assert.Equal(t, "User not found", T("de", "USER_NOT_FOUND"))
+	assert.Equal(t, "NO_SUCH_MESSAGE", T(Vietnamese, "NO_SUCH_MESSAGE"))
+}
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+	got := Format(Vietnamese, "validation.min", map[string]string{"field": "mật khẩu", "param": "8"})
+	assert.Equal(t, "Trường 'mật khẩu' phải có ít nhất 8 ký tự", got)
+}
+
+// Every message must exist in the default catalog, and every non-field message
+// must be translated in every other catalog.
+func TestCatalogsAreComplete(t *testing.T) {
+	t.Parallel()
+	for locale, messages := range catalogs {
+		for key := range messages {
+			_, ok := catalogs[Default][key]
+			if strings.HasPrefix(key, "field.") {
+				continue
+			}
+			assert.True(t, ok, "%s: key %q missing from %s catalog", locale, key, Default)
+		}
+	}
+	for key := range catalogs[Default] {
+		for locale, messages := range catalogs {
+			_, ok := messages[key]
+			assert.True(t, ok, "key %q missing from %s catalog", key, locale)
+		}
+	}
+}
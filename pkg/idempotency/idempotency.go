@@ -0,0 +1,77 @@
+// Package idempotency makes unsafe requests safe to retry. A client sends an
+// Idempotency-Key header with a POST; the first response for that key is
+// stored and replayed to every retry, so a request whose response was lost
+// in transit is never executed twice.
+//
+// Keys are scoped to the authenticated user (or shared by anonymous callers)
+// and to the request's method and path. Each stored request keeps a
+// fingerprint of its body: reusing a key for a different request is rejected
+// rather than answered with an unrelated response. internal/idempotency
+// provides the Postgres-backed Store.
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Header is the request header carrying the key.
+const Header = "Idempotency-Key"
+
+// ReplayedHeader is set to "true" on responses replayed from the store.
+const ReplayedHeader = "Idempotent-Replayed"
+
+// MaxKeyLength bounds the length of a key. UUIDs, the recommended keys, are
+// 36 characters.
+const MaxKeyLength = 255
+
+// Key identifies a stored request.
+type Key struct {
+	// Scope is the authenticated user's ID, or "" for anonymous requests.
+	Scope string
+	Key   string
+	// Route is the request's method and path, e.g. "POST /users".
+	Route string
+}
+
+// Response is a stored response, replayed to retries.
+type Response struct {
+	Status int
+	// Header holds the replayed headers only (see replayedHeaders).
+	Header http.Header
+	Body   []byte
+}
+
+// Record is the stored state of a key.
+type Record struct {
+	Key
+	// Fingerprint is a hash of the request that first used the key.
+	Fingerprint string
+	// Response is nil while the first request is still in flight.
+	Response *Response
+	// LockedUntil is the lease of the in-flight request. A record still in
+	// flight after it (e.g. its instance crashed) may be taken over by a retry.
+	LockedUntil time.Time
+	// ExpiresAt is when the key may be used again for another request.
+	ExpiresAt time.Time
+}
+
+// Store persists records. Implementations must make Acquire atomic: of
+// concurrent requests for the same key, exactly one acquires it.
+type Store interface {
+	// Acquire stores rec as in flight unless a live record exists for its
+	// key. It reports whether the caller now owns the key; if not, it returns
+	// the existing record. Expired records, and records abandoned in flight
+	// by a request with the same fingerprint, are replaced.
+	Acquire(ctx context.Context, rec Record) (existing *Record, acquired bool, err error)
+	// Complete stores the response of the request that acquired rec. It
+	// does nothing if rec's lease was lost to a retry meanwhile.
+	Complete(ctx context.Context, rec Record, res Response) error
+	// Release forgets rec unless it was completed or its lease lost, so that
+	// a retry runs the request again.
+	Release(ctx context.Context, rec Record) error
+	// DeleteExpired deletes records that expired before now and returns how
+	// many there were.
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
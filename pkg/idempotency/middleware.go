@@ -0,0 +1,186 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"english-learning/configs"
+	"english-learning/pkg/logger"
+	"english-learning/pkg/reqctx"
+	"english-learning/pkg/response"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// replayedHeaders are the response headers stored with a response. Others,
+// such as X-Request-ID, describe the retry rather than the original request.
+var replayedHeaders = []string{"Content-Type", "Location", "ETag"}
+
+// inFlightRetryAfter is the Retry-After sent while the first request runs.
+const inFlightRetryAfter = 1 * time.Second
+
+// Middleware makes the routes it guards idempotent for requests carrying an
+// Idempotency-Key header; requests without one, and safe methods, pass
+// through. Mount it after AuthMiddleware so that keys are scoped per user.
+//
+// For a new key the handler runs and its response is stored for cfg.TTL,
+// unless it is a 5xx or was never written: those are forgotten so that a
+// retry runs the request again. A retry of a completed request gets the
+// stored response with Idempotent-Replayed: true; a retry while the first
+// request is still running gets a 409, and reusing the key for a different
+// request a 422.
+func Middleware(store Store, cfg configs.IdempotencyConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		raw := c.GetHeader(Header)
+		if raw == "" || isSafe(c.Request.Method) {
+			c.Next()
+			return
+		}
+		if !validKey(raw) {
+			response.Error(c, http.StatusBadRequest, response.CodeInvalidIdempotencyKey, response.MsgInvalidIdempotencyKey)
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, response.CodeBadRequest, response.MsgMalformedRequest)
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		now := time.Now()
+		rec := Record{
+			Key:         Key{Scope: scope(ctx), Key: raw, Route: c.Request.Method + " " + c.Request.URL.Path},
+			Fingerprint: fingerprint(c.Request, body),
+			LockedUntil: now.Add(cfg.LockTimeout),
+			ExpiresAt:   now.Add(cfg.TTL),
+		}
+		existing, acquired, err := store.Acquire(ctx, rec)
+		if err != nil {
+			response.HandleError(c, fmt.Errorf("acquiring idempotency key: %w", err))
+			c.Abort()
+			return
+		}
+		if !acquired {
+			answerExisting(c, existing, rec.Fingerprint)
+			c.Abort()
+			return
+		}
+
+		run(c, store, rec)
+	}
+}
+
+// run executes the rest of the chain as the owner of rec and records the
+// outcome. The store is updated even if the request's context has expired,
+// and the key is released if the handler panics.
+func run(c *gin.Context, store Store, rec Record) {
+	ctx := context.WithoutCancel(c.Request.Context())
+	w := &recorder{ResponseWriter: c.Writer}
+	c.Writer = w
+
+	completed := false
+	defer func() {
+		c.Writer = w.ResponseWriter
+		if completed {
+			return
+		}
+		if err := store.Release(ctx, rec); err != nil {
+			logger.Errorf("idempotency", "Releasing key %q: %v", rec.Key.Key, err)
+		}
+	}()
+
+	c.Next()
+
+	if !w.Written() || w.Status() >= http.StatusInternalServerError {
+		return
+	}
+	res := Response{Status: w.Status(), Header: http.Header{}, Body: w.body.Bytes()}
+	for _, name := range replayedHeaders {
+		if v := w.Header().Values(name); len(v) > 0 {
+			res.Header[name] = v
+		}
+	}
+	if err := store.Complete(ctx, rec, res); err != nil {
+		logger.Errorf("idempotency", "Storing response for key %q: %v", rec.Key.Key, err)
+		return
+	}
+	completed = true
+}
+
+// answerExisting responds to a request whose key is owned by another one.
+func answerExisting(c *gin.Context, existing *Record, fp string) {
+	switch {
+	case existing.Fingerprint != fp:
+		response.Error(c, http.StatusUnprocessableEntity, response.CodeIdempotencyKeyReused, response.MsgIdempotencyKeyReused)
+	case existing.Response == nil:
+		c.Header("Retry-After", strconv.Itoa(int(inFlightRetryAfter.Seconds())))
+		response.Error(c, http.StatusConflict, response.CodeIdempotencyRequestInFlight, response.MsgIdempotencyRequestInFlight)
+	default:
+		res := existing.Response
+		for name, values := range res.Header {
+			c.Writer.Header()[name] = values
+		}
+		c.Header(ReplayedHeader, "true")
+		c.Writer.WriteHeader(res.Status)
+		_, _ = c.Writer.Write(res.Body)
+	}
+}
+
+// fingerprint hashes what makes two requests the same: method, target and
+// body.
+func fingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	_, _ = io.WriteString(h, r.Method+" "+r.URL.RequestURI()+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func scope(ctx context.Context) string {
+	if id, ok := reqctx.UserID(ctx); ok {
+		return strconv.FormatUint(uint64(id), 10)
+	}
+	return ""
+}
+
+// validKey accepts 1 to MaxKeyLength printable ASCII characters.
+func validKey(key string) bool {
+	if len(key) > MaxKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func isSafe(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// recorder keeps a copy of the response body as it is written.
+type recorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,225 @@
+package idempotency
+
+import (
+	"encoding/json"
+	"english-learning/configs"
+	"english-learning/pkg/reqctx"
+	"english-learning/pkg/response"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+var testConfig = configs.IdempotencyConfig{TTL: time.Hour, LockTimeout: time.Minute}
+
+// server guards POST /items with the middleware. The X-User header stands in
+// for AuthMiddleware. handler counts its runs.
+func server(store Store, handler gin.HandlerFunc) *gin.Engine {
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if id, err := strconv.ParseUint(c.GetHeader("X-User"), 10, 64); err == nil {
+			c.Request = c.Request.WithContext(reqctx.WithUserID(c.Request.Context(), uint(id)))
+		}
+	})
+	r.POST("/items", Middleware(store, testConfig), handler)
+	return r
+}
+
+type call struct {
+	key, user, body string
+}
+
+func do(r http.Handler, c call) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(c.body))
+	req.Header.Set("Content-Type", "application/json")
+	if c.key != "" {
+		req.Header.Set(Header, c.key)
+	}
+	if c.user != "" {
+		req.Header.Set("X-User", c.user)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func code(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body response.APIResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return body.Code
+}
+
+func creating(runs *atomic.Int32) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		n := runs.Add(1)
+		c.Header("Location", "/items/"+strconv.Itoa(int(n)))
+		c.JSON(http.StatusCreated, gin.H{"id": n})
+	}
+}
+
+func TestMiddleware_ReplaysFirstResponse(t *testing.T) {
+	t.Parallel()
+	var runs atomic.Int32
+	r := server(newMemoryStore(), creating(&runs))
+
+	first := do(r, call{key: "k1", body: `{"name":"a"}`})
+	retry := do(r, call{key: "k1", body: `{"name":"a"}`})
+
+	assert.EqualValues(t, 1, runs.Load())
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, "/items/1", retry.Header().Get("Location"))
+	assert.Equal(t, "application/json; charset=utf-8", retry.Header().Get("Content-Type"))
+	assert.Empty(t, first.Header().Get(ReplayedHeader))
+	assert.Equal(t, "true", retry.Header().Get(ReplayedHeader))
+}
+
+func TestMiddleware_WithoutKeyRunsEveryTime(t *testing.T) {
+	t.Parallel()
+	var runs atomic.Int32
+	r := server(newMemoryStore(), creating(&runs))
+
+	do(r, call{body: `{}`})
+	do(r, call{body: `{}`})
+
+	assert.EqualValues(t, 2, runs.Load())
+}
+
+func TestMiddleware_KeysAreScopedPerUser(t *testing.T) {
+	t.Parallel()
+	var runs atomic.Int32
+	r := server(newMemoryStore(), creating(&runs))
+
+	do(r, call{key: "k1", user: "1", body: `{}`})
+	other := do(r, call{key: "k1", user: "2", body: `{}`})
+	anonymous := do(r, call{key: "k1", body: `{}`})
+
+	assert.EqualValues(t, 3, runs.Load())
+	assert.Empty(t, other.Header().Get(ReplayedHeader))
+	assert.Empty(t, anonymous.Header().Get(ReplayedHeader))
+}
+
+func TestMiddleware_RejectsReuseWithDifferentBody(t *testing.T) {
+	t.Parallel()
+	var runs atomic.Int32
+	r := server(newMemoryStore(), creating(&runs))
+
+	do(r, call{key: "k1", body: `{"name":"a"}`})
+	w := do(r, call{key: "k1", body: `{"name":"b"}`})
+
+	assert.EqualValues(t, 1, runs.Load())
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, response.CodeIdempotencyKeyReused, code(t, w))
+}
+
+func TestMiddleware_RejectsInvalidKey(t *testing.T) {
+	t.Parallel()
+	var runs atomic.Int32
+	r := server(newMemoryStore(), creating(&runs))
+
+	for _, key := range []string{"has space", strings.Repeat("k", MaxKeyLength+1), "clé"} {
+		w := do(r, call{key: key, body: `{}`})
+		assert.Equalf(t, http.StatusBadRequest, w.Code, "key %q", key)
+		assert.Equal(t, response.CodeInvalidIdempotencyKey, code(t, w))
+	}
+	assert.Zero(t, runs.Load())
+}
+
+func TestMiddleware_ConcurrentDuplicateGetsConflict(t *testing.T) {
+	t.Parallel()
+	started, finish := make(chan struct{}), make(chan struct{})
+	var runs atomic.Int32
+	r := server(newMemoryStore(), func(c *gin.Context) {
+		runs.Add(1)
+		close(started)
+		<-finish
+		c.JSON(http.StatusCreated, gin.H{"id": 1})
+	})
+
+	var wg sync.WaitGroup
+	var first *httptest.ResponseRecorder
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		first = do(r, call{key: "k1", body: `{}`})
+	}()
+	<-started
+
+	w := do(r, call{key: "k1", body: `{}`})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, response.CodeIdempotencyRequestInFlight, code(t, w))
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+
+	close(finish)
+	wg.Wait()
+	assert.Equal(t, http.StatusCreated, first.Code)
+
+	w = do(r, call{key: "k1", body: `{}`})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.EqualValues(t, 1, runs.Load())
+}
+
+func TestMiddleware_ServerErrorsAreNotStored(t *testing.T) {
+	t.Parallel()
+	var runs atomic.Int32
+	r := server(newMemoryStore(), func(c *gin.Context) {
+		if runs.Add(1) == 1 {
+			c.JSON(http.StatusServiceUnavailable, gin.H{})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{})
+	})
+
+	assert.Equal(t, http.StatusServiceUnavailable, do(r, call{key: "k1", body: `{}`}).Code)
+	assert.Equal(t, http.StatusCreated, do(r, call{key: "k1", body: `{}`}).Code)
+	assert.EqualValues(t, 2, runs.Load())
+}
+
+func TestMiddleware_PanicReleasesKey(t *testing.T) {
+	t.Parallel()
+	store := newMemoryStore()
+	r := gin.New()
+	r.Use(gin.CustomRecovery(func(c *gin.Context, _ interface{}) {
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}))
+	r.POST("/items", Middleware(store, testConfig), func(c *gin.Context) { panic("boom") })
+
+	assert.Equal(t, http.StatusInternalServerError, do(r, call{key: "k1", body: `{}`}).Code)
+	_, ok := store.get(Key{Key: "k1", Route: "POST /items"})
+	assert.False(t, ok, "a retry must run the request again")
+}
+
+func TestMiddleware_TakesOverAbandonedKey(t *testing.T) {
+	t.Parallel()
+	store := newMemoryStore()
+	var runs atomic.Int32
+	r := server(store, creating(&runs))
+
+	// A request that acquired the key and whose instance died before its
+	// lease ended.
+	abandoned := Record{
+		Key:         Key{Key: "k1", Route: "POST /items"},
+		LockedUntil: time.Now().Add(-time.Second),
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+	abandoned.Fingerprint = fingerprint(httptest.NewRequest(http.MethodPost, "/items", nil), []byte(`{}`))
+	_, _, _ = store.Acquire(t.Context(), abandoned)
+
+	w := do(r, call{key: "k1", body: `{}`})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.EqualValues(t, 1, runs.Load())
+}
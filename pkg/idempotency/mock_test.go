@@ -0,0 +1,76 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryStore is an in-memory Store with the semantics of the Postgres one.
+type memoryStore struct {
+	mu      sync.Mutex
+	records map[Key]Record
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{records: make(map[Key]Record)}
+}
+
+func (s *memoryStore) Acquire(_ context.Context, rec Record) (*Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if existing, ok := s.records[rec.Key]; ok {
+		abandoned := existing.Response == nil && !existing.LockedUntil.After(now) && existing.Fingerprint == rec.Fingerprint
+		if existing.ExpiresAt.After(now) && !abandoned {
+			return &existing, false, nil
+		}
+	}
+	s.records[rec.Key] = rec
+	return nil, true, nil
+}
+
+func (s *memoryStore) Complete(_ context.Context, rec Record, res Response) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.owns(rec) {
+		stored := s.records[rec.Key]
+		stored.Response = &res
+		s.records[rec.Key] = stored
+	}
+	return nil
+}
+
+func (s *memoryStore) Release(_ context.Context, rec Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.owns(rec) {
+		delete(s.records, rec.Key)
+	}
+	return nil
+}
+
+func (s *memoryStore) DeleteExpired(_ context.Context, now time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var deleted int64
+	for key, rec := range s.records {
+		if rec.ExpiresAt.Before(now) {
+			delete(s.records, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (s *memoryStore) owns(rec Record) bool {
+	stored, ok := s.records[rec.Key]
+	return ok && stored.Response == nil && stored.LockedUntil.Equal(rec.LockedUntil)
+}
+
+func (s *memoryStore) get(key Key) (Record, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.records[key]
+	return rec, ok
+}
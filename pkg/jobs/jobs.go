@@ -0,0 +1,90 @@
+// Package jobs defines the background job abstractions shared by every
+// module. Modules enqueue jobs and register typed handlers and recurring
+// schedules; internal/jobqueue provides the Postgres-backed implementation.
+// Like pkg/events it has no framework dependencies so domain packages can use
+// it.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"english-learning/pkg/cron"
+	"fmt"
+	"time"
+)
+
+// Job is a unit of background work. It must marshal to JSON.
+type Job interface {
+	// JobKind identifies the handler, e.g. "session.purge_expired". Kinds
+	// are persisted, so never rename an existing one.
+	JobKind() string
+}
+
+// Options tune a single enqueue.
+type Options struct {
+	// RunAt is the earliest time the job may run. Zero means now.
+	RunAt time.Time
+	// MaxAttempts overrides the queue default when positive.
+	MaxAttempts int
+}
+
+// Option sets an enqueue option.
+type Option func(*Options)
+
+// At delays the job until t.
+func At(t time.Time) Option {
+	return func(o *Options) { o.RunAt = t }
+}
+
+// After delays the job by d.
+func After(d time.Duration) Option {
+	return func(o *Options) { o.RunAt = time.Now().Add(d) }
+}
+
+// MaxAttempts sets how many failed runs move the job to the dead-letter
+// state.
+func MaxAttempts(n int) Option {
+	return func(o *Options) { o.MaxAttempts = n }
+}
+
+// Enqueuer stores jobs for the workers. Called with a ctx inside a unit of
+// work, the job only becomes visible if that transaction commits.
+type Enqueuer interface {
+	Enqueue(ctx context.Context, job Job, opts ...Option) error
+}
+
+// Envelope is a claimed job as seen by handlers.
+type Envelope struct {
+	ID      uint64
+	Kind    string
+	Payload json.RawMessage
+	// Attempt is 1 on the first run and increases with every retry.
+	Attempt int
+}
+
+// Handler runs a job. Returning an error schedules a retry with exponential
+// backoff. A job may run more than once (e.g. after a worker crash), so
+// handlers must be idempotent.
+type Handler func(ctx context.Context, e Envelope) error
+
+// Registry is where modules register handlers and recurring schedules.
+type Registry interface {
+	Register(kind string, h Handler)
+	// Schedule enqueues job every time schedule fires. name identifies the
+	// schedule across instances and restarts; only one instance enqueues
+	// each occurrence.
+	Schedule(name string, schedule cron.Schedule, job Job)
+}
+
+// Handle registers a typed handler for T, decoding the payload before calling
+// h. T must be a non-pointer type whose JobKind has a value receiver.
+func Handle[T Job](r Registry, h func(ctx context.Context, job T) error) {
+	var zero T
+	r.Register(zero.JobKind(), func(ctx context.Context, e Envelope) error {
+		var job T
+		if err := json.Unmarshal(e.Payload, &job); err != nil {
+			return fmt.Errorf("decoding %s payload: %w", e.Kind, err)
+		}
+		return h(ctx, job)
+	})
+}
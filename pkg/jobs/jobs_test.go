@@ -0,0 +1,71 @@
+package jobs
+
+import (
+	"context"
+	"english-learning/pkg/cron"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sendEmail struct {
+	To string `json:"to"`
+}
+
+func (sendEmail) JobKind() string { return "test.send_email" }
+
+type fakeRegistry struct {
+	handlers map[string]Handler
+}
+
+func (r *fakeRegistry) Register(kind string, h Handler) {
+	r.handlers[kind] = h
+}
+
+func (r *fakeRegistry) Schedule(string, cron.Schedule, Job) {}
+
+func TestHandle_DecodesPayload(t *testing.T) {
+	t.Parallel()
+	r := &fakeRegistry{handlers: make(map[string]Handler)}
+
+	var got sendEmail
+	Handle(r, func(ctx context.Context, job sendEmail) error {
+		got = job
+		return nil
+	})
+
+	h, ok := r.handlers["test.send_email"]
+	require.True(t, ok)
+	require.NoError(t, h(context.Background(), Envelope{Kind: "test.send_email", Payload: []byte(`{"to":"a@example.com"}`)}))
+	assert.Equal(t, sendEmail{To: "a@example.com"}, got)
+}
+
+func TestHandle_InvalidPayload(t *testing.T) {
+	t.Parallel()
+	r := &fakeRegistry{handlers: make(map[string]Handler)}
+
+	called := false
+	Handle(r, func(ctx context.Context, job sendEmail) error {
+		called = true
+		return nil
+	})
+
+	err := r.handlers["test.send_email"](context.Background(), Envelope{Kind: "test.send_email", Payload: []byte(`[`)})
+
+	assert.Error(t, err)
+	assert.False(t, called)
+}
+
+func TestOptions(t *testing.T) {
+	t.Parallel()
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	var o Options
+	for _, opt := range []Option{At(at), MaxAttempts(3)} {
+		opt(&o)
+	}
+
+	assert.Equal(t, Options{RunAt: at, MaxAttempts: 3}, o)
+}
@@ -0,0 +1,83 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Hook is a pair of callbacks a module registers to take part in application
+// start-up and shutdown. Either callback may be nil.
+//
+// The ctx given to OnStart only scopes start-up itself and is not cancelled on
+// shutdown, so goroutines started by the hook must not rely on it to end.
+// They are ended by OnStop, whose ctx carries the shutdown deadline.
+type Hook struct {
+	Name    string
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+}
+
+// Lifecycle runs registered hooks in order on start and in reverse order on
+// stop, so a component is always stopped before the things it depends on.
+type Lifecycle struct {
+	mu      sync.Mutex
+	hooks   []Hook
+	started int
+}
+
+// New creates an empty Lifecycle.
+func New() *Lifecycle {
+	return &Lifecycle{}
+}
+
+// Append registers a hook. Hooks must be appended before Start is called.
+func (l *Lifecycle) Append(h Hook) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.hooks = append(l.hooks, h)
+}
+
+// Start runs every OnStart callback in registration order. If one fails, the
+// hooks that already started are stopped again, with the same ctx, before the
+// error is returned. Callers pass a ctx that stays alive for the whole run;
+// see Hook.
+func (l *Lifecycle) Start(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, h := range l.hooks[l.started:] {
+		if h.OnStart != nil {
+			if err := h.OnStart(ctx); err != nil {
+				startErr := fmt.Errorf("starting %s: %w", h.Name, err)
+				return errors.Join(startErr, l.stopLocked(ctx))
+			}
+		}
+		l.started++
+	}
+
+	return nil
+}
+
+// Stop runs the OnStop callback of every started hook in reverse order. All
+// hooks are given a chance to stop; their errors are joined.
+func (l *Lifecycle) Stop(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stopLocked(ctx)
+}
+
+func (l *Lifecycle) stopLocked(ctx context.Context) error {
+	var errs []error
+	for ; l.started > 0; l.started-- {
+		h := l.hooks[l.started-1]
+		if h.OnStop == nil {
+			continue
+		}
+		if err := h.OnStop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("stopping %s: %w", h.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
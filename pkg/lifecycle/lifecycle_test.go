@@ -0,0 +1,67 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func recorder(calls *[]string, name string, startErr error) Hook {
+	return Hook{
+		Name: name,
+		OnStart: func(ctx context.Context) error {
+			*calls = append(*calls, "start "+name)
+			return startErr
+		},
+		OnStop: func(ctx context.Context) error {
+			*calls = append(*calls, "stop "+name)
+			return nil
+		},
+	}
+}
+
+func TestLifecycle_StartAndStopOrder(t *testing.T) {
+	t.Parallel()
+	var calls []string
+	lc := New()
+	lc.Append(recorder(&calls, "db", nil))
+	lc.Append(recorder(&calls, "worker", nil))
+
+	assert.NoError(t, lc.Start(context.Background()))
+	assert.NoError(t, lc.Stop(context.Background()))
+
+	assert.Equal(t, []string{"start db", "start worker", "stop worker", "stop db"}, calls)
+}
+
+func TestLifecycle_StartFailureRollsBack(t *testing.T) {
+	t.Parallel()
+	var calls []string
+	lc := New()
+	lc.Append(recorder(&calls, "db", nil))
+	lc.Append(recorder(&calls, "worker", errors.New("boom")))
+	lc.Append(recorder(&calls, "scheduler", nil))
+
+	err := lc.Start(context.Background())
+
+	assert.ErrorContains(t, err, "starting worker: boom")
+	assert.Equal(t, []string{"start db", "start worker", "stop db"}, calls)
+
+	// A second Stop is a no-op once everything has been stopped.
+	assert.NoError(t, lc.Stop(context.Background()))
+	assert.Len(t, calls, 3)
+}
+
+func TestLifecycle_StopJoinsErrors(t *testing.T) {
+	t.Parallel()
+	lc := New()
+	lc.Append(Hook{Name: "a", OnStop: func(ctx context.Context) error { return errors.New("a failed") }})
+	lc.Append(Hook{Name: "b", OnStop: func(ctx context.Context) error { return errors.New("b failed") }})
+
+	assert.NoError(t, lc.Start(context.Background()))
+	err := lc.Stop(context.Background())
+
+	assert.ErrorContains(t, err, "stopping a: a failed")
+	assert.ErrorContains(t, err, "stopping b: b failed")
+}
@@ -0,0 +1,30 @@
+package logger
+
+import (
+	"context"
+	"english-learning/pkg/reqctx"
+
+	"go.uber.org/zap"
+)
+
+// ContextFields returns the request ID, user ID and trace fields carried by ctx.
+func ContextFields(ctx context.Context) []zap.Field {
+	if ctx == nil {
+		return nil
+	}
+
+	var fields []zap.Field
+	if id := reqctx.RequestID(ctx); id != "" {
+		fields = append(fields, zap.String("request_id", id))
+	}
+	if id, ok := reqctx.UserID(ctx); ok {
+		fields = append(fields, zap.Uint("user_id", id))
+	}
+	return append(fields, TraceFields(ctx)...)
+}
+
+// FromContext returns the global logger annotated with the fields carried by
+// ctx. Prefer it over Log for anything that runs on behalf of a request.
+func FromContext(ctx context.Context) *zap.Logger {
+	return Log.With(ContextFields(ctx)...)
+}
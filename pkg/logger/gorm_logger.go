@@ -3,23 +3,32 @@ package logger
 import (
 	"context"
 	"errors"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
 	gormlogger "gorm.io/gorm/logger"
 )
 
+// QueryObserver receives the timing of every SQL statement GORM executes.
+// operation is the upper-cased leading SQL keyword (SELECT, INSERT, ...).
+type QueryObserver interface {
+	ObserveQuery(operation string, elapsed time.Duration, err error)
+}
+
 // GormLogger is a custom GORM logger that uses zap
 type GormLogger struct {
 	ZapLogger     *zap.Logger
 	SlowThreshold time.Duration
+	Observer      QueryObserver
 }
 
-// NewGormLogger creates a new GormLogger
-func NewGormLogger(zapLogger *zap.Logger, slowThreshold time.Duration) gormlogger.Interface {
+// NewGormLogger creates a new GormLogger. observer may be nil.
+func NewGormLogger(zapLogger *zap.Logger, slowThreshold time.Duration, observer QueryObserver) gormlogger.Interface {
 	return &GormLogger{
 		ZapLogger:     zapLogger.Named("gorm"),
 		SlowThreshold: slowThreshold,
+		Observer:      observer,
 	}
 }
 
@@ -30,38 +39,55 @@ func (l *GormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
 
 // Info implements gormlogger.Interface
 func (l *GormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
-	l.ZapLogger.Sugar().Infof(msg, data...)
+	l.ZapLogger.With(ContextFields(ctx)...).Sugar().Infof(msg, data...)
 }
 
 // Warn implements gormlogger.Interface
 func (l *GormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
-	l.ZapLogger.Sugar().Warnf(msg, data...)
+	l.ZapLogger.With(ContextFields(ctx)...).Sugar().Warnf(msg, data...)
 }
 
 // Error implements gormlogger.Interface
 func (l *GormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
-	l.ZapLogger.Sugar().Errorf(msg, data...)
+	l.ZapLogger.With(ContextFields(ctx)...).Sugar().Errorf(msg, data...)
 }
 
 // Trace implements gormlogger.Interface
 func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
 	elapsed := time.Since(begin)
 	sql, rows := fc()
+
+	if l.Observer != nil {
+		var queryErr error
+		if err != nil && !errors.Is(err, gormlogger.ErrRecordNotFound) {
+			queryErr = err
+		}
+		l.Observer.ObserveQuery(sqlOperation(sql), elapsed, queryErr)
+	}
+
 	fields := []zap.Field{
 		zap.Duration("elapsed", elapsed),
 		zap.Int64("rows", rows),
 		zap.String("sql", sql),
 	}
+	log := l.ZapLogger.With(ContextFields(ctx)...)
 
 	if err != nil && !errors.Is(err, gormlogger.ErrRecordNotFound) {
-		l.ZapLogger.Error("SQL execution error", append(fields, zap.Error(err))...)
+		log.Error("SQL execution error", append(fields, zap.Error(err))...)
 		return
 	}
 
 	if l.SlowThreshold != 0 && elapsed > l.SlowThreshold {
-		l.ZapLogger.Warn("Slow SQL query detected", fields...)
+		log.Warn("Slow SQL query detected", fields...)
 		return
 	}
 
-	l.ZapLogger.Debug("SQL query executed", fields...)
+	log.Debug("SQL query executed", fields...)
+}
+
+// sqlOperation returns the leading keyword of a statement, keeping metric
+// labels low-cardinality.
+func sqlOperation(sql string) string {
+	op, _, _ := strings.Cut(strings.TrimSpace(sql), " ")
+	return strings.ToUpper(op)
 }
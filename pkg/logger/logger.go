@@ -7,7 +7,9 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
-var Log *zap.Logger
+// Log is a no-op logger until InitLogger is called, so packages that log can
+// be used from tests without initialising it.
+var Log = zap.NewNop()
 
 func InitLogger(env string) {
 	var config zap.Config
@@ -0,0 +1,53 @@
+// Package mergepatch implements JSON Merge Patch (RFC 7396): a patch is a
+// JSON document whose members replace those of the target, recursively for
+// objects, and whose null members remove them.
+package mergepatch
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// ContentType is the media type of merge patches.
+const ContentType = "application/merge-patch+json"
+
+// ErrNotObject is returned for patches that are not JSON objects. RFC 7396
+// allows them, replacing the whole target, but no resource here supports
+// that.
+var ErrNotObject = errors.New("merge patch must be a JSON object")
+
+// Apply returns doc with patch applied. doc must be a JSON object.
+func Apply(doc, patch []byte) ([]byte, error) {
+	var p interface{}
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return nil, err
+	}
+	if _, ok := p.(map[string]interface{}); !ok {
+		return nil, ErrNotObject
+	}
+
+	var target interface{}
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, err
+	}
+	return json.Marshal(merge(target, p))
+}
+
+func merge(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = make(map[string]interface{})
+	}
+	for key, value := range p {
+		if value == nil {
+			delete(t, key)
+			continue
+		}
+		t[key] = merge(t[key], value)
+	}
+	return t
+}
@@ -0,0 +1,43 @@
+package mergepatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The examples of RFC 7396, appendix A, that patch an object.
+func TestApply_RFCExamples(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		doc, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tt := range tests {
+		got, err := Apply([]byte(tt.doc), []byte(tt.patch))
+		require.NoError(t, err)
+		assert.JSONEqf(t, tt.want, string(got), "%s + %s", tt.doc, tt.patch)
+	}
+}
+
+func TestApply_RejectsNonObjectPatch(t *testing.T) {
+	t.Parallel()
+	for _, patch := range []string{`["a"]`, `"a"`, `null`} {
+		_, err := Apply([]byte(`{"a":"b"}`), []byte(patch))
+		assert.ErrorIsf(t, err, ErrNotObject, "patch %s", patch)
+	}
+
+	_, err := Apply([]byte(`{}`), []byte(`{"a":`))
+	assert.Error(t, err)
+}
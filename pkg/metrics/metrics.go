@@ -0,0 +1,158 @@
+package metrics
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+const namespace = "english_learning"
+
+// Metrics owns the Prometheus registry and every collector the application
+// exports. All methods are safe to call on a nil *Metrics, which is what
+// callers get when metrics are disabled in config.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+	dbQueries    *prometheus.HistogramVec
+	dbErrors     *prometheus.CounterVec
+	authEvents   *prometheus.CounterVec
+	cacheLookups *prometheus.CounterVec
+}
+
+// New creates a Metrics instance with Go runtime and process collectors
+// registered on a private registry.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests processed, by route template and status code.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency, by route template and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		dbQueries: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "SQL statement latency, by statement type.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"operation"}),
+		dbErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "db_query_errors_total",
+			Help:      "SQL statements that returned an error, by statement type.",
+		}, []string{"operation"}),
+		authEvents: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "auth_events_total",
+			Help:      "Authentication events such as logins, failed logins, registrations and token refreshes.",
+		}, []string{"event"}),
+		cacheLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cache_lookups_total",
+			Help:      "Cache lookups, by cache and result (hit or miss).",
+		}, []string{"cache", "result"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.dbQueries,
+		m.dbErrors,
+		m.authEvents,
+		m.cacheLookups,
+	)
+
+	return m
+}
+
+// Registry exposes the underlying registry so modules can register their own
+// collectors.
+func (m *Metrics) Registry() *prometheus.Registry {
+	if m == nil {
+		return nil
+	}
+	return m.registry
+}
+
+// RegisterDBStats exports connection pool statistics of db.
+func (m *Metrics) RegisterDBStats(db *sql.DB, dbName string) {
+	if m == nil {
+		return
+	}
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, dbName))
+}
+
+// ObserveHTTPRequest records a finished HTTP request.
+func (m *Metrics) ObserveHTTPRequest(method, route, status string, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+	m.httpRequests.WithLabelValues(method, route, status).Inc()
+	m.httpDuration.WithLabelValues(method, route, status).Observe(elapsed.Seconds())
+}
+
+// ObserveQuery implements logger.QueryObserver.
+func (m *Metrics) ObserveQuery(operation string, elapsed time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	m.dbQueries.WithLabelValues(operation).Observe(elapsed.Seconds())
+	if err != nil {
+		m.dbErrors.WithLabelValues(operation).Inc()
+	}
+}
+
+// Auth event label values.
+const (
+	EventLogin        = "login"
+	EventLoginFailed  = "login_failed"
+	EventRegistration = "registration"
+	EventTokenRefresh = "token_refresh"
+)
+
+func (m *Metrics) authEvent(event string) {
+	if m == nil {
+		return
+	}
+	m.authEvents.WithLabelValues(event).Inc()
+}
+
+// LoginSucceeded implements authDomain.Metrics.
+func (m *Metrics) LoginSucceeded() { m.authEvent(EventLogin) }
+
+// LoginFailed implements authDomain.Metrics.
+func (m *Metrics) LoginFailed() { m.authEvent(EventLoginFailed) }
+
+// Registered implements authDomain.Metrics.
+func (m *Metrics) Registered() { m.authEvent(EventRegistration) }
+
+// TokenRefreshed implements authDomain.Metrics.
+func (m *Metrics) TokenRefreshed() { m.authEvent(EventTokenRefresh) }
+
+// CacheHit records a lookup served from the named cache.
+func (m *Metrics) CacheHit(cache string) {
+	if m == nil {
+		return
+	}
+	m.cacheLookups.WithLabelValues(cache, "hit").Inc()
+}
+
+// CacheMiss records a lookup the named cache could not serve.
+func (m *Metrics) CacheMiss(cache string) {
+	if m == nil {
+		return
+	}
+	m.cacheLookups.WithLabelValues(cache, "miss").Inc()
+}
@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func TestMiddleware_LabelsByRouteTemplate(t *testing.T) {
+	t.Parallel()
+	m := New()
+	r := gin.New()
+	r.Use(m.Middleware())
+	r.GET("/users/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, path := range []string{"/users/1", "/users/2", "/nope"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.httpRequests.WithLabelValues("GET", "/users/:id", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.httpRequests.WithLabelValues("GET", unmatchedRoute, "404")))
+}
+
+func TestObserveQuery_CountsErrors(t *testing.T) {
+	t.Parallel()
+	m := New()
+
+	m.ObserveQuery("SELECT", time.Millisecond, nil)
+	m.ObserveQuery("INSERT", time.Millisecond, errors.New("duplicate key"))
+
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.dbErrors.WithLabelValues("SELECT")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.dbErrors.WithLabelValues("INSERT")))
+}
+
+func TestHandler_RequiresToken(t *testing.T) {
+	t.Parallel()
+	m := New()
+	m.LoginFailed()
+	r := gin.New()
+	r.GET("/metrics", m.Handler("secret"))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, strings.Contains(w.Body.String(), `english_learning_auth_events_total{event="login_failed"} 1`))
+}
+
+func TestNilMetrics_IsNoop(t *testing.T) {
+	t.Parallel()
+	var m *Metrics
+
+	assert.NotPanics(t, func() {
+		m.LoginSucceeded()
+		m.ObserveQuery("SELECT", time.Millisecond, nil)
+		m.ObserveHTTPRequest("GET", "/", "200", time.Millisecond)
+		m.CacheHit("users")
+	})
+}
+
+func TestCacheLookups(t *testing.T) {
+	t.Parallel()
+	m := New()
+
+	m.CacheHit("users")
+	m.CacheHit("users")
+	m.CacheMiss("users")
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.cacheLookups.WithLabelValues("users", "hit")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.cacheLookups.WithLabelValues("users", "miss")))
+}
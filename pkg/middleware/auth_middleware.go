@@ -2,45 +2,71 @@ package middleware
 
 import (
 	"english-learning/configs"
+	"english-learning/pkg/i18n"
+	"english-learning/pkg/reqctx"
+	"english-learning/pkg/response"
+	"english-learning/pkg/token"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// AuthMiddleware authenticates requests carrying an HS256-signed access
+// token in "Authorization: Bearer <token>".
 func AuthMiddleware(cfg configs.JWTConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+			abortUnauthorized(c, response.MsgAuthHeaderRequired)
 			return
 		}
 
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
+			abortUnauthorized(c, response.MsgInvalidAuthHeader)
 			return
 		}
 
 		tokenString := parts[1]
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		parsed, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 			return []byte(cfg.Secret), nil
-		})
+		}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 
-		if err != nil || !token.Valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		if err != nil || !parsed.Valid {
+			abortUnauthorized(c, response.MsgInvalidToken)
 			return
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+		claims, ok := parsed.Claims.(jwt.MapClaims)
+		if !ok || claims["typ"] != token.TypeAccess {
+			abortUnauthorized(c, response.MsgInvalidToken)
 			return
 		}
 
-		c.Set("user_id", claims["user_id"])
+		// The user ID is carried in the standard "sub" claim.
+		sub, _ := claims.GetSubject()
+		userID, err := strconv.ParseUint(sub, 10, 64)
+		if err != nil {
+			abortUnauthorized(c, response.MsgInvalidToken)
+			return
+		}
+
+		c.Set("user_id", uint(userID))
 		c.Set("email", claims["email"])
+		role, _ := claims["role"].(string)
+		c.Set("role", role)
+		c.Request = c.Request.WithContext(reqctx.WithUserID(c.Request.Context(), uint(userID)))
+		if locale, _ := claims["locale"].(string); i18n.IsSupported(locale) {
+			setLocale(c, locale)
+		}
 		c.Next()
 	}
 }
+
+func abortUnauthorized(c *gin.Context, message string) {
+	response.Error(c, http.StatusUnauthorized, response.CodeUnauthorized, message)
+	c.Abort()
+}
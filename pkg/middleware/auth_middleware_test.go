@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"english-learning/configs"
+	"english-learning/pkg/token"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "test-secret-at-least-32-characters-long"
+
+func signToken(t *testing.T, method jwt.SigningMethod, key interface{}, claims jwt.MapClaims) string {
+	t.Helper()
+	signed, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	t.Parallel()
+	exp := time.Now().Add(time.Hour).Unix()
+	claims := func(typ string) jwt.MapClaims {
+		return jwt.MapClaims{"sub": "7", "email": "lan@example.com", "role": "student", "typ": typ, "exp": exp}
+	}
+
+	tests := []struct {
+		name     string
+		header   string
+		wantCode int
+	}{
+		{"access token", "Bearer " + signToken(t, jwt.SigningMethodHS256, []byte(testSecret), claims(token.TypeAccess)), http.StatusOK},
+		{"refresh token", "Bearer " + signToken(t, jwt.SigningMethodHS256, []byte(testSecret), claims(token.TypeRefresh)), http.StatusUnauthorized},
+		{"untyped token", "Bearer " + signToken(t, jwt.SigningMethodHS256, []byte(testSecret), jwt.MapClaims{"sub": "7", "exp": exp}), http.StatusUnauthorized},
+		{"other HMAC algorithm", "Bearer " + signToken(t, jwt.SigningMethodHS512, []byte(testSecret), claims(token.TypeAccess)), http.StatusUnauthorized},
+		{"unsigned token", "Bearer " + signToken(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, claims(token.TypeAccess)), http.StatusUnauthorized},
+		{"wrong secret", "Bearer " + signToken(t, jwt.SigningMethodHS256, []byte("another-secret"), claims(token.TypeAccess)), http.StatusUnauthorized},
+		{"missing header", "", http.StatusUnauthorized},
+		{"not a bearer token", "Basic Zm9vOmJhcg==", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r := gin.New()
+			r.Use(AuthMiddleware(configs.JWTConfig{Secret: testSecret}))
+			r.GET("/", func(c *gin.Context) {
+				assert.Equal(t, uint(7), c.GetUint("user_id"))
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
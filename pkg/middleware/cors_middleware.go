@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"english-learning/configs"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// corsAllowedMethods are the methods browsers may use cross-origin.
+var corsAllowedMethods = strings.Join([]string{
+	http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+}, ", ")
+
+// corsAllowedHeaders are the request headers the API reads.
+var corsAllowedHeaders = strings.Join([]string{
+	"Authorization", "Content-Type", "Accept-Language",
+	"If-Match", "If-None-Match", "Idempotency-Key", "X-App-Version", RequestIDHeader,
+}, ", ")
+
+// corsExposedHeaders are the response headers scripts on other origins may
+// read.
+var corsExposedHeaders = strings.Join([]string{
+	"ETag", "Location", "Retry-After", "Idempotent-Replayed", RequestIDHeader,
+	"Deprecation", "Sunset", "Link",
+}, ", ")
+
+// CORSMiddleware lets browsers on cfg.AllowedOrigins call the API. Preflight
+// requests are answered here, before routing; requests from other origins get
+// no CORS headers, so browsers refuse to expose the response. Server-to-server
+// calls carry no Origin and are unaffected.
+func CORSMiddleware(cfg configs.CORSConfig) gin.HandlerFunc {
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" || len(cfg.AllowedOrigins) == 0 {
+			c.Next()
+			return
+		}
+
+		// The response depends on the Origin, so caches must key on it.
+		c.Writer.Header().Add("Vary", "Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+		allowed := originAllowed(cfg.AllowedOrigins, origin)
+		if !allowed {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		c.Header("Access-Control-Allow-Origin", origin)
+		if cfg.AllowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+		if !preflight {
+			c.Header("Access-Control-Expose-Headers", corsExposedHeaders)
+			c.Next()
+			return
+		}
+
+		c.Header("Access-Control-Allow-Methods", corsAllowedMethods)
+		c.Header("Access-Control-Allow-Headers", corsAllowedHeaders)
+		c.Header("Access-Control-Max-Age", maxAge)
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+// originAllowed matches origin against the allow-list: exactly, by "*", or by
+// a "scheme://*.domain" pattern that matches subdomains of domain only.
+func originAllowed(allowed []string, origin string) bool {
+	for _, pattern := range allowed {
+		if pattern == "*" || strings.EqualFold(pattern, origin) {
+			return true
+		}
+		scheme, domain, ok := strings.Cut(pattern, "://*.")
+		if !ok {
+			continue
+		}
+		rest, ok := strings.CutPrefix(strings.ToLower(origin), strings.ToLower(scheme)+"://")
+		if ok && strings.HasSuffix(rest, "."+strings.ToLower(domain)) && !strings.ContainsAny(rest, "/@") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"english-learning/configs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func corsRouter(cfg configs.CORSConfig) *gin.Engine {
+	r := gin.New()
+	r.Use(CORSMiddleware(cfg))
+	r.GET("/users", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return r
+}
+
+func corsRequest(r http.Handler, method, origin string, preflight bool) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/users", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	if preflight {
+		req.Header.Set("Access-Control-Request-Method", http.MethodPatch)
+		req.Header.Set("Access-Control-Request-Headers", "if-match, content-type")
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+var appCORS = configs.CORSConfig{
+	AllowedOrigins: []string{"https://app.example.com", "https://*.preview.example.com"},
+	MaxAge:         10 * time.Minute,
+}
+
+func TestCORSMiddleware_Preflight(t *testing.T) {
+	t.Parallel()
+	w := corsRequest(corsRouter(appCORS), http.MethodOptions, "https://app.example.com", true)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, w.Header().Get("Access-Control-Allow-Methods"), http.MethodPatch)
+	for _, header := range []string{"If-Match", "If-None-Match", "Idempotency-Key", "Authorization"} {
+		assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), header)
+	}
+	assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "Origin", w.Header().Get("Vary"))
+}
+
+func TestCORSMiddleware_SimpleRequestExposesHeaders(t *testing.T) {
+	t.Parallel()
+	w := corsRequest(corsRouter(appCORS), http.MethodGet, "https://pr-12.preview.example.com", false)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "https://pr-12.preview.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, w.Header().Get("Access-Control-Expose-Headers"), "ETag")
+	assert.Contains(t, w.Header().Get("Access-Control-Expose-Headers"), "Idempotent-Replayed")
+}
+
+func TestCORSMiddleware_OtherOrigins(t *testing.T) {
+	t.Parallel()
+	r := corsRouter(appCORS)
+	for _, origin := range []string{
+		"https://evil.example.com",
+		"http://app.example.com",
+		"https://preview.example.com",
+		"https://evilpreview.example.com",
+	} {
+		w := corsRequest(r, http.MethodOptions, origin, true)
+		assert.Equalf(t, http.StatusForbidden, w.Code, "preflight from %s", origin)
+		assert.Emptyf(t, w.Header().Get("Access-Control-Allow-Origin"), "preflight from %s", origin)
+
+		w = corsRequest(r, http.MethodGet, origin, false)
+		assert.Equalf(t, http.StatusOK, w.Code, "request from %s", origin)
+		assert.Emptyf(t, w.Header().Get("Access-Control-Allow-Origin"), "request from %s", origin)
+	}
+}
+
+func TestCORSMiddleware_WithoutOriginOrPolicy(t *testing.T) {
+	t.Parallel()
+	w := corsRequest(corsRouter(appCORS), http.MethodGet, "", false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Vary"))
+
+	w = corsRequest(corsRouter(configs.CORSConfig{}), http.MethodOptions, "https://app.example.com", true)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"), "CORS is off without allowed origins")
+}
+
+func TestCORSMiddleware_AnyOriginWithCredentials(t *testing.T) {
+	t.Parallel()
+	r := corsRouter(configs.CORSConfig{AllowedOrigins: []string{"*"}})
+	w := corsRequest(r, http.MethodGet, "https://anything.test", false)
+	assert.Equal(t, "https://anything.test", w.Header().Get("Access-Control-Allow-Origin"))
+
+	r = corsRouter(configs.CORSConfig{AllowedOrigins: []string{"https://app.example.com"}, AllowCredentials: true})
+	w = corsRequest(r, http.MethodGet, "https://app.example.com", false)
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+}
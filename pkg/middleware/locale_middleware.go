@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"english-learning/pkg/i18n"
+	"english-learning/pkg/reqctx"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LocaleMiddleware negotiates the response locale from Accept-Language and
+// stores it in the request context. AuthMiddleware later overrides it with
+// the authenticated user's saved preference, if they have one.
+func LocaleMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		setLocale(c, i18n.Negotiate(c.GetHeader("Accept-Language")))
+		c.Next()
+	}
+}
+
+func setLocale(c *gin.Context, locale string) {
+	c.Request = c.Request.WithContext(reqctx.WithLocale(c.Request.Context(), locale))
+	c.Header("Content-Language", locale)
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"encoding/json"
+	"english-learning/pkg/response"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocaleMiddleware_LocalizesMessageButNotCode(t *testing.T) {
+	t.Parallel()
+	r := gin.New()
+	r.Use(LocaleMiddleware())
+	r.GET("/", func(c *gin.Context) {
+		response.Error(c, http.StatusNotFound, response.CodeUserNotFound, response.MsgUserNotFound)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Language", "vi-VN,vi;q=0.9,en;q=0.8")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var body response.APIResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "vi", w.Header().Get("Content-Language"))
+	assert.Equal(t, response.CodeUserNotFound, body.Code)
+	assert.Equal(t, "Không tìm thấy người dùng", body.Message)
+}
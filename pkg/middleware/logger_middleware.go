@@ -31,20 +31,21 @@ func LoggerMiddleware() gin.HandlerFunc {
 			zap.String("user-agent", c.Request.UserAgent()),
 			zap.Duration("latency", latency),
 		}
+		log := logger.FromContext(c.Request.Context()).Named("middleware")
 
 		if len(c.Errors) > 0 {
 			// Log errors if any
 			for _, e := range c.Errors.Errors() {
-				logger.Log.Named("middleware").Error(e, fields...)
+				log.Error(e, fields...)
 			}
 		} else {
 			// Log success/redirection/client error
 			if status >= 500 {
-				logger.Log.Named("middleware").Error("Internal Server Error", fields...)
+				log.Error("Internal Server Error", fields...)
 			} else if status >= 400 {
-				logger.Log.Named("middleware").Warn("Client Error", fields...)
+				log.Warn("Client Error", fields...)
 			} else {
-				logger.Log.Named("middleware").Info("Request Processed", fields...)
+				log.Info("Request Processed", fields...)
 			}
 		}
 	}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"encoding/json"
+	"english-learning/pkg/reqctx"
+	"english-learning/pkg/response"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{name: "generates when missing", incoming: "", keep: false},
+		{name: "keeps valid client id", incoming: "mobile-7f3a.1", keep: true},
+		{name: "replaces id with unsafe characters", incoming: "abc\r\nX-Injected: 1", keep: false},
+		{name: "replaces overly long id", incoming: strings.Repeat("a", maxRequestIDLength+1), keep: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var seen string
+			r := gin.New()
+			r.Use(RequestIDMiddleware())
+			r.GET("/", func(c *gin.Context) {
+				seen = reqctx.RequestID(c.Request.Context())
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(RequestIDHeader, tt.incoming)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.NotEmpty(t, seen)
+			assert.Equal(t, seen, w.Header().Get(RequestIDHeader))
+			if tt.keep {
+				assert.Equal(t, tt.incoming, seen)
+			} else {
+				assert.NotEqual(t, tt.incoming, seen)
+			}
+		})
+	}
+}
+
+func TestRequestIDMiddleware_IncludedInErrorBody(t *testing.T) {
+	t.Parallel()
+	r := gin.New()
+	r.Use(RequestIDMiddleware())
+	r.GET("/", func(c *gin.Context) {
+		response.Error(c, http.StatusBadRequest, response.CodeBadRequest, "bad")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(RequestIDHeader, "req-123")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var body response.APIResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "req-123", body.RequestID)
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"english-learning/pkg/response"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole aborts with 403 unless the authenticated user holds one of the
+// given roles. It must run after AuthMiddleware, which sets "role" from the
+// access token.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !slices.Contains(roles, c.GetString("role")) {
+			response.Error(c, http.StatusForbidden, response.CodeForbidden, response.MsgForbidden)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireSelfOrRole aborts with 403 unless the path parameter param is the
+// authenticated user's own ID or the user holds one of the given roles, for
+// routes where users manage their own resource and admins everyone's. It
+// must run after AuthMiddleware, which sets "user_id" and "role".
+func RequireSelfOrRole(param string, roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		self := c.Param(param) == strconv.FormatUint(uint64(c.GetUint("user_id")), 10)
+		if !self && !slices.Contains(roles, c.GetString("role")) {
+			response.Error(c, http.StatusForbidden, response.CodeForbidden, response.MsgForbidden)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
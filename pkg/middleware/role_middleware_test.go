@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireRole(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		role     string
+		wantCode int
+	}{
+		{"allowed role", "admin", http.StatusOK},
+		{"other role", "student", http.StatusForbidden},
+		{"no role", "", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r := gin.New()
+			r.Use(func(c *gin.Context) {
+				c.Set("role", tt.role)
+			})
+			r.Use(RequireRole("admin"))
+			r.GET("/", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			assert.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
+
+func TestRequireSelfOrRole(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		path     string
+		role     string
+		wantCode int
+	}{
+		{"own resource", "/users/7", "student", http.StatusOK},
+		{"other user's resource", "/users/8", "student", http.StatusForbidden},
+		{"not a number", "/users/7a", "student", http.StatusForbidden},
+		{"allowed role", "/users/8", "admin", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r := gin.New()
+			r.Use(func(c *gin.Context) {
+				c.Set("user_id", uint(7))
+				c.Set("role", tt.role)
+			})
+			r.GET("/users/:id", RequireSelfOrRole("id", "admin"), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
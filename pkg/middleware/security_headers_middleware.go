@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"english-learning/configs"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiContentSecurityPolicy forbids everything: API responses are data, never
+// documents to render. Handlers serving HTML set their own policy.
+const apiContentSecurityPolicy = "default-src 'none'; frame-ancestors 'none'"
+
+// SecurityHeadersMiddleware sets the response headers that keep browsers
+// from sniffing, framing or rendering API responses, and Strict-Transport-
+// Security when cfg.HSTSMaxAge is set.
+func SecurityHeadersMiddleware(cfg configs.SecurityConfig) gin.HandlerFunc {
+	hsts := ""
+	if cfg.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(int(cfg.HSTSMaxAge.Seconds()))
+	}
+	return func(c *gin.Context) {
+		h := c.Writer.Header()
+		h.Set("X-Content-Type-Options", "nosniff")
+		h.Set("X-Frame-Options", "DENY")
+		h.Set("Referrer-Policy", "no-referrer")
+		h.Set("Content-Security-Policy", apiContentSecurityPolicy)
+		if hsts != "" {
+			h.Set("Strict-Transport-Security", hsts)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"english-learning/configs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurityHeadersMiddleware(t *testing.T) {
+	t.Parallel()
+	for _, tt := range []struct {
+		maxAge time.Duration
+		hsts   string
+	}{
+		{0, ""},
+		{365 * 24 * time.Hour, "max-age=31536000"},
+	} {
+		r := gin.New()
+		r.Use(SecurityHeadersMiddleware(configs.SecurityConfig{HSTSMaxAge: tt.maxAge}))
+		r.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+		assert.Equal(t, "DENY", w.Header().Get("X-Frame-Options"))
+		assert.Equal(t, "no-referrer", w.Header().Get("Referrer-Policy"))
+		assert.Equal(t, "default-src 'none'; frame-ancestors 'none'", w.Header().Get("Content-Security-Policy"))
+		assert.Equal(t, tt.hsts, w.Header().Get("Strict-Transport-Security"))
+	}
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"context"
+	"english-learning/pkg/response"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TimeoutMiddleware attaches a deadline to the request context so that services
+// and GORM queries started with c.Request.Context() are cancelled once it
+// expires. If the handler gave up without writing a response, a 504 is sent.
+// A non-positive timeout disables the middleware.
+func TimeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+			response.Error(c, http.StatusGatewayTimeout, response.CodeRequestTimeout, response.MsgRequestTimeout)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func TestTimeoutMiddleware_SetsDeadline(t *testing.T) {
+	t.Parallel()
+	r := gin.New()
+	r.Use(TimeoutMiddleware(time.Second))
+	r.GET("/", func(c *gin.Context) {
+		_, ok := c.Request.Context().Deadline()
+		assert.True(t, ok)
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestTimeoutMiddleware_RespondsWhenHandlerGivesUp(t *testing.T) {
+	t.Parallel()
+	r := gin.New()
+	r.Use(TimeoutMiddleware(10 * time.Millisecond))
+	r.GET("/", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+}
+
+func TestTimeoutMiddleware_Disabled(t *testing.T) {
+	t.Parallel()
+	r := gin.New()
+	r.Use(TimeoutMiddleware(0))
+	r.GET("/", func(c *gin.Context) {
+		_, ok := c.Request.Context().Deadline()
+		assert.False(t, ok)
+		c.Status(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
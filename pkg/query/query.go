@@ -0,0 +1,411 @@
+// Package query parses list requests into a storage-independent Query:
+// filters and a sort order over an allow-list of fields, a page size, and a
+// keyset cursor marking where the previous page ended.
+//
+// A list endpoint accepts
+//
+//	limit=20                      page size, at most Spec.MaxLimit
+//	cursor=<nextCursor>           continue after the previous page
+//	sort=-createdAt               sort field, "-" for descending
+//	total=true                    also count every match (slower)
+//	role=admin                    filter: field equals value
+//	email[contains]=lan           filter: field <op> value
+//	createdAt[gte]=2024-01-01     (ops: eq, contains, gt, gte, lt, lte)
+//
+// Every page is ordered by the sort field and then by ID, so the cursor, the
+// last row's (value, ID) pair, identifies a position even among equal values.
+// Unlike OFFSET, seeking past it costs the same on every page, and rows
+// inserted meanwhile never shift a page.
+package query
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Type is the type of a field's values.
+type Type int
+
+const (
+	String Type = iota
+	Int
+	Time
+)
+
+// Op is a filter operator.
+type Op string
+
+const (
+	Eq       Op = "eq"
+	Contains Op = "contains" // case-insensitive substring, strings only
+	Gt       Op = "gt"
+	Gte      Op = "gte"
+	Lt       Op = "lt"
+	Lte      Op = "lte"
+)
+
+// Field is a field clients may filter or sort by. Sortable fields must never
+// be null.
+type Field struct {
+	// Name is the field's name in the API, e.g. "createdAt".
+	Name     string
+	Type     Type
+	Ops      []Op
+	Sortable bool
+}
+
+// Spec is the allow-list of one list endpoint.
+type Spec struct {
+	Fields []Field
+	// DefaultSort is used when the request has no sort, e.g. "-createdAt".
+	DefaultSort  string
+	DefaultLimit int
+	MaxLimit     int
+}
+
+// Filter restricts a listing to items whose Field compares to Value with Op.
+// Value is a string, int64 or time.Time according to the field's Type.
+type Filter struct {
+	Field Field
+	Op    Op
+	Value interface{}
+}
+
+// Sort orders a listing by Field, then by ID in the same direction.
+type Sort struct {
+	Field Field
+	Desc  bool
+}
+
+func (s Sort) String() string {
+	if s.Desc {
+		return "-" + s.Field.Name
+	}
+	return s.Field.Name
+}
+
+// Cursor is the position of the last item of a page: its sort value and ID.
+type Cursor struct {
+	Value interface{}
+	ID    uint64
+}
+
+// Query is a parsed list request.
+type Query struct {
+	Filters []Filter
+	Sort    Sort
+	Limit   int
+	// After, when set, restricts the listing to items after this position.
+	After *Cursor
+	// WithTotal asks for the number of items matching the filters.
+	WithTotal bool
+	// Offset skips this many items before the page. Only the legacy
+	// page/page_size parameters set it (see Spec.ParseOffset); it is never
+	// combined with After.
+	Offset int
+}
+
+// Page is one page of a listing.
+type Page[T any] struct {
+	Items []T
+	// NextCursor continues the listing; it is empty on the last page.
+	NextCursor string
+	// Total is set when the query asked for it.
+	Total *int64
+}
+
+// Error describes why a query parameter was rejected. Tag identifies the
+// problem for clients and translations; Param is the offending parameter.
+type Error struct {
+	Param string
+	Tag   string
+	// Detail is the allowed range or values, when that helps.
+	Detail string
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("query parameter %q: %s", e.Param, e.Tag)
+	if e.Detail != "" {
+		msg += " (" + e.Detail + ")"
+	}
+	return msg
+}
+
+// Error tags.
+const (
+	TagUnknown  = "unknown"  // no such parameter or field
+	TagOperator = "operator" // operator not allowed for the field
+	TagValue    = "value"    // value does not parse as the field's type
+	TagSort     = "sort"     // field cannot be sorted by
+	TagLimit    = "limit"    // limit out of range
+	TagCursor   = "cursor"   // cursor malformed or from another sort order
+	TagBool     = "bool"     // value must be true or false
+)
+
+var filterParam = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9]*)(?:\[([a-z]+)\])?$`)
+
+func (s Spec) field(name string) (Field, bool) {
+	for _, f := range s.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+// Parse builds a Query from request parameters. Unknown parameters are
+// rejected rather than ignored, so a typo never silently returns everything.
+func (s Spec) Parse(values url.Values) (Query, error) {
+	q := Query{Limit: s.DefaultLimit}
+
+	sortSpec := s.DefaultSort
+	if v := values.Get("sort"); v != "" {
+		sortSpec = v
+	}
+	order, err := s.parseSort(sortSpec)
+	if err != nil {
+		return Query{}, err
+	}
+	q.Sort = order
+
+	// Walk parameters in order so filters and errors are deterministic.
+	params := make([]string, 0, len(values))
+	for param := range values {
+		params = append(params, param)
+	}
+	sort.Strings(params)
+
+	for _, param := range params {
+		v := values[param][len(values[param])-1]
+		switch param {
+		case "sort":
+		case "limit":
+			limit, err := strconv.Atoi(v)
+			if err != nil || limit < 1 || limit > s.MaxLimit {
+				return Query{}, &Error{Param: param, Tag: TagLimit, Detail: fmt.Sprintf("1-%d", s.MaxLimit)}
+			}
+			q.Limit = limit
+		case "total":
+			total, err := strconv.ParseBool(v)
+			if err != nil {
+				return Query{}, &Error{Param: param, Tag: TagBool}
+			}
+			q.WithTotal = total
+		case "cursor":
+			after, err := decodeCursor(v, order)
+			if err != nil {
+				return Query{}, &Error{Param: param, Tag: TagCursor}
+			}
+			q.After = &after
+		default:
+			filter, err := s.parseFilter(param, v)
+			if err != nil {
+				return Query{}, err
+			}
+			q.Filters = append(q.Filters, filter)
+		}
+	}
+	return q, nil
+}
+
+// Legacy offset pagination parameters, from before cursors.
+const (
+	pageParam     = "page"
+	pageSizeParam = "page_size"
+)
+
+// HasPageParams reports whether values use the legacy page/page_size
+// parameters.
+func HasPageParams(values url.Values) bool {
+	return values.Has(pageParam) || values.Has(pageSizeParam)
+}
+
+// ParsePage parses the legacy page and page_size parameters. As before
+// cursors, a missing or invalid page means the first one and a missing or
+// invalid page size means defaultSize, but a page size above maxSize is
+// rejected rather than silently reduced.
+func ParsePage(values url.Values, defaultSize, maxSize int) (page, size int, err error) {
+	page, _ = strconv.Atoi(values.Get(pageParam))
+	if page < 1 {
+		page = 1
+	}
+	size, _ = strconv.Atoi(values.Get(pageSizeParam))
+	if size < 1 {
+		size = defaultSize
+	}
+	if size > maxSize {
+		return 0, 0, &Error{Param: pageSizeParam, Tag: TagLimit, Detail: fmt.Sprintf("1-%d", maxSize)}
+	}
+	return page, size, nil
+}
+
+// ParseOffset builds a Query from a request paginated with the legacy page
+// and page_size parameters instead of a cursor; the other parameters are
+// those of Parse. The Query always counts the total, which offset pages
+// report. defaultSize is the page size when page_size is missing.
+func (s Spec) ParseOffset(values url.Values, defaultSize int) (q Query, page, size int, err error) {
+	page, size, err = ParsePage(values, defaultSize, s.MaxLimit)
+	if err != nil {
+		return Query{}, 0, 0, err
+	}
+
+	rest := url.Values{}
+	for param, v := range values {
+		switch param {
+		case pageParam, pageSizeParam:
+		case "cursor", "limit":
+			// Offset and keyset pagination do not mix.
+			return Query{}, 0, 0, &Error{Param: param, Tag: TagUnknown}
+		default:
+			rest[param] = v
+		}
+	}
+	q, err = s.Parse(rest)
+	if err != nil {
+		return Query{}, 0, 0, err
+	}
+	q.Limit = size
+	q.Offset = (page - 1) * size
+	q.WithTotal = true
+	return q, page, size, nil
+}
+
+func (s Spec) parseSort(spec string) (Sort, error) {
+	name := strings.TrimPrefix(spec, "-")
+	f, ok := s.field(name)
+	if !ok || !f.Sortable {
+		return Sort{}, &Error{Param: "sort", Tag: TagSort, Detail: s.sortable()}
+	}
+	return Sort{Field: f, Desc: strings.HasPrefix(spec, "-")}, nil
+}
+
+func (s Spec) sortable() string {
+	var names []string
+	for _, f := range s.Fields {
+		if f.Sortable {
+			names = append(names, f.Name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+func (s Spec) parseFilter(param, raw string) (Filter, error) {
+	m := filterParam.FindStringSubmatch(param)
+	if m == nil {
+		return Filter{}, &Error{Param: param, Tag: TagUnknown}
+	}
+	f, ok := s.field(m[1])
+	if !ok || len(f.Ops) == 0 {
+		return Filter{}, &Error{Param: param, Tag: TagUnknown}
+	}
+
+	op := Eq
+	if m[2] != "" {
+		op = Op(m[2])
+	}
+	if !f.allows(op) {
+		return Filter{}, &Error{Param: param, Tag: TagOperator, Detail: f.ops()}
+	}
+
+	value, err := parseValue(f.Type, raw)
+	if err != nil {
+		return Filter{}, &Error{Param: param, Tag: TagValue}
+	}
+	return Filter{Field: f, Op: op, Value: value}, nil
+}
+
+func (f Field) allows(op Op) bool {
+	for _, allowed := range f.Ops {
+		if allowed == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (f Field) ops() string {
+	names := make([]string, len(f.Ops))
+	for i, op := range f.Ops {
+		names[i] = string(op)
+	}
+	return strings.Join(names, ", ")
+}
+
+// parseValue parses a filter or cursor value. Times are RFC 3339 or, for
+// filters, a date meaning midnight UTC.
+func parseValue(t Type, raw string) (interface{}, error) {
+	switch t {
+	case Int:
+		return strconv.ParseInt(raw, 10, 64)
+	case Time:
+		if v, err := time.Parse(time.RFC3339Nano, raw); err == nil {
+			return v, nil
+		}
+		return time.Parse(time.DateOnly, raw)
+	default:
+		return raw, nil
+	}
+}
+
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+type cursorJSON struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    uint64 `json:"id"`
+}
+
+// EncodeCursor returns the opaque form of c for listings sorted by s.
+func EncodeCursor(c Cursor, s Sort) string {
+	b, _ := json.Marshal(cursorJSON{Sort: s.String(), Value: formatValue(c.Value), ID: c.ID})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeCursor parses a cursor issued by EncodeCursor for the same sort. A
+// cursor from another sort order would skip or repeat items, so it is
+// rejected.
+func decodeCursor(raw string, s Sort) (Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return Cursor{}, err
+	}
+	var c cursorJSON
+	if err := json.Unmarshal(b, &c); err != nil {
+		return Cursor{}, err
+	}
+	if c.Sort != s.String() {
+		return Cursor{}, fmt.Errorf("cursor is for sort %q", c.Sort)
+	}
+	value, err := parseValue(s.Field.Type, c.Value)
+	if err != nil {
+		return Cursor{}, err
+	}
+	return Cursor{Value: value, ID: c.ID}, nil
+}
+
+// NewPage builds a page from items fetched with a limit of q.Limit+1: the
+// extra item, if present, only signals that another page follows. position
+// returns an item's cursor.
+func NewPage[T any](items []T, q Query, position func(T) Cursor) Page[T] {
+	if len(items) <= q.Limit {
+		return Page[T]{Items: items}
+	}
+	items = items[:q.Limit]
+	return Page[T]{Items: items, NextCursor: EncodeCursor(position(items[len(items)-1]), q.Sort)}
+}
@@ -0,0 +1,265 @@
+package query
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSpec = Spec{
+	Fields: []Field{
+		{Name: "id", Type: Int, Sortable: true},
+		{Name: "name", Type: String, Ops: []Op{Eq, Contains}, Sortable: true},
+		{Name: "age", Type: Int, Ops: []Op{Gte, Lte}},
+		{Name: "createdAt", Type: Time, Ops: []Op{Gte, Lt}, Sortable: true},
+	},
+	DefaultSort:  "-createdAt",
+	DefaultLimit: 20,
+	MaxLimit:     100,
+}
+
+func parse(t *testing.T, raw string) (Query, error) {
+	t.Helper()
+	values, err := url.ParseQuery(raw)
+	require.NoError(t, err)
+	return testSpec.Parse(values)
+}
+
+func TestParse_Defaults(t *testing.T) {
+	t.Parallel()
+	q, err := parse(t, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, 20, q.Limit)
+	assert.Equal(t, "createdAt", q.Sort.Field.Name)
+	assert.True(t, q.Sort.Desc)
+	assert.Nil(t, q.After)
+	assert.False(t, q.WithTotal)
+	assert.Empty(t, q.Filters)
+}
+
+func TestParse_Filters(t *testing.T) {
+	t.Parallel()
+	q, err := parse(t, "name[contains]=lan&age[gte]=18&createdAt[lt]=2024-05-01&sort=name&limit=5&total=true")
+	require.NoError(t, err)
+
+	assert.Equal(t, 5, q.Limit)
+	assert.Equal(t, "name", q.Sort.String())
+	assert.True(t, q.WithTotal)
+
+	byField := make(map[string]Filter)
+	for _, f := range q.Filters {
+		byField[f.Field.Name] = f
+	}
+	assert.Equal(t, Filter{Field: testSpec.Fields[1], Op: Contains, Value: "lan"}, byField["name"])
+	assert.Equal(t, Filter{Field: testSpec.Fields[2], Op: Gte, Value: int64(18)}, byField["age"])
+	assert.Equal(t, Lt, byField["createdAt"].Op)
+	assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), byField["createdAt"].Value)
+
+	q, err = parse(t, "name=Lan")
+	require.NoError(t, err)
+	require.Len(t, q.Filters, 1)
+	assert.Equal(t, Eq, q.Filters[0].Op, "a bare field means eq")
+}
+
+func TestParse_Errors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		query string
+		param string
+		tag   string
+	}{
+		{"page=2", "page", TagUnknown},
+		{"password=x", "password", TagUnknown},
+		{"id=3", "id", TagUnknown},
+		{"name[gte]=a", "name[gte]", TagOperator},
+		{"name[nope]=a", "name[nope]", TagOperator},
+		{"age[gte]=old", "age[gte]", TagValue},
+		{"createdAt[gte]=yesterday", "createdAt[gte]", TagValue},
+		{"sort=age", "sort", TagSort},
+		{"sort=-nope", "sort", TagSort},
+		{"limit=0", "limit", TagLimit},
+		{"limit=101", "limit", TagLimit},
+		{"limit=ten", "limit", TagLimit},
+		{"total=maybe", "total", TagBool},
+		{"cursor=!!!", "cursor", TagCursor},
+	}
+	for _, tt := range tests {
+		_, err := parse(t, tt.query)
+		var qerr *Error
+		if assert.ErrorAsf(t, err, &qerr, "query %s", tt.query) {
+			assert.Equalf(t, tt.param, qerr.Param, "query %s", tt.query)
+			assert.Equalf(t, tt.tag, qerr.Tag, "query %s", tt.query)
+		}
+	}
+}
+
+func TestParseOffset(t *testing.T) {
+	t.Parallel()
+	parseOffset := func(raw string) (Query, int, int, error) {
+		values, err := url.ParseQuery(raw)
+		require.NoError(t, err)
+		return testSpec.ParseOffset(values, 10)
+	}
+
+	q, page, size, err := parseOffset("page=3&page_size=5&name=Lan&sort=name")
+	require.NoError(t, err)
+	assert.Equal(t, 3, page)
+	assert.Equal(t, 5, size)
+	assert.Equal(t, 5, q.Limit)
+	assert.Equal(t, 10, q.Offset)
+	assert.True(t, q.WithTotal, "offset pages report the total")
+	assert.Equal(t, "name", q.Sort.String())
+	assert.Len(t, q.Filters, 1)
+
+	q, page, size, err = parseOffset("page=zero&page_size=-1")
+	require.NoError(t, err, "invalid values fall back to the defaults, as before cursors")
+	assert.Equal(t, 1, page)
+	assert.Equal(t, 10, size)
+	assert.Zero(t, q.Offset)
+
+	tests := []struct {
+		query string
+		param string
+		tag   string
+	}{
+		{"page_size=101", "page_size", TagLimit},
+		{"page=2&cursor=abc", "cursor", TagUnknown},
+		{"page=2&limit=5", "limit", TagUnknown},
+		{"page=2&password=x", "password", TagUnknown},
+	}
+	for _, tt := range tests {
+		_, _, _, err := parseOffset(tt.query)
+		var qerr *Error
+		if assert.ErrorAsf(t, err, &qerr, "query %s", tt.query) {
+			assert.Equalf(t, tt.param, qerr.Param, "query %s", tt.query)
+			assert.Equalf(t, tt.tag, qerr.Tag, "query %s", tt.query)
+		}
+	}
+}
+
+func TestHasPageParams(t *testing.T) {
+	t.Parallel()
+	assert.True(t, HasPageParams(url.Values{"page": {"2"}}))
+	assert.True(t, HasPageParams(url.Values{"page_size": {"5"}}))
+	assert.False(t, HasPageParams(url.Values{"limit": {"5"}}))
+}
+
+func TestCursor_RoundTrip(t *testing.T) {
+	t.Parallel()
+	created := time.Date(2024, 5, 1, 12, 30, 0, 123456000, time.UTC)
+	q, err := parse(t, "sort=-createdAt")
+	require.NoError(t, err)
+
+	encoded := EncodeCursor(Cursor{Value: created, ID: 42}, q.Sort)
+	q, err = parse(t, "sort=-createdAt&cursor="+url.QueryEscape(encoded))
+	require.NoError(t, err)
+	require.NotNil(t, q.After)
+	assert.True(t, created.Equal(q.After.Value.(time.Time)))
+	assert.Equal(t, uint64(42), q.After.ID)
+}
+
+func TestCursor_RejectsOtherSort(t *testing.T) {
+	t.Parallel()
+	q, err := parse(t, "sort=name")
+	require.NoError(t, err)
+	encoded := EncodeCursor(Cursor{Value: "Lan", ID: 1}, q.Sort)
+
+	_, err = parse(t, "sort=-name&cursor="+encoded)
+	var qerr *Error
+	require.ErrorAs(t, err, &qerr)
+	assert.Equal(t, TagCursor, qerr.Tag)
+}
+
+func TestNewPage(t *testing.T) {
+	t.Parallel()
+	q, err := parse(t, "sort=id&limit=2")
+	require.NoError(t, err)
+	position := func(id int) Cursor { return Cursor{Value: int64(id), ID: uint64(id)} }
+
+	page := NewPage([]int{1, 2}, q, position)
+	assert.Equal(t, []int{1, 2}, page.Items)
+	assert.Empty(t, page.NextCursor, "no extra item, no next page")
+
+	page = NewPage([]int{1, 2, 3}, q, position)
+	assert.Equal(t, []int{1, 2}, page.Items)
+	assert.Equal(t, EncodeCursor(position(2), q.Sort), page.NextCursor)
+}
+
+type person struct {
+	id   uint64
+	name string
+	age  int64
+}
+
+func (p person) value(field string) interface{} {
+	switch field {
+	case "id":
+		return int64(p.id)
+	case "name":
+		return p.name
+	case "age":
+		return p.age
+	}
+	return nil
+}
+
+func TestSlice(t *testing.T) {
+	t.Parallel()
+	people := []person{
+		{1, "Lan", 30},
+		{2, "minh", 17},
+		{3, "Lan", 25},
+		{4, "An", 40},
+		{5, "Lanh", 19},
+	}
+	value := func(p person, field string) interface{} { return p.value(field) }
+	id := func(p person) uint64 { return p.id }
+
+	list := func(raw string) Page[person] {
+		q, err := parse(t, raw)
+		require.NoError(t, err)
+		return Slice(people, q, value, id)
+	}
+	ids := func(page Page[person]) []uint64 {
+		var out []uint64
+		for _, p := range page.Items {
+			out = append(out, p.id)
+		}
+		return out
+	}
+
+	// Equal names are ordered by ID, in the sort's direction.
+	assert.Equal(t, []uint64{4, 1, 3, 5, 2}, ids(list("sort=name")))
+	assert.Equal(t, []uint64{2, 5, 3, 1, 4}, ids(list("sort=-name")))
+
+	page := list("sort=name&name[contains]=LAN&age[gte]=20&total=true")
+	assert.Equal(t, []uint64{1, 3}, ids(page))
+	require.NotNil(t, page.Total)
+	assert.EqualValues(t, 2, *page.Total)
+
+	var seen []uint64
+	raw := "sort=-name&limit=2"
+	for {
+		page := list(raw)
+		seen = append(seen, ids(page)...)
+		if page.NextCursor == "" {
+			break
+		}
+		raw = "sort=-name&limit=2&cursor=" + url.QueryEscape(page.NextCursor)
+	}
+	assert.Equal(t, []uint64{2, 5, 3, 1, 4}, seen)
+
+	q, _, _, err := testSpec.ParseOffset(url.Values{"sort": {"-name"}, "page": {"2"}, "page_size": {"2"}}, 10)
+	require.NoError(t, err)
+	page = Slice(people, q, value, id)
+	assert.Equal(t, []uint64{3, 1}, ids(page))
+	require.NotNil(t, page.Total)
+	assert.EqualValues(t, 5, *page.Total)
+
+	q.Offset = 10
+	assert.Empty(t, Slice(people, q, value, id).Items, "an offset past the end is an empty page")
+}
@@ -0,0 +1,109 @@
+package query
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Slice evaluates q over items in memory, with the same results a database
+// would give. value returns an item's value of a field, typed as the field's
+// Type; id returns its ID. It is meant for in-memory repositories.
+func Slice[T any](items []T, q Query, value func(item T, field string) interface{}, id func(T) uint64) Page[T] {
+	var matched []T
+	for _, item := range items {
+		if matches(item, q.Filters, value) {
+			matched = append(matched, item)
+		}
+	}
+
+	var total *int64
+	if q.WithTotal {
+		n := int64(len(matched))
+		total = &n
+	}
+
+	position := func(item T) Cursor {
+		return Cursor{Value: value(item, q.Sort.Field.Name), ID: id(item)}
+	}
+	before := func(a, b Cursor) bool {
+		if c := compare(a.Value, b.Value); c != 0 {
+			return (c < 0) != q.Sort.Desc
+		}
+		if a.ID == b.ID {
+			return false
+		}
+		return (a.ID < b.ID) != q.Sort.Desc
+	}
+
+	sort.Slice(matched, func(i, j int) bool { return before(position(matched[i]), position(matched[j])) })
+
+	start := 0
+	if q.After != nil {
+		start = sort.Search(len(matched), func(i int) bool { return before(*q.After, position(matched[i])) })
+	}
+	start += q.Offset
+	if start > len(matched) {
+		start = len(matched)
+	}
+	end := start + q.Limit + 1
+	if end > len(matched) {
+		end = len(matched)
+	}
+
+	page := NewPage(matched[start:end], q, position)
+	page.Total = total
+	return page
+}
+
+func matches[T any](item T, filters []Filter, value func(T, string) interface{}) bool {
+	for _, f := range filters {
+		if !f.match(value(item, f.Field.Name)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f Filter) match(v interface{}) bool {
+	if f.Op == Contains {
+		s, _ := v.(string)
+		pattern, _ := f.Value.(string)
+		return strings.Contains(strings.ToLower(s), strings.ToLower(pattern))
+	}
+
+	c := compare(v, f.Value)
+	switch f.Op {
+	case Eq:
+		return c == 0
+	case Gt:
+		return c > 0
+	case Gte:
+		return c >= 0
+	case Lt:
+		return c < 0
+	case Lte:
+		return c <= 0
+	}
+	return false
+}
+
+// compare orders two values of the same Type.
+func compare(a, b interface{}) int {
+	switch a := a.(type) {
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	case int64:
+		b := b.(int64)
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(a, b.(string))
+	}
+	return 0
+}
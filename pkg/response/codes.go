@@ -2,23 +2,86 @@ package response
 
 // Response Codes
 const (
-	CodeSuccess             = "SUCCESS"
-	CodeCreated             = "CREATED"
-	CodeBadRequest          = "BAD_REQUEST"
-	CodeUnauthorized        = "UNAUTHORIZED"
-	CodeNotFound            = "NOT_FOUND"
-	CodeServerInternalError = "SERVER_INTERNAL_ERROR"
+	CodeSuccess              = "SUCCESS"
+	CodeCreated              = "CREATED"
+	CodeBadRequest           = "BAD_REQUEST"
+	CodeUnauthorized         = "UNAUTHORIZED"
+	CodeForbidden            = "FORBIDDEN"
+	CodeNotFound             = "NOT_FOUND"
+	CodeConflict             = "CONFLICT"
+	CodeServerInternalError  = "SERVER_INTERNAL_ERROR"
+	CodeRequestTimeout       = "REQUEST_TIMEOUT"
+	CodeServiceUnavailable   = "SERVICE_UNAVAILABLE"
+	CodeValidationFailed     = "VALIDATION_FAILED"
+	CodeInvalidQuery         = "INVALID_QUERY"
+	CodeUnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+	CodePreconditionRequired = "PRECONDITION_REQUIRED"
+
+	CodeInvalidIdempotencyKey      = "INVALID_IDEMPOTENCY_KEY"
+	CodeIdempotencyKeyReused       = "IDEMPOTENCY_KEY_REUSED"
+	CodeIdempotencyRequestInFlight = "IDEMPOTENCY_REQUEST_IN_FLIGHT"
+
+	// Domain error codes. These are part of the API contract: clients branch
+	// on them, so never rename an existing code.
+	CodeUserNotFound           = "USER_NOT_FOUND"
+	CodeEmailAlreadyRegistered = "EMAIL_ALREADY_REGISTERED"
+	CodeEmailInUse             = "EMAIL_IN_USE"
+	CodeVersionConflict        = "VERSION_CONFLICT"
+	CodeInvalidCredentials     = "INVALID_CREDENTIALS"
+	CodeInvalidRefreshToken    = "INVALID_REFRESH_TOKEN"
+	CodeSessionRevoked         = "SESSION_REVOKED"
+	CodeJobNotFound            = "JOB_NOT_FOUND"
+	CodeJobNotRetryable        = "JOB_NOT_RETRYABLE"
+	CodeFeatureFlagNotFound    = "FEATURE_FLAG_NOT_FOUND"
+	CodeFeatureFlagExists      = "FEATURE_FLAG_EXISTS"
+	CodeInvalidFeatureFlagKey  = "INVALID_FEATURE_FLAG_KEY"
 )
 
-// Response Messages
+// Response Messages. Each constant is a message ID that is translated into
+// the request's locale by the pkg/i18n catalog when the response is written.
 const (
-	MsgSuccess             = "Success"
-	MsgUserCreated         = "User created"
-	MsgUserUpdated         = "User updated"
-	MsgUserDeleted         = "User deleted"
-	MsgUserRegistered      = "User registered successfully"
-	MsgInvalidID           = "Invalid ID"
-	MsgUserNotFound        = "User not found"
-	MsgLoginSuccess        = "Login success"
-	MsgRefreshTokenSuccess = "Refresh token success"
+	MsgSuccess              = "SUCCESS"
+	MsgUserCreated          = "USER_CREATED"
+	MsgUserUpdated          = "USER_UPDATED"
+	MsgUserDeleted          = "USER_DELETED"
+	MsgUserRestored         = "USER_RESTORED"
+	MsgUserPurged           = "USER_PURGED"
+	MsgEmailInUse           = "EMAIL_IN_USE"
+	MsgUserRegistered       = "USER_REGISTERED"
+	MsgInvalidID            = "INVALID_ID"
+	MsgUserNotFound         = "USER_NOT_FOUND"
+	MsgLoginSuccess         = "LOGIN_SUCCESS"
+	MsgRefreshTokenSuccess  = "REFRESH_TOKEN_SUCCESS"
+	MsgRequestTimeout       = "REQUEST_TIMEOUT"
+	MsgServiceNotReady      = "SERVICE_NOT_READY"
+	MsgUnauthorized         = "UNAUTHORIZED"
+	MsgForbidden            = "FORBIDDEN"
+	MsgInternalError        = "INTERNAL_ERROR"
+	MsgMalformedRequest     = "MALFORMED_REQUEST"
+	MsgEmailRegistered      = "EMAIL_ALREADY_REGISTERED"
+	MsgInvalidCredentials   = "INVALID_CREDENTIALS"
+	MsgInvalidRefreshToken  = "INVALID_REFRESH_TOKEN"
+	MsgSessionRevoked       = "SESSION_REVOKED"
+	MsgInvalidToken         = "INVALID_TOKEN"
+	MsgAuthHeaderRequired   = "AUTH_HEADER_REQUIRED"
+	MsgInvalidAuthHeader    = "INVALID_AUTH_HEADER"
+	MsgValidationFailed     = "VALIDATION_FAILED"
+	MsgJobNotFound          = "JOB_NOT_FOUND"
+	MsgJobNotRetryable      = "JOB_NOT_RETRYABLE"
+	MsgJobRetried           = "JOB_RETRIED"
+	MsgInvalidQuery         = "INVALID_QUERY"
+	MsgUnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+	MsgUserVersionConflict  = "USER_VERSION_CONFLICT"
+	MsgIfMatchRequired      = "IF_MATCH_REQUIRED"
+
+	MsgInvalidIdempotencyKey      = "INVALID_IDEMPOTENCY_KEY"
+	MsgIdempotencyKeyReused       = "IDEMPOTENCY_KEY_REUSED"
+	MsgIdempotencyRequestInFlight = "IDEMPOTENCY_REQUEST_IN_FLIGHT"
+
+	MsgFeatureFlagNotFound   = "FEATURE_FLAG_NOT_FOUND"
+	MsgFeatureFlagExists     = "FEATURE_FLAG_EXISTS"
+	MsgInvalidFeatureFlagKey = "INVALID_FEATURE_FLAG_KEY"
+	MsgFeatureFlagCreated    = "FEATURE_FLAG_CREATED"
+	MsgFeatureFlagUpdated    = "FEATURE_FLAG_UPDATED"
+	MsgFeatureFlagDeleted    = "FEATURE_FLAG_DELETED"
 )
@@ -0,0 +1,135 @@
+package response
+
+import (
+	"context"
+	"english-learning/pkg/i18n"
+	"english-learning/pkg/logger"
+	"english-learning/pkg/query"
+	"english-learning/pkg/validation"
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+// ErrorSpec describes how a domain error is presented to clients. Message is
+// a message ID from the i18n catalog.
+type ErrorSpec struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+type registeredError struct {
+	target error
+	spec   ErrorSpec
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   []registeredError
+)
+
+// RegisterError maps a domain sentinel error (matched with errors.Is, so
+// wrapped errors are recognised) to an HTTP status, stable code and client
+// message. Each module's transport package registers its errors at init.
+func RegisterError(target error, status int, code, message string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry = append(registry, registeredError{
+		target: target,
+		spec:   ErrorSpec{Status: status, Code: code, Message: message},
+	})
+}
+
+// lookupError returns the spec of the first registered error matching err.
+func lookupError(err error) (ErrorSpec, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	for _, r := range registry {
+		if errors.Is(err, r.target) {
+			return r.spec, true
+		}
+	}
+	return ErrorSpec{}, false
+}
+
+// HandleError writes the response for an error returned by a service. Errors
+// that were registered with RegisterError are reported with their code and
+// message; validation errors list the offending fields; a request whose
+// deadline expired gets a 504, as from middleware.TimeoutMiddleware. Anything else is
+// logged with the request context and answered with a generic 500 so that
+// internal details never reach the client.
+func HandleError(c *gin.Context, err error) {
+	if spec, ok := lookupError(err); ok {
+		Error(c, spec.Status, spec.Code, spec.Message)
+		return
+	}
+
+	var verrs validator.ValidationErrors
+	if errors.As(err, &verrs) {
+		ValidationError(c, err)
+		return
+	}
+
+	var qerr *query.Error
+	if errors.As(err, &qerr) {
+		QueryError(c, qerr)
+		return
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		_ = c.Error(err)
+		logger.FromContext(c.Request.Context()).Named("response").Warn("Request deadline exceeded", zap.Error(err))
+		Error(c, http.StatusGatewayTimeout, CodeRequestTimeout, MsgRequestTimeout)
+		return
+	}
+
+	_ = c.Error(err)
+	logger.FromContext(c.Request.Context()).Named("response").Error("Unhandled error", zap.Error(err))
+	Error(c, http.StatusInternalServerError, CodeServerInternalError, MsgInternalError)
+}
+
+// BindError writes the response for a request body or query that failed to
+// bind. Validation failures are listed per field; malformed input is reported
+// without echoing decoder internals.
+func BindError(c *gin.Context, err error) {
+	var verrs validator.ValidationErrors
+	if errors.As(err, &verrs) {
+		ValidationError(c, err)
+		return
+	}
+	Error(c, http.StatusBadRequest, CodeBadRequest, MsgMalformedRequest)
+}
+
+// QueryError writes a 400 naming the rejected list query parameter.
+func QueryError(c *gin.Context, err *query.Error) {
+	l := locale(c)
+	c.JSON(http.StatusBadRequest, APIResponse{
+		Code:    CodeInvalidQuery,
+		Message: translate(c, MsgInvalidQuery),
+		Errors: []validation.FieldError{{
+			Field: err.Param,
+			Tag:   err.Tag,
+			Message: i18n.Format(l, "query."+err.Tag, map[string]string{
+				"param":  err.Param,
+				"detail": err.Detail,
+			}),
+		}},
+		RequestID: requestID(c),
+	})
+}
+
+// ValidationError writes a 400 with one entry per invalid field.
+func ValidationError(c *gin.Context, err error) {
+	l := locale(c)
+	c.JSON(http.StatusBadRequest, APIResponse{
+		Code:      CodeValidationFailed,
+		Message:   validation.FormatError(err, l),
+		Errors:    validation.FieldErrors(err, l),
+		RequestID: requestID(c),
+	})
+}
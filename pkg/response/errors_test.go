@@ -0,0 +1,135 @@
+package response
+
+import (
+	"context"
+	"encoding/json"
+	"english-learning/pkg/query"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errWidgetMissing = errors.New("widget missing")
+
+func init() {
+	gin.SetMode(gin.TestMode)
+	RegisterError(errWidgetMissing, http.StatusNotFound, "WIDGET_NOT_FOUND", "Widget not found")
+}
+
+func handle(err error) (*httptest.ResponseRecorder, APIResponse) {
+	r := gin.New()
+	r.GET("/", func(c *gin.Context) { HandleError(c, err) })
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var body APIResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+	return w, body
+}
+
+func TestHandleError_RegisteredWrappedError(t *testing.T) {
+	t.Parallel()
+	w, body := handle(fmt.Errorf("loading widget 7: %w", errWidgetMissing))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "WIDGET_NOT_FOUND", body.Code)
+	assert.Equal(t, "Widget not found", body.Message)
+}
+
+func TestHandleError_UnknownErrorIsHidden(t *testing.T) {
+	t.Parallel()
+	w, body := handle(errors.New(`updating user: ERROR: relation "users" does not exist`))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, CodeServerInternalError, body.Code)
+	assert.Equal(t, "Internal server error", body.Message)
+	assert.NotContains(t, w.Body.String(), "relation")
+}
+
+func TestHandleError_WrappedValidationErrors(t *testing.T) {
+	t.Parallel()
+	verr := validator.New().Struct(struct {
+		Name string `validate:"required"`
+	}{})
+	require.Error(t, verr)
+
+	w, body := handle(fmt.Errorf("creating widget: %w", verr))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, CodeValidationFailed, body.Code)
+	if assert.Len(t, body.Errors, 1) {
+		assert.Equal(t, "Name", body.Errors[0].Field)
+		assert.Equal(t, "required", body.Errors[0].Tag)
+	}
+}
+
+func TestHandleError_DeadlineExceeded(t *testing.T) {
+	t.Parallel()
+	w, body := handle(fmt.Errorf("listing widgets: %w", context.DeadlineExceeded))
+
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.Equal(t, CodeRequestTimeout, body.Code)
+}
+
+func TestBindError_MalformedBody(t *testing.T) {
+	t.Parallel()
+	r := gin.New()
+	r.POST("/", func(c *gin.Context) {
+		var v struct {
+			Name string `json:"name" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&v); err != nil {
+			BindError(c, err)
+		}
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", nil))
+
+	var body APIResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, CodeBadRequest, body.Code)
+}
+
+func TestHandleError_QueryError(t *testing.T) {
+	t.Parallel()
+	w, body := handle(fmt.Errorf("listing: %w", &query.Error{Param: "limit", Tag: query.TagLimit, Detail: "1-100"}))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, CodeInvalidQuery, body.Code)
+	if assert.Len(t, body.Errors, 1) {
+		assert.Equal(t, "limit", body.Errors[0].Field)
+		assert.Equal(t, query.TagLimit, body.Errors[0].Tag)
+		assert.Equal(t, "Parameter 'limit' must be between 1-100", body.Errors[0].Message)
+	}
+}
+
+func TestSuccessCursor_OmitsTotalUnlessCounted(t *testing.T) {
+	t.Parallel()
+	total := int64(3)
+	for _, tt := range []struct {
+		total *int64
+		want  string
+	}{
+		{nil, `{"items":[1,2],"nextCursor":"abc"}`},
+		{&total, `{"items":[1,2],"nextCursor":"abc","total":3}`},
+	} {
+		r := gin.New()
+		r.GET("/", func(c *gin.Context) { SuccessCursor(c, []int{1, 2}, "abc", tt.total, MsgSuccess) })
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		var body struct {
+			Data json.RawMessage `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.JSONEq(t, tt.want, string(body.Data))
+	}
+}
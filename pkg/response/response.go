@@ -1,6 +1,9 @@
 package response
 
 import (
+	"english-learning/pkg/i18n"
+	"english-learning/pkg/reqctx"
+	"english-learning/pkg/validation"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -10,6 +13,11 @@ type APIResponse struct {
 	Data    interface{} `json:"data"`
 	Code    string      `json:"code"`
 	Message string      `json:"message"`
+	// RequestID is only set on error responses so clients can quote it when
+	// reporting a problem. It is also sent in the X-Request-ID header.
+	RequestID string `json:"requestId,omitempty"`
+	// Errors lists field-level problems of a rejected request.
+	Errors []validation.FieldError `json:"errors,omitempty"`
 }
 
 type PaginatedData struct {
@@ -19,11 +27,19 @@ type PaginatedData struct {
 	Size  int         `json:"size"`
 }
 
+// CursorData is a page of a keyset-paginated listing. NextCursor is empty on
+// the last page; Total is only present when the client asked for it.
+type CursorData struct {
+	Items      interface{} `json:"items"`
+	NextCursor string      `json:"nextCursor"`
+	Total      *int64      `json:"total,omitempty"`
+}
+
 func Success(c *gin.Context, data interface{}, message string) {
 	c.JSON(http.StatusOK, APIResponse{
 		Data:    data,
 		Code:    CodeSuccess,
-		Message: message,
+		Message: translate(c, message),
 	})
 }
 
@@ -36,7 +52,20 @@ func SuccessList(c *gin.Context, items interface{}, total int64, page int, size
 			Size:  size,
 		},
 		Code:    CodeSuccess,
-		Message: message,
+		Message: translate(c, message),
+	})
+}
+
+// SuccessCursor writes a page of a listing parsed with pkg/query.
+func SuccessCursor(c *gin.Context, items interface{}, nextCursor string, total *int64, message string) {
+	c.JSON(http.StatusOK, APIResponse{
+		Data: CursorData{
+			Items:      items,
+			NextCursor: nextCursor,
+			Total:      total,
+		},
+		Code:    CodeSuccess,
+		Message: translate(c, message),
 	})
 }
 
@@ -44,14 +73,37 @@ func Created(c *gin.Context, data interface{}, message string) {
 	c.JSON(http.StatusCreated, APIResponse{
 		Data:    data,
 		Code:    CodeCreated,
-		Message: message,
+		Message: translate(c, message),
 	})
 }
 
 func Error(c *gin.Context, status int, code string, message string) {
+	ErrorWithData(c, status, code, message, nil)
+}
+
+// ErrorWithData writes an error response that carries additional details.
+func ErrorWithData(c *gin.Context, status int, code string, message string, data interface{}) {
 	c.JSON(status, APIResponse{
-		Data:    nil,
-		Code:    code,
-		Message: message,
+		Data:      data,
+		Code:      code,
+		Message:   translate(c, message),
+		RequestID: requestID(c),
 	})
 }
+
+// locale returns the negotiated locale of the request, or the default one.
+func locale(c *gin.Context) string {
+	if l := reqctx.Locale(c.Request.Context()); l != "" {
+		return l
+	}
+	return i18n.Default
+}
+
+// translate resolves a message ID into the request's locale.
+func translate(c *gin.Context, message string) string {
+	return i18n.T(locale(c), message)
+}
+
+func requestID(c *gin.Context) string {
+	return reqctx.RequestID(c.Request.Context())
+}
@@ -0,0 +1,12 @@
+// Package token holds the JWT token types shared by the code that issues
+// tokens and the middleware that checks them.
+package token
+
+// Token types, carried in the "typ" claim. Access and refresh tokens are
+// signed with the same secret; the type keeps a refresh token, which lives
+// for days and is checked against its session only when exchanged, from
+// being accepted as an access token.
+const (
+	TypeAccess  = "access"
+	TypeRefresh = "refresh"
+)
@@ -0,0 +1,91 @@
+package tracing
+
+import (
+	"context"
+	"english-learning/pkg/logger"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type widget struct {
+	ID   uint
+	Name string
+}
+
+func TestGormPlugin_SpanPerStatement(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	// DryRun builds the SQL and runs the callbacks without a server.
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost dbname=none"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(GormPlugin{}))
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "request")
+	var w widget
+	require.NoError(t, db.WithContext(ctx).Where("name = ?", "gear").First(&w).Error)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	span := spans[0]
+	assert.Equal(t, "gorm.query", span.Name())
+	assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+	assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID(), "parented to the request span")
+
+	attrs := attribute.NewSet(span.Attributes()...)
+	for _, want := range []attribute.KeyValue{
+		semconv.DBSystemNamePostgreSQL,
+		semconv.DBOperationName("query"),
+		semconv.DBCollectionName("widgets"),
+	} {
+		got, ok := attrs.Value(want.Key)
+		if assert.Truef(t, ok, "attribute %s", want.Key) {
+			assert.Equal(t, want.Value, got)
+		}
+	}
+	query, _ := attrs.Value(semconv.DBQueryTextKey)
+	assert.Contains(t, query.AsString(), `SELECT * FROM "widgets" WHERE name = $1`)
+}
+
+func TestGormPlugin_LogsQueryWithItsSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	core, logs := observer.New(zap.DebugLevel)
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost dbname=none"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+		Logger:               logger.NewGormLogger(zap.New(core), 0, nil),
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(GormPlugin{}))
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "request")
+	var w widget
+	require.NoError(t, db.WithContext(ctx).Where("name = ?", "gear").First(&w).Error)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	entries := logs.FilterMessage("SQL query executed").All()
+	require.Len(t, entries, 1)
+	fields := entries[0].ContextMap()
+	assert.Equal(t, spans[0].SpanContext().SpanID().String(), fields["span_id"], "logged with the query span, not the request span")
+	assert.Equal(t, parent.SpanContext().TraceID().String(), fields["trace_id"])
+}
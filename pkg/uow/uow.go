@@ -0,0 +1,15 @@
+// Package uow defines the unit-of-work abstraction that services use to run
+// several repository calls atomically. It has no dependencies so every module's
+// domain layer can use it; internal/database provides the GORM implementation.
+package uow
+
+import "context"
+
+// UnitOfWork runs a function inside a single transaction.
+type UnitOfWork interface {
+	// Do runs fn in a transaction. Repository calls made with the ctx passed
+	// to fn take part in it. The transaction commits if fn returns nil and
+	// rolls back if it returns an error or panics. Calling Do with a ctx that
+	// is already inside a transaction joins it.
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
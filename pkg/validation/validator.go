@@ -1,7 +1,8 @@
 package validation
 
 import (
-	"fmt"
+	"english-learning/pkg/i18n"
+	"errors"
 	"reflect"
 	"regexp"
 	"strings"
@@ -40,6 +41,14 @@ func ValidatePhone(fl validator.FieldLevel) bool {
 	return phoneRegex.MatchString(fl.Field().String())
 }
 
+var versionRegex = regexp.MustCompile(`^\d+(\.\d+){0,2}$`)
+
+// ValidateVersion checks for an app release such as "2", "2.3" or "2.3.1".
+// Usage: binding:"version"
+func ValidateVersion(fl validator.FieldLevel) bool {
+	return versionRegex.MatchString(fl.Field().String())
+}
+
 // RegisterTagName registers the "json" tag name as the field name in validator errors.
 // This ensures error messages use the JSON field names (e.g. "email" instead of "Email").
 func RegisterTagName(v *validator.Validate) {
@@ -52,31 +61,59 @@ func RegisterTagName(v *validator.Validate) {
 	})
 }
 
-// FormatError converts validator.ValidationErrors into a human-readable string.
-func FormatError(err error) string {
-	if errs, ok := err.(validator.ValidationErrors); ok {
+// FieldError describes why a single request field was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Tag     string `json:"tag"`
+	Message string `json:"message"`
+}
+
+// FieldErrors converts validator.ValidationErrors, wrapped or not, into one FieldError per field,
+// with messages in the given locale. It returns nil for any other error.
+func FieldErrors(err error, locale string) []FieldError {
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return nil
+	}
+	fields := make([]FieldError, len(errs))
+	for i, e := range errs {
+		fields[i] = FieldError{
+			Field:   e.Field(),
+			Tag:     e.Tag(),
+			Message: formatField(e, locale),
+		}
+	}
+	return fields
+}
+
+// FormatError converts validator.ValidationErrors into a human-readable string
+// in the given locale.
+func FormatError(err error, locale string) string {
+	var errs validator.ValidationErrors
+	if errors.As(err, &errs) {
 		var messages []string
 		for _, e := range errs {
-			messages = append(messages, formatField(e))
+			messages = append(messages, formatField(e, locale))
 		}
 		return strings.Join(messages, "; ")
 	}
 	return err.Error()
 }
 
-func formatField(e validator.FieldError) string {
-	switch e.Tag() {
-	case "required":
-		return fmt.Sprintf("Field '%s' is required", e.Field())
-	case "email":
-		return fmt.Sprintf("Field '%s' must be a valid email address", e.Field())
-	case "min":
-		return fmt.Sprintf("Field '%s' must be at least %s characters", e.Field(), e.Param())
-	case "max":
-		return fmt.Sprintf("Field '%s' must be at most %s characters", e.Field(), e.Param())
-	case "date_format":
-		return fmt.Sprintf("Field '%s' must match format %s", e.Field(), e.Param())
-	default:
-		return fmt.Sprintf("Field '%s' failed validation on '%s'", e.Field(), e.Tag())
+func formatField(e validator.FieldError, locale string) string {
+	field, ok := i18n.Lookup(locale, "field."+e.Field())
+	if !ok {
+		field = e.Field()
 	}
+
+	key := "validation." + e.Tag()
+	if _, ok := i18n.Lookup(locale, key); !ok {
+		key = "validation.default"
+	}
+
+	return i18n.Format(locale, key, map[string]string{
+		"field": field,
+		"param": e.Param(),
+		"tag":   e.Tag(),
+	})
 }